@@ -0,0 +1,24 @@
+package evalopts
+
+import (
+	"github.com/fhir-fli/fhirpath-go/fhirpath/internal/opts"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/profile"
+)
+
+// ProfileValidator returns an EvaluateOption that sets the Validator used by
+// the conformsTo() function.
+//
+// Without this option, conformsTo() uses profile.ClaimedProfiles, which trusts
+// the profiles claimed in each resource's meta.profile. If this option is
+// specified more than once, the validators are asked in the order they were
+// given, as by profile.Chain.
+func ProfileValidator(validator profile.Validator) opts.EvaluateOption {
+	return opts.Transform(func(cfg *opts.EvaluateConfig) error {
+		if previous := cfg.Context.ProfileValidator; previous != nil {
+			cfg.Context.ProfileValidator = profile.Chain(previous, validator)
+			return nil
+		}
+		cfg.Context.ProfileValidator = validator
+		return nil
+	})
+}
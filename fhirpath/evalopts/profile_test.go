@@ -0,0 +1,36 @@
+package evalopts_test
+
+import (
+	"testing"
+
+	"github.com/fhir-fli/fhirpath-go/fhir"
+	"github.com/fhir-fli/fhirpath-go/fhirpath"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/evalopts"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/profile"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/system"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+	"github.com/google/go-cmp/cmp"
+)
+
+// unknownProfiles is a Validator that resolves no profiles.
+type unknownProfiles struct{}
+
+func (unknownProfiles) ConformsTo(fhir.Base, string) (bool, error) {
+	return false, profile.ErrUnknownProfile
+}
+
+func TestProfileValidator_MultipleValidators_AsksEachInOrder(t *testing.T) {
+	expression := fhirpath.MustCompile("Patient.conformsTo('http://hl7.org/fhir/StructureDefinition/Patient')")
+
+	got, err := expression.Evaluate([]fhir.Resource{&ppb.Patient{}},
+		evalopts.ProfileValidator(unknownProfiles{}),
+		evalopts.ProfileValidator(profile.ClaimedProfiles),
+	)
+	if err != nil {
+		t.Fatalf("Evaluate() returned unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff(system.Collection{system.Boolean(true)}, got); diff != "" {
+		t.Errorf("Evaluate() returned unexpected diff (-want, +got)\n%s", diff)
+	}
+}
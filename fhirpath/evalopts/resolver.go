@@ -0,0 +1,27 @@
+package evalopts
+
+import (
+	"github.com/fhir-fli/fhirpath-go/fhirpath/internal/expr"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/internal/opts"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/resolver"
+)
+
+// Resolver returns an EvaluateOption that sets the Resolver used by the
+// resolve() function to locate referenced resources.
+//
+// Without a Resolver, resolve() always yields an empty collection. If this
+// option is specified more than once, the resolvers are tried in the order
+// they were given.
+func Resolver(r resolver.Resolver) opts.EvaluateOption {
+	return opts.Transform(func(cfg *opts.EvaluateConfig) error {
+		if cfg.Context.Containers == nil {
+			cfg.Context.Containers = expr.NewContainers()
+		}
+		if previous := cfg.Context.Resolver; previous != nil {
+			cfg.Context.Resolver = resolver.Chain(previous, r)
+			return nil
+		}
+		cfg.Context.Resolver = r
+		return nil
+	})
+}
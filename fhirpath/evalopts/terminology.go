@@ -0,0 +1,23 @@
+package evalopts
+
+import (
+	"github.com/fhir-fli/fhirpath-go/fhirpath/internal/opts"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/terminology"
+)
+
+// TerminologyProvider returns an EvaluateOption that sets the Provider used by
+// the memberOf(), subsumes(), and subsumedBy() functions.
+//
+// Without a Provider, these functions always yield an empty collection. If this
+// option is specified more than once, the providers are asked in the order
+// they were given, as by terminology.Chain.
+func TerminologyProvider(provider terminology.Provider) opts.EvaluateOption {
+	return opts.Transform(func(cfg *opts.EvaluateConfig) error {
+		if previous := cfg.Context.Terminology; previous != nil {
+			cfg.Context.Terminology = terminology.Chain(previous, provider)
+			return nil
+		}
+		cfg.Context.Terminology = provider
+		return nil
+	})
+}
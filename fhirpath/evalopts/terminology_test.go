@@ -0,0 +1,45 @@
+package evalopts_test
+
+import (
+	"testing"
+
+	"github.com/fhir-fli/fhirpath-go/fhir"
+	"github.com/fhir-fli/fhirpath-go/fhirpath"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/evalopts"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/system"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/terminology"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+	vspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/value_set_go_proto"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTerminologyProvider_MultipleProviders_AsksEachInOrder(t *testing.T) {
+	empty, err := terminology.NewMemory()
+	if err != nil {
+		t.Fatalf("NewMemory() returned unexpected error: %v", err)
+	}
+	genders, err := terminology.NewMemory(&vspb.ValueSet{
+		Url: fhir.URI("http://example.com/ValueSet/genders"),
+		Expansion: &vspb.ValueSet_Expansion{
+			Contains: []*vspb.ValueSet_Expansion_Contains{
+				{Code: fhir.Code("female")},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewMemory() returned unexpected error: %v", err)
+	}
+	expression := fhirpath.MustCompile("'female'.memberOf('http://example.com/ValueSet/genders')")
+
+	got, err := expression.Evaluate([]fhir.Resource{&ppb.Patient{}},
+		evalopts.TerminologyProvider(empty),
+		evalopts.TerminologyProvider(genders),
+	)
+	if err != nil {
+		t.Fatalf("Evaluate() returned unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff(system.Collection{system.Boolean(true)}, got); diff != "" {
+		t.Errorf("Evaluate() returned unexpected diff (-want, +got)\n%s", diff)
+	}
+}
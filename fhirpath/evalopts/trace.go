@@ -0,0 +1,62 @@
+package evalopts
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/fhir-fli/fhirpath-go/fhirpath/internal/opts"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/system"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// TraceSink returns an EvaluateOption that receives the collections logged by
+// the trace() function, along with the name given to the trace.
+//
+// Tracing is disabled by default, in which case trace() simply returns its
+// input. If this option is specified more than once, every sink receives each
+// traced collection.
+func TraceSink(sink func(name string, collection system.Collection)) opts.EvaluateOption {
+	return opts.Transform(func(cfg *opts.EvaluateConfig) error {
+		if previous := cfg.Context.TraceSink; previous != nil {
+			cfg.Context.TraceSink = func(name string, collection system.Collection) {
+				previous(name, collection)
+				sink(name, collection)
+			}
+			return nil
+		}
+		cfg.Context.TraceSink = sink
+		return nil
+	})
+}
+
+// SlogTraceSink returns a sink for use with TraceSink, which writes each traced
+// collection to the given logger at the specified level. System types are
+// rendered in their FHIRPath string form, and FHIR elements and resources are
+// rendered as JSON.
+func SlogTraceSink(logger *slog.Logger, level slog.Level) func(name string, collection system.Collection) {
+	return func(name string, collection system.Collection) {
+		items := make([]string, 0, len(collection))
+		for _, item := range collection {
+			items = append(items, traceString(item))
+		}
+		logger.Log(context.Background(), level, "fhirpath trace",
+			slog.String("name", name),
+			slog.Any("collection", items),
+		)
+	}
+}
+
+// traceString renders a single item of a traced collection.
+func traceString(item any) string {
+	if value, err := system.From(item); err == nil {
+		return fmt.Sprint(value)
+	}
+	if message, ok := item.(proto.Message); ok {
+		if data, err := protojson.Marshal(message); err == nil {
+			return string(data)
+		}
+	}
+	return fmt.Sprint(item)
+}
@@ -0,0 +1,52 @@
+package evalopts_test
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/fhir-fli/fhirpath-go/fhir"
+	"github.com/fhir-fli/fhirpath-go/fhirpath"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/evalopts"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/system"
+	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+)
+
+func TestSlogTraceSink(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	sink := evalopts.SlogTraceSink(logger, slog.LevelDebug)
+
+	sink("values", system.Collection{
+		system.Integer(1),
+		fhir.String("Kang"),
+		&dtpb.HumanName{Family: fhir.String("Chu")},
+	})
+
+	got := buf.String()
+	for _, want := range []string{"level=DEBUG", "name=values", "1", "Kang", `\"family\"`, "Chu"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("SlogTraceSink() logged %q, which doesn't contain %q", got, want)
+		}
+	}
+}
+
+func TestTraceSink_MultipleSinks_ReceiveTraces(t *testing.T) {
+	var first, second []string
+	expression := fhirpath.MustCompile("Patient.id.trace('id')")
+	patient := &ppb.Patient{Id: fhir.ID("123")}
+
+	_, err := expression.Evaluate([]fhir.Resource{patient},
+		evalopts.TraceSink(func(name string, _ system.Collection) { first = append(first, name) }),
+		evalopts.TraceSink(func(name string, _ system.Collection) { second = append(second, name) }),
+	)
+	if err != nil {
+		t.Fatalf("Evaluate() returned unexpected error: %v", err)
+	}
+
+	if len(first) != 1 || len(second) != 1 {
+		t.Errorf("TraceSink() sinks received %v and %v traces, want 1 each", len(first), len(second))
+	}
+}
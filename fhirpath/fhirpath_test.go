@@ -9,12 +9,17 @@ import (
 	"github.com/fhir-fli/fhirpath-go/fhirpath"
 	"github.com/fhir-fli/fhirpath-go/fhirpath/compopts"
 	"github.com/fhir-fli/fhirpath-go/fhirpath/evalopts"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/resolver"
 	"github.com/fhir-fli/fhirpath-go/fhirpath/system"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/terminology"
 	"github.com/fhir-fli/fhirpath-go/internal/element/extension"
 	"github.com/fhir-fli/fhirpath-go/internal/element/reference"
 	"github.com/fhir-fli/fhirpath-go/internal/fhirconv"
+	"github.com/fhir-fli/fhirpath-go/pkg/containedresource"
 	cpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
 	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	bcrpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	cspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/code_system_go_proto"
 	drpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/document_reference_go_proto"
 	epb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/encounter_go_proto"
 	lpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/list_go_proto"
@@ -22,11 +27,14 @@ import (
 	opb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/observation_go_proto"
 	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
 	prpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/practitioner_go_proto"
+	qpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/questionnaire_go_proto"
 	tpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/task_go_proto"
+	vspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/value_set_go_proto"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/shopspring/decimal"
 	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/anypb"
 )
 
 type evaluateTestCase struct {
@@ -146,6 +154,30 @@ var obsWithRef = &opb.Observation{
 		questionnaireRef,
 	},
 }
+var nestedQuestionnaire = &qpb.Questionnaire{
+	Item: []*qpb.Questionnaire_Item{
+		{
+			LinkId: fhir.String("1"),
+			Item: []*qpb.Questionnaire_Item{
+				{
+					LinkId: fhir.String("1.1"),
+					Initial: []*qpb.Questionnaire_Item_Initial{
+						{
+							Value: &qpb.Questionnaire_Item_Initial_ValueX{
+								Choice: &qpb.Questionnaire_Item_Initial_ValueX_Reference{
+									Reference: questionnaireRef,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			LinkId: fhir.String("2"),
+		},
+	},
+}
 var listWithNilRef = &lpb.List{
 	Entry: []*lpb.List_Entry{
 		{Item: &dtpb.Reference{Type: fhir.URI("Location")}},
@@ -463,6 +495,122 @@ func TestEvaluate_ThisInvocation_Evaluates(t *testing.T) {
 			inputCollection: []fhir.Resource{patientChu},
 			wantCollection:  system.Collection{dtpb.String{Value: "Senpai"}},
 		},
+		{
+			name:            "binds $this within arguments of non-iterating functions",
+			inputPath:       "Patient.name.select(family.combine($this.given))",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{fhir.String("Chu"), fhir.String("Senpai"), fhir.String("Chu"), fhir.String("Kang")},
+		},
+		{
+			name:            "returns input collection outside of iterating functions",
+			inputPath:       "$this.name.family.first()",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{fhir.String("Chu")},
+		},
+	}
+
+	testEvaluate(t, testCases)
+}
+
+func TestEvaluate_Iif_Evaluates(t *testing.T) {
+	testCases := []evaluateTestCase{
+		{
+			name:            "returns true-result",
+			inputPath:       "iif(Patient.active, 'active', 'inactive')",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.String("active")},
+		},
+		{
+			name:            "returns otherwise-result for empty criterion",
+			inputPath:       "iif(Patient.deceased, 'deceased', 'alive')",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.String("alive")},
+		},
+		{
+			name:            "returns empty without otherwise-result",
+			inputPath:       "iif(Patient.active.not(), 'inactive')",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{},
+		},
+		{
+			name:            "doesn't evaluate branch that isn't taken",
+			inputPath:       "iif(true, 1, Patient.name.given.single())",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.Integer(1)},
+		},
+		{
+			name:            "evaluates branches relative to input",
+			inputPath:       "Patient.name.select(iif(use = 'official', given, family))",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{fhir.String("Chu"), fhir.String("Kang")},
+		},
+	}
+
+	testEvaluate(t, testCases)
+}
+
+func TestEvaluate_IterationInvocations_Evaluates(t *testing.T) {
+	testCases := []evaluateTestCase{
+		{
+			name:            "filters by $index with where()",
+			inputPath:       "Patient.name.given.where($index < 1)",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{fhir.String("Senpai")},
+		},
+		{
+			name:            "projects $index with select()",
+			inputPath:       "Patient.name.select($index)",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.Integer(0), system.Integer(1)},
+		},
+		{
+			name:            "binds innermost $index in nested iterations",
+			inputPath:       "Patient.name.select(given.select($index))",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.Integer(0), system.Integer(0)},
+		},
+		{
+			name:            "restores outer $index after nested iteration",
+			inputPath:       "Patient.name.where(given.select($index).exists() and $index = 1).use",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{patientChu.Name[1].Use},
+		},
+		{
+			name:            "binds $index with exists()",
+			inputPath:       "Patient.name.exists($index = 1 and use = 'official')",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:            "binds $this with all()",
+			inputPath:       "Patient.name.given.all($this = 'Kang')",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.Boolean(false)},
+		},
+		{
+			name:            "sums with aggregate()",
+			inputPath:       "(1 | 2 | 3).aggregate($this + $total, 0)",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{system.Integer(6)},
+		},
+		{
+			name:            "aggregates using $index",
+			inputPath:       "(5 | 6 | 7).aggregate($total + $index, 10)",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{system.Integer(13)},
+		},
+		{
+			name:            "aggregate() without init starts with empty $total",
+			inputPath:       "(1 | 2 | 3).aggregate($total.count() + 1)",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{system.Integer(2)},
+		},
+		{
+			name:            "$index and $total are empty outside iteration",
+			inputPath:       "$index.exists() or $total.exists()",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{system.Boolean(false)},
+		},
 	}
 
 	testEvaluate(t, testCases)
@@ -704,6 +852,104 @@ func TestEvaluateEquality_ReturnsBoolean(t *testing.T) {
 	testEvaluate(t, testCases)
 }
 
+func TestEvaluateEquivalence_ReturnsBoolean(t *testing.T) {
+	testCases := []evaluateTestCase{
+		{
+			name:            "strings ignoring case and whitespace",
+			inputPath:       "'Hello   World' ~ ' hello world'",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:            "decimals at least precision",
+			inputPath:       "1.2 / 1.8 ~ 0.67",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:            "dates with different precision",
+			inputPath:       "@2012 ~ @2012-01",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{system.Boolean(false)},
+		},
+		{
+			name:            "empty collections",
+			inputPath:       "{} ~ {}",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:            "empty collection and value",
+			inputPath:       "{} ~ Patient.active",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.Boolean(false)},
+		},
+		{
+			name:            "unordered collections",
+			inputPath:       "Patient.name.given ~ ('kang' | 'SENPAI')",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:            "complex types",
+			inputPath:       "Patient.name[0] ~ Patient.name[1]",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.Boolean(false)},
+		},
+		{
+			name:            "code compared to string",
+			inputPath:       "Patient.gender ~ 'FEMALE'",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:            "not equivalent",
+			inputPath:       "'a' !~ 'A'",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{system.Boolean(false)},
+		},
+	}
+
+	testEvaluate(t, testCases)
+}
+
+func TestUnionExpression_Evaluates(t *testing.T) {
+	testCases := []evaluateTestCase{
+		{
+			name:            "returns union of given and family names",
+			inputPath:       "Patient.name.given | Patient.name.family",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{fhir.String("Senpai"), fhir.String("Kang"), fhir.String("Chu")},
+		},
+		{
+			name:            "eliminates duplicate literals",
+			inputPath:       "(1 | 2 | 1.0 | 'a')",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{system.Integer(1), system.Integer(2), system.String("a")},
+		},
+		{
+			name:            "union with empty collection",
+			inputPath:       "{} | Patient.name.family",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{fhir.String("Chu")},
+		},
+		{
+			name:            "returns union with union()",
+			inputPath:       "Patient.name.family.union(%context.name.given)",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{fhir.String("Chu"), fhir.String("Senpai"), fhir.String("Kang")},
+		},
+		{
+			name:            "keeps duplicates with combine()",
+			inputPath:       "Patient.name.family.combine(%context.name.given)",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{fhir.String("Chu"), fhir.String("Chu"), fhir.String("Senpai"), fhir.String("Kang")},
+		},
+	}
+
+	testEvaluate(t, testCases)
+}
+
 func TestParenthesizedExpression_MaintainsPrecedence(t *testing.T) {
 	patient := &ppb.Patient{
 		Name: []*dtpb.HumanName{
@@ -737,7 +983,7 @@ func TestParenthesizedExpression_MaintainsPrecedence(t *testing.T) {
 
 func TestFunctionInvocation_Evaluates(t *testing.T) {
 	testTime := time.Now()
-	testDateTime, _ := system.DateTimeFromProto(fhir.DateTime(testTime))
+	testDateTime := system.MustParseDateTime(testTime.Format("2006-01-02T15:04:05.000Z07:00"))
 	testCases := []evaluateTestCase{
 		{
 			name:            "returns nickname with where()",
@@ -951,6 +1197,98 @@ func TestFunctionInvocation_Evaluates(t *testing.T) {
 			inputCollection: []fhir.Resource{patientChu},
 			wantCollection:  system.Collection{system.Boolean(false), system.Boolean(true)},
 		},
+		{
+			name:            "returns nested items with repeat()",
+			inputPath:       "Questionnaire.repeat(item).linkId",
+			inputCollection: []fhir.Resource{nestedQuestionnaire},
+			wantCollection:  system.Collection{fhir.String("1"), fhir.String("2"), fhir.String("1.1")},
+		},
+		{
+			name:            "returns immediate child nodes with children()",
+			inputPath:       "Patient.name[0].children()",
+			inputCollection: []fhir.Resource{patientVoldemort},
+			wantCollection:  system.Collection{nameVoldemort.Family, nameVoldemort.Given[0]},
+		},
+		{
+			name:            "returns nested references with descendants()",
+			inputPath:       "Questionnaire.descendants().where($this is Reference).reference",
+			inputCollection: []fhir.Resource{nestedQuestionnaire},
+			wantCollection:  system.Collection{fhir.String("Questionnaire/1234")},
+		},
+		{
+			name:            "evaluate with single()",
+			inputPath:       "Patient.name.where(use = 'official').family.single()",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{fhir.String("Chu")},
+		},
+		{
+			name:            "evaluate with subsetOf()",
+			inputPath:       "Patient.name[0].given.subsetOf(%context.name.given)",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:            "evaluate with supersetOf()",
+			inputPath:       "Patient.name[0].given.supersetOf(%context.name.given)",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.Boolean(false)},
+		},
+	}
+
+	testEvaluate(t, testCases)
+}
+
+func TestOfTypeFunction_Evaluates(t *testing.T) {
+	quantity := &dtpb.Quantity{Value: fhir.Decimal(22.2)}
+	observation := &opb.Observation{
+		Value: &opb.Observation_ValueX{
+			Choice: &opb.Observation_ValueX_Quantity{Quantity: quantity},
+		},
+	}
+
+	testCases := []evaluateTestCase{
+		{
+			name:            "filters FHIR type",
+			inputPath:       "Observation.value.ofType(Quantity)",
+			inputCollection: []fhir.Resource{observation},
+			wantCollection:  system.Collection{quantity},
+		},
+		{
+			name:            "filters out mismatched FHIR type",
+			inputPath:       "Observation.value.ofType(string)",
+			inputCollection: []fhir.Resource{observation},
+			wantCollection:  system.Collection{},
+		},
+		{
+			name:            "filters with qualified FHIR type",
+			inputPath:       "Observation.children().ofType(FHIR.Quantity)",
+			inputCollection: []fhir.Resource{observation},
+			wantCollection:  system.Collection{quantity},
+		},
+		{
+			name:            "filters System types",
+			inputPath:       "(1 | 'a' | 2.5 | 3).ofType(Integer)",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{system.Integer(1), system.Integer(3)},
+		},
+		{
+			name:            "primitive type specifiers are case-sensitive",
+			inputPath:       "Patient.name.given.ofType(String)",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{},
+		},
+		{
+			name:            "filters subtypes",
+			inputPath:       "Patient.gender.ofType(FHIR.string)",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{patientChu.Gender},
+		},
+		{
+			name:            "filters resources",
+			inputPath:       "%context.ofType(Patient).id",
+			inputCollection: []fhir.Resource{patientChu, observation},
+			wantCollection:  system.Collection{patientChu.Id},
+		},
 	}
 
 	testEvaluate(t, testCases)
@@ -1099,9 +1437,15 @@ func TestComparisonExpression_ReturnsBool(t *testing.T) {
 			wantCollection:  system.Collection{system.Boolean(false)},
 		},
 		{
-			name:            "returns empty for quantities of different precision",
+			name:            "converts quantities of commensurable units",
 			inputPath:       "99.9 'cm' < 1 'm'",
 			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:            "returns empty for quantities of incommensurable units",
+			inputPath:       "1 'cm' < 1 'g'",
+			inputCollection: []fhir.Resource{},
 			wantCollection:  system.Collection{},
 		},
 		{
@@ -1133,6 +1477,55 @@ func TestComparisonExpression_ReturnsBool(t *testing.T) {
 	testEvaluate(t, testCases)
 }
 
+func TestMembershipExpression_Evaluates(t *testing.T) {
+	testCases := []evaluateTestCase{
+		{
+			name:            "code is in collection",
+			inputPath:       "'official' in Patient.name.use",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:            "code is not in collection",
+			inputPath:       "'usual' in Patient.name.use",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.Boolean(false)},
+		},
+		{
+			name:            "collection contains string",
+			inputPath:       "Patient.name.given contains 'Kang'",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:            "collection doesn't contain string",
+			inputPath:       "Patient.name.given contains 'Bob'",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.Boolean(false)},
+		},
+		{
+			name:            "integer is implicitly converted to decimal",
+			inputPath:       "1 in (1.0 | 2.0)",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:            "empty collection returns false",
+			inputPath:       "{} contains 'Bob'",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{system.Boolean(false)},
+		},
+		{
+			name:            "empty item returns empty",
+			inputPath:       "Patient.name.given contains {}",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{},
+		},
+	}
+
+	testEvaluate(t, testCases)
+}
+
 func TestArithmetic_ReturnsResult(t *testing.T) {
 	testCases := []evaluateTestCase{
 		{
@@ -1234,6 +1627,34 @@ func TestCompile_ReturnsError(t *testing.T) {
 			name:      "resolving invalid type specifier",
 			inputPath: "1 is System.Patient",
 		},
+		{
+			name:      "ofType with invalid type specifier",
+			inputPath: "Patient.name.ofType(NotAType)",
+		},
+		{
+			name:      "ofType with expression argument",
+			inputPath: "Patient.name.ofType(1 + 2)",
+		},
+		{
+			name:      "ofType without argument",
+			inputPath: "Patient.name.ofType()",
+		},
+		{
+			name:      "redefining variable in the same scope",
+			inputPath: "defineVariable('v', 1).defineVariable('v', 2)",
+		},
+		{
+			name:      "redefining variable within function argument",
+			inputPath: "defineVariable('v', 1).select(defineVariable('v', 2))",
+		},
+		{
+			name:      "redefining system variable",
+			inputPath: "defineVariable('context', 1)",
+		},
+		{
+			name:      "variable name is not a string literal",
+			inputPath: "defineVariable('v' + 'w', 1)",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -1282,6 +1703,21 @@ func TestEvaluate_ReturnsError(t *testing.T) {
 			inputPath:       "1 + true",
 			inputCollection: []fhir.Resource{},
 		},
+		{
+			name:            "referencing variable defined in sibling branch",
+			inputPath:       "defineVariable('v', 1).count() | %v",
+			inputCollection: []fhir.Resource{},
+		},
+		{
+			name:            "referencing variable outside of its scope",
+			inputPath:       "(defineVariable('v', 1).count() and true).select(%v)",
+			inputCollection: []fhir.Resource{},
+		},
+		{
+			name:            "referencing variable defined within function argument",
+			inputPath:       "select(defineVariable('v', 1)).select(%v)",
+			inputCollection: []fhir.Resource{patientChu},
+		},
 		{
 			name:            "misspelled identifier raises error",
 			inputPath:       "Patient.nam.given",
@@ -1312,9 +1748,34 @@ func TestEvaluate_ReturnsError(t *testing.T) {
 			},
 		},
 		{
-			name:            "negating unsupported type",
-			inputPath:       "-'string'",
-			inputCollection: []fhir.Resource{},
+			name:            "trace with non-string name and no sink",
+			inputPath:       "Patient.trace(1)",
+			inputCollection: []fhir.Resource{patientChu},
+		},
+		{
+			name:            "repeatAll with duplicating projection",
+			inputPath:       "(1 | 2).repeatAll($this.combine($this)).count()",
+			inputCollection: []fhir.Resource{},
+		},
+		{
+			name:            "single with multiple items",
+			inputPath:       "Patient.name.given.single()",
+			inputCollection: []fhir.Resource{patientChu},
+		},
+		{
+			name:            "iif with multi-item criterion",
+			inputPath:       "iif(Patient.name.given, 1, 2)",
+			inputCollection: []fhir.Resource{patientChu},
+		},
+		{
+			name:            "membership with non-singleton operand",
+			inputPath:       "Patient.name.given in Patient.name.given",
+			inputCollection: []fhir.Resource{patientChu},
+		},
+		{
+			name:            "negating unsupported type",
+			inputPath:       "-'string'",
+			inputCollection: []fhir.Resource{},
 		},
 	}
 
@@ -1331,6 +1792,986 @@ func TestEvaluate_ReturnsError(t *testing.T) {
 	}
 }
 
+func TestResolveFunction_Evaluates(t *testing.T) {
+	patientRef, _ := reference.Typed("Patient", "123")
+	practitionerRef, _ := reference.Typed("Practitioner", "456")
+	practitioner := &prpb.Practitioner{Id: fhir.ID("456")}
+	obs := &opb.Observation{
+		Subject: patientRef,
+		Focus: []*dtpb.Reference{
+			practitionerRef,
+			patientRef,
+			{Display: fhir.String("unresolvable logical reference")},
+		},
+	}
+	resolverOpt := evalopts.Resolver(resolver.NewMap(patientChu, practitioner))
+
+	testCases := []evaluateTestCase{
+		{
+			name:            "resolves reference",
+			inputPath:       "Observation.subject.resolve().name.given",
+			inputCollection: []fhir.Resource{obs},
+			wantCollection:  system.Collection{fhir.String("Senpai"), fhir.String("Kang")},
+			evaluateOptions: []fhirpath.EvaluateOption{resolverOpt},
+		},
+		{
+			name:            "filters references by resolved type",
+			inputPath:       "Observation.focus.where(resolve() is Patient)",
+			inputCollection: []fhir.Resource{obs},
+			wantCollection:  system.Collection{patientRef},
+			evaluateOptions: []fhirpath.EvaluateOption{resolverOpt},
+		},
+		{
+			name:            "resolves reference string",
+			inputPath:       "Observation.focus.reference.resolve().id",
+			inputCollection: []fhir.Resource{obs},
+			wantCollection:  system.Collection{fhir.ID("456"), fhir.ID("123")},
+			evaluateOptions: []fhirpath.EvaluateOption{resolverOpt},
+		},
+		{
+			name:            "returns empty without resolver",
+			inputPath:       "Observation.subject.resolve()",
+			inputCollection: []fhir.Resource{obs},
+			wantCollection:  system.Collection{},
+		},
+	}
+
+	testEvaluate(t, testCases)
+}
+
+func TestResolveFunction_ContainedReferences_Evaluates(t *testing.T) {
+	observation := func(family string) *opb.Observation {
+		patient, err := anypb.New(containedresource.Wrap(&ppb.Patient{
+			Id:   fhir.ID("p1"),
+			Name: []*dtpb.HumanName{{Family: fhir.String(family)}},
+		}))
+		if err != nil {
+			t.Fatalf("anypb.New() returned unexpected error: %v", err)
+		}
+		return &opb.Observation{
+			Contained: []*anypb.Any{patient},
+			Subject:   &dtpb.Reference{Reference: &dtpb.Reference_Fragment{Fragment: fhir.String("p1")}},
+		}
+	}
+	first, second := observation("Chu"), observation("Lee")
+	bundle := &bcrpb.Bundle{
+		Entry: []*bcrpb.Bundle_Entry{
+			{Resource: containedresource.Wrap(first)},
+			{Resource: containedresource.Wrap(second)},
+		},
+	}
+	resolverOpt := evalopts.Resolver(resolver.Contained(nil))
+
+	testCases := []evaluateTestCase{
+		{
+			name:            "resolves fragment in referencing resource",
+			inputPath:       "Observation.subject.resolve().name.family",
+			inputCollection: []fhir.Resource{second},
+			wantCollection:  system.Collection{fhir.String("Lee")},
+			evaluateOptions: []fhirpath.EvaluateOption{resolverOpt},
+		},
+		{
+			name:            "resolves fragment string in input resource",
+			inputPath:       "Observation.subject.reference.resolve().name.family",
+			inputCollection: []fhir.Resource{first},
+			wantCollection:  system.Collection{fhir.String("Chu")},
+			evaluateOptions: []fhirpath.EvaluateOption{resolverOpt},
+		},
+		{
+			name:            "resolves fragments in Bundle entries",
+			inputPath:       "Bundle.entry.resource.subject.resolve().name.family",
+			inputCollection: []fhir.Resource{bundle},
+			wantCollection:  system.Collection{fhir.String("Chu"), fhir.String("Lee")},
+			evaluateOptions: []fhirpath.EvaluateOption{resolverOpt},
+		},
+		{
+			name:            "resolves fragments with chained resolvers",
+			inputPath:       "Bundle.entry.resource.subject.resolve().name.family",
+			inputCollection: []fhir.Resource{bundle},
+			wantCollection:  system.Collection{fhir.String("Chu"), fhir.String("Lee")},
+			evaluateOptions: []fhirpath.EvaluateOption{evalopts.Resolver(resolver.NewMap()), resolverOpt},
+		},
+	}
+
+	testEvaluate(t, testCases)
+}
+
+func TestTerminologyFunctions_Evaluate(t *testing.T) {
+	const loinc = "http://loinc.org"
+	codeSystem := &cspb.CodeSystem{
+		Url: fhir.URI(loinc),
+		Concept: []*cspb.CodeSystem_ConceptDefinition{
+			{
+				Code: fhir.Code("LP7819-8"),
+				Concept: []*cspb.CodeSystem_ConceptDefinition{
+					{Code: fhir.Code("8867-4")},
+				},
+			},
+		},
+	}
+	valueSet := &vspb.ValueSet{
+		Url: fhir.URI("http://example.com/ValueSet/vital-signs"),
+		Expansion: &vspb.ValueSet_Expansion{
+			Contains: []*vspb.ValueSet_Expansion_Contains{
+				{System: fhir.URI(loinc), Code: fhir.Code("8867-4")},
+			},
+		},
+	}
+	provider, err := terminology.NewMemory(codeSystem, valueSet)
+	if err != nil {
+		t.Fatalf("NewMemory() returned unexpected error: %v", err)
+	}
+	obs := &opb.Observation{
+		Code: fhir.CodeableConcept("Heart rate", fhir.Coding(loinc, "8867-4")),
+	}
+	terminologyOpt := evalopts.TerminologyProvider(provider)
+	parentOpt := evalopts.EnvVariable("parent", fhir.Coding(loinc, "LP7819-8"))
+
+	testCases := []evaluateTestCase{
+		{
+			name:            "CodeableConcept is member of value set",
+			inputPath:       "Observation.code.memberOf('http://example.com/ValueSet/vital-signs')",
+			inputCollection: []fhir.Resource{obs},
+			wantCollection:  system.Collection{system.Boolean(true)},
+			evaluateOptions: []fhirpath.EvaluateOption{terminologyOpt},
+		},
+		{
+			name:            "Coding is subsumed by parent",
+			inputPath:       "Observation.code.coding.subsumedBy(%parent)",
+			inputCollection: []fhir.Resource{obs},
+			wantCollection:  system.Collection{system.Boolean(true)},
+			evaluateOptions: []fhirpath.EvaluateOption{terminologyOpt, parentOpt},
+		},
+		{
+			name:            "Coding does not subsume parent",
+			inputPath:       "Observation.code.subsumes(%parent)",
+			inputCollection: []fhir.Resource{obs},
+			wantCollection:  system.Collection{system.Boolean(false)},
+			evaluateOptions: []fhirpath.EvaluateOption{terminologyOpt, parentOpt},
+		},
+		{
+			name:            "returns empty without terminology provider",
+			inputPath:       "Observation.code.memberOf('http://example.com/ValueSet/vital-signs')",
+			inputCollection: []fhir.Resource{obs},
+			wantCollection:  system.Collection{},
+		},
+	}
+
+	testEvaluate(t, testCases)
+}
+
+func TestConformsToFunction_Evaluates(t *testing.T) {
+	const usCorePatient = "http://hl7.org/fhir/us/core/StructureDefinition/us-core-patient"
+	usCore := &ppb.Patient{
+		Id:   fhir.ID("us-core"),
+		Meta: &dtpb.Meta{Profile: []*dtpb.Canonical{{Value: usCorePatient}}},
+	}
+	bundle := &bcrpb.Bundle{
+		Entry: []*bcrpb.Bundle_Entry{
+			{Resource: containedresource.Wrap(patientChu)},
+			{Resource: containedresource.Wrap(usCore)},
+		},
+	}
+	rejectAll := evalopts.ProfileValidator(profileValidatorFunc(func(fhir.Base, string) (bool, error) {
+		return false, nil
+	}))
+
+	testCases := []evaluateTestCase{
+		{
+			name:            "filters resources by claimed profile",
+			inputPath:       "Bundle.entry.resource.where(conformsTo('" + usCorePatient + "')).id",
+			inputCollection: []fhir.Resource{bundle},
+			wantCollection:  system.Collection{fhir.ID("us-core")},
+		},
+		{
+			name:            "resource conforms to base definition",
+			inputPath:       "conformsTo('http://hl7.org/fhir/StructureDefinition/Patient')",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:            "uses provided profile validator",
+			inputPath:       "Bundle.entry.resource.where(conformsTo('" + usCorePatient + "')).id",
+			inputCollection: []fhir.Resource{bundle},
+			wantCollection:  system.Collection{},
+			evaluateOptions: []fhirpath.EvaluateOption{rejectAll},
+		},
+	}
+
+	testEvaluate(t, testCases)
+}
+
+type profileValidatorFunc func(fhir.Base, string) (bool, error)
+
+func (f profileValidatorFunc) ConformsTo(element fhir.Base, structure string) (bool, error) {
+	return f(element, structure)
+}
+
+func TestPrimitiveWithoutValue_Evaluates(t *testing.T) {
+	absent, _ := extension.FromElement("http://hl7.org/fhir/StructureDefinition/data-absent-reason", fhir.Code("unknown"))
+	patient := &ppb.Patient{
+		BirthDate: &dtpb.Date{Extension: []*dtpb.Extension{absent}},
+		Active: &dtpb.Boolean{Extension: []*dtpb.Extension{
+			absent,
+			extension.New("https://g.co/fhir/StructureDefinition/primitiveHasNoValue", fhir.Boolean(true)),
+		}},
+	}
+
+	testCases := []evaluateTestCase{
+		{
+			name:            "primitive without value exists",
+			inputPath:       "Patient.birthDate.exists()",
+			inputCollection: []fhir.Resource{patient},
+			wantCollection:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:            "primitive without value has no value",
+			inputPath:       "Patient.birthDate.hasValue()",
+			inputCollection: []fhir.Resource{patient},
+			wantCollection:  system.Collection{system.Boolean(false)},
+		},
+		{
+			name:            "primitive with value has value",
+			inputPath:       "Patient.birthDate.hasValue()",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:            "getValue returns system value",
+			inputPath:       "Patient.birthDate.getValue()",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.MustParseDate("2000-03-22")},
+		},
+		{
+			name:            "getValue of primitive without value is empty",
+			inputPath:       "Patient.birthDate.getValue()",
+			inputCollection: []fhir.Resource{patient},
+			wantCollection:  system.Collection{},
+		},
+		{
+			name:            "equality with primitive without value is empty",
+			inputPath:       "Patient.birthDate = @1970-01-01",
+			inputCollection: []fhir.Resource{patient},
+			wantCollection:  system.Collection{},
+		},
+		{
+			name:            "inequality with primitive without value is empty",
+			inputPath:       "Patient.birthDate != @1970-01-01",
+			inputCollection: []fhir.Resource{patient},
+			wantCollection:  system.Collection{},
+		},
+		{
+			name:            "comparison with primitive without value is empty",
+			inputPath:       "Patient.birthDate < @2000-01-01",
+			inputCollection: []fhir.Resource{patient},
+			wantCollection:  system.Collection{},
+		},
+		{
+			name:            "extensions of primitive without value are navigable",
+			inputPath:       "Patient.birthDate.extension('http://hl7.org/fhir/StructureDefinition/data-absent-reason').exists()",
+			inputCollection: []fhir.Resource{patient},
+			wantCollection:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:            "boolean without value is not a criterion",
+			inputPath:       "Patient.where(active).exists()",
+			inputCollection: []fhir.Resource{patient},
+			wantCollection:  system.Collection{system.Boolean(false)},
+		},
+	}
+
+	testEvaluate(t, testCases)
+}
+
+func TestHtmlChecks_Evaluates(t *testing.T) {
+	withNarrative := func(div string) *ppb.Patient {
+		return &ppb.Patient{
+			Text: &dtpb.Narrative{Div: &dtpb.Xhtml{Value: div}},
+		}
+	}
+
+	testCases := []evaluateTestCase{
+		{
+			name:            "valid narrative",
+			inputPath:       "Patient.text.`div`.htmlChecks()",
+			inputCollection: []fhir.Resource{withNarrative(`<div xmlns="http://www.w3.org/1999/xhtml"><p>Peter James <b>Chalmers</b></p></div>`)},
+			wantCollection:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:            "narrative with script",
+			inputPath:       "Patient.text.`div`.htmlChecks()",
+			inputCollection: []fhir.Resource{withNarrative(`<div xmlns="http://www.w3.org/1999/xhtml"><script>alert(1)</script>Peter</div>`)},
+			wantCollection:  system.Collection{system.Boolean(false)},
+		},
+		{
+			name:            "missing narrative",
+			inputPath:       "Patient.text.`div`.htmlChecks()",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{},
+		},
+	}
+
+	testEvaluate(t, testCases)
+}
+
+func TestBoundaryFunctions_Evaluate(t *testing.T) {
+	patient := &ppb.Patient{
+		BirthDate: fhir.MustParseDate("2023-04"),
+	}
+
+	testCases := []evaluateTestCase{
+		{
+			name:           "decimal low boundary",
+			inputPath:      "1.587.lowBoundary()",
+			wantCollection: system.Collection{system.MustParseDecimal("1.58650000")},
+		},
+		{
+			name:           "negative decimal high boundary",
+			inputPath:      "(-1.587).highBoundary(2)",
+			wantCollection: system.Collection{system.MustParseDecimal("-1.58")},
+		},
+		{
+			name:           "decimal precision",
+			inputPath:      "1.58700.precision()",
+			wantCollection: system.Collection{system.Integer(5)},
+		},
+		{
+			name:           "date low boundary",
+			inputPath:      "@2014.lowBoundary(6)",
+			wantCollection: system.Collection{system.MustParseDate("2014-01")},
+		},
+		{
+			name:           "dateTime high boundary",
+			inputPath:      "@2014-01-01T08.highBoundary(17)",
+			wantCollection: system.Collection{system.MustParseDateTime("2014-01-01T08:59:59.999")},
+		},
+		{
+			name:           "time precision",
+			inputPath:      "@T10:30:00.000.precision()",
+			wantCollection: system.Collection{system.Integer(9)},
+		},
+		{
+			name:            "partial birth date boundaries",
+			inputPath:       "Patient.birthDate.lowBoundary() = @2023-04-01 and Patient.birthDate.highBoundary() = @2023-04-30",
+			inputCollection: []fhir.Resource{patient},
+			wantCollection:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:            "partial birth date precision",
+			inputPath:       "Patient.birthDate.precision()",
+			inputCollection: []fhir.Resource{patient},
+			wantCollection:  system.Collection{system.Integer(6)},
+		},
+	}
+
+	testEvaluate(t, testCases)
+}
+
+func TestDefineVariable_Evaluates(t *testing.T) {
+	testCases := []evaluateTestCase{
+		{
+			name:            "variable holds input by default",
+			inputPath:       "Patient.name.given.defineVariable('given').first().select(%given)",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{fhir.String("Senpai"), fhir.String("Kang")},
+		},
+		{
+			name:            "variable holds expression result",
+			inputPath:       "Patient.defineVariable('family', name.family.first()).name.where(family = %family).given",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{fhir.String("Senpai"), fhir.String("Kang")},
+		},
+		{
+			name:            "variable is visible within function arguments",
+			inputPath:       "defineVariable('n', 2).select((1 | 2 | 3).where($this > %n))",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.Integer(3)},
+		},
+		{
+			name:            "variable is visible in nested function arguments",
+			inputPath:       "defineVariable('n', 1).select(defineVariable('m', 2).select(%n + %m))",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.Integer(3)},
+		},
+		{
+			name:            "variables in sibling branches are independent",
+			inputPath:       "defineVariable('v', 1).select(%v) | defineVariable('v', 2).select(%v)",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.Integer(1), system.Integer(2)},
+		},
+		{
+			name:            "variables in sibling function arguments are independent",
+			inputPath:       "iif(true, defineVariable('v', 'a').select(%v), defineVariable('v', 'b').select(%v))",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.String("a")},
+		},
+		{
+			name:            "variables are redefined per iteration",
+			inputPath:       "(1 | 2).select(defineVariable('v', $this * 10).select(%v))",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.Integer(10), system.Integer(20)},
+		},
+		{
+			name:           "defineVariable returns input",
+			inputPath:      "(1 | 2).defineVariable('v', 3)",
+			wantCollection: system.Collection{system.Integer(1), system.Integer(2)},
+		},
+	}
+
+	testEvaluate(t, testCases)
+}
+
+func TestDateTimeComponentFunctions_Evaluate(t *testing.T) {
+	testCases := []evaluateTestCase{
+		{
+			name:            "year of birth date",
+			inputPath:       "Patient.birthDate.yearOf()",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.Integer(2000)},
+		},
+		{
+			name:            "age banding by birth year",
+			inputPath:       "Patient.birthDate.yearOf() < 2001 and Patient.birthDate.monthOf() = 3",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:           "component below precision",
+			inputPath:      "@2014-01.dayOf()",
+			wantCollection: system.Collection{},
+		},
+		{
+			name:           "timezone offset",
+			inputPath:      "@2014-01-05T10:30:00-05:30.timezoneOffsetOf()",
+			wantCollection: system.Collection{system.MustParseDecimal("-5.5")},
+		},
+		{
+			name:           "date and time of dateTime",
+			inputPath:      "@2014-01-05T10:30:00.dateOf() = @2014-01-05 and @2014-01-05T10:30:00.timeOf() = @T10:30:00",
+			wantCollection: system.Collection{system.Boolean(true)},
+		},
+	}
+
+	testEvaluate(t, testCases)
+}
+
+func TestCalendarArithmeticFunctions_Evaluate(t *testing.T) {
+	testCases := []evaluateTestCase{
+		{
+			name:            "age in whole years",
+			inputPath:       "Patient.birthDate.duration(@2024-03-21, 'years')",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.Integer(23)},
+		},
+		{
+			name:            "calendar years crossed",
+			inputPath:       "Patient.birthDate.difference(@2024-03-21, 'years')",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.Integer(24)},
+		},
+		{
+			name:           "days crossed in timezone of first operand",
+			inputPath:      "@2020-01-01T23:00:00+10:00.difference(@2020-01-02T01:00:00+10:00, 'days')",
+			wantCollection: system.Collection{system.Integer(1)},
+		},
+		{
+			name:           "hours between dateTimes in different timezones",
+			inputPath:      "@2024-01-01T10:30:00+02:00.duration(@2024-01-01T10:00:00Z, 'hours')",
+			wantCollection: system.Collection{system.Integer(1)},
+		},
+		{
+			name:           "below precision",
+			inputPath:      "@2024-01.duration(@2024-03-01, 'days')",
+			wantCollection: system.Collection{},
+		},
+	}
+
+	testEvaluate(t, testCases)
+}
+
+func TestQuantityUnitConversion_Evaluate(t *testing.T) {
+	testCases := []evaluateTestCase{
+		{
+			name:           "compares prefixed units",
+			inputPath:      "1 'kg' > 900 'g'",
+			wantCollection: system.Collection{system.Boolean(true)},
+		},
+		{
+			name:           "equates concentrations",
+			inputPath:      "5 'mg/dL' = 50 'mg/L'",
+			wantCollection: system.Collection{system.Boolean(true)},
+		},
+		{
+			name:           "equates calendar seconds with UCUM units",
+			inputPath:      "1 second = 1000 'ms'",
+			wantCollection: system.Collection{system.Boolean(true)},
+		},
+		{
+			name:           "doesn't equate calendar years with UCUM years",
+			inputPath:      "1 year = 1 'a'",
+			wantCollection: system.Collection{},
+		},
+		{
+			name:           "adds in the unit of the left operand",
+			inputPath:      "1 'kg' + 500 'g'",
+			wantCollection: system.Collection{system.MustParseQuantity("1.5", "kg")},
+		},
+		{
+			name:           "subtracts customary units",
+			inputPath:      "1 '[ft_i]' - 6 '[in_i]'",
+			wantCollection: system.Collection{system.MustParseQuantity("0.5", "[ft_i]")},
+		},
+		{
+			name:           "returns empty when equating incommensurable units",
+			inputPath:      "1 'kg' = 1 'm'",
+			wantCollection: system.Collection{},
+		},
+		{
+			name:           "returns empty when equating units with very large exponents",
+			inputPath:      "1 'm99999999' = 1 'm'",
+			wantCollection: system.Collection{},
+		},
+	}
+
+	testEvaluate(t, testCases)
+}
+
+func TestCalendarDurations_Evaluate(t *testing.T) {
+	// Relationships between calendar durations, and between calendar and
+	// definite durations, from the FHIRPath specification.
+	// See: https://hl7.org/fhirpath/N1/#time-valued-quantities
+	testCases := []evaluateTestCase{
+		{
+			name:           "1 year is 12 months",
+			inputPath:      "1 year = 12 months",
+			wantCollection: system.Collection{system.Boolean(true)},
+		},
+		{
+			name:           "1 week is 7 days",
+			inputPath:      "1 week = 7 days",
+			wantCollection: system.Collection{system.Boolean(true)},
+		},
+		{
+			name:           "1 day is 24 hours",
+			inputPath:      "1 day = 24 hours",
+			wantCollection: system.Collection{system.Boolean(true)},
+		},
+		{
+			name:           "1 hour is 60 minutes",
+			inputPath:      "1 hour = 60 minutes",
+			wantCollection: system.Collection{system.Boolean(true)},
+		},
+		{
+			name:           "1 minute is 60 seconds",
+			inputPath:      "1 minute = 60 seconds",
+			wantCollection: system.Collection{system.Boolean(true)},
+		},
+		{
+			name:           "1 second is 1 's'",
+			inputPath:      "1 second = 1 's'",
+			wantCollection: system.Collection{system.Boolean(true)},
+		},
+		{
+			name:           "1 millisecond is 1 'ms'",
+			inputPath:      "1 millisecond = 1 'ms'",
+			wantCollection: system.Collection{system.Boolean(true)},
+		},
+		{
+			name:           "calendar and definite years aren't comparable",
+			inputPath:      "1 year = 1 'a'",
+			wantCollection: system.Collection{},
+		},
+		{
+			name:           "calendar and definite weeks aren't comparable",
+			inputPath:      "1 'wk' = 7 days",
+			wantCollection: system.Collection{},
+		},
+		{
+			name:           "calendar and definite years are equivalent",
+			inputPath:      "1 year ~ 1 'a'",
+			wantCollection: system.Collection{system.Boolean(true)},
+		},
+		{
+			name:           "calendar and definite seconds are equivalent",
+			inputPath:      "1 second ~ 1 's'",
+			wantCollection: system.Collection{system.Boolean(true)},
+		},
+		{
+			name:           "adds calendar year",
+			inputPath:      "@2020-01-01 + 1 year",
+			wantCollection: system.Collection{system.MustParseDate("2021-01-01")},
+		},
+		{
+			name:           "adds definite year",
+			inputPath:      "@2020-01-01 + 1 'a'",
+			wantCollection: system.Collection{system.MustParseDate("2020-12-31")},
+		},
+		{
+			name:           "subtracts definite days",
+			inputPath:      "@2020-03-01 - 1 'd'",
+			wantCollection: system.Collection{system.MustParseDate("2020-02-29")},
+		},
+		{
+			name:           "adds definite minutes to time",
+			inputPath:      "@T08:00:00 + 90 'min'",
+			wantCollection: system.Collection{system.MustParseTime("09:30:00")},
+		},
+	}
+
+	testEvaluate(t, testCases)
+}
+
+func TestMicrosecondPrecision_Evaluate(t *testing.T) {
+	observation := &opb.Observation{
+		Issued: &dtpb.Instant{
+			ValueUs:   1577836800000001,
+			Timezone:  "Z",
+			Precision: dtpb.Instant_MICROSECOND,
+		},
+	}
+	testCases := []evaluateTestCase{
+		{
+			name:            "retains microseconds of instant",
+			inputPath:       "Observation.issued > @2020-01-01T00:00:00.000Z",
+			inputCollection: []fhir.Resource{observation},
+			wantCollection:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:            "equates microsecond literal",
+			inputPath:       "Observation.issued = @2020-01-01T00:00:00.000001Z",
+			inputCollection: []fhir.Resource{observation},
+			wantCollection:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:            "reports microsecond precision",
+			inputPath:       "Observation.issued.precision()",
+			inputCollection: []fhir.Resource{observation},
+			wantCollection:  system.Collection{system.Integer(20)},
+		},
+	}
+
+	testEvaluate(t, testCases)
+}
+
+func TestQuantityMultiplicationAndDivision_Evaluate(t *testing.T) {
+	testCases := []evaluateTestCase{
+		{
+			name:           "body mass index",
+			inputPath:      "(70 'kg' / (1.75 'm' * 1.75 'm')) > 22.8 'kg/m2'",
+			wantCollection: system.Collection{system.Boolean(true)},
+		},
+		{
+			name:           "dose per kilogram",
+			inputPath:      "(700 'mg' / 70 'kg').toQuantity('mg/kg') = 10 'mg/kg'",
+			wantCollection: system.Collection{system.Boolean(true)},
+		},
+		{
+			name:           "dimensionless quotient",
+			inputPath:      "10 'mg' / 4 'mg'",
+			wantCollection: system.Collection{system.MustParseDecimal("2.5")},
+		},
+		{
+			name:           "dimensionless quotient of prefixed units",
+			inputPath:      "100 'cm' / 1 'm'",
+			wantCollection: system.Collection{system.MustParseDecimal("1")},
+		},
+		{
+			name:           "dimensionless quotient of mass units",
+			inputPath:      "5 'mg' / 1 'g'",
+			wantCollection: system.Collection{system.MustParseDecimal("0.005")},
+		},
+		{
+			name:           "scales by integer",
+			inputPath:      "2 * 3 'kg'",
+			wantCollection: system.Collection{system.MustParseQuantity("6", "kg")},
+		},
+		{
+			name:           "divides by decimal",
+			inputPath:      "3 'kg' / 1.5",
+			wantCollection: system.Collection{system.MustParseQuantity("2", "kg")},
+		},
+		{
+			name:           "divides number by quantity",
+			inputPath:      "2 / 4 'h'",
+			wantCollection: system.Collection{system.MustParseQuantity("0.5", "/h")},
+		},
+		{
+			name:           "returns empty on division by zero",
+			inputPath:      "1 'kg' / 0 'm'",
+			wantCollection: system.Collection{},
+		},
+	}
+
+	testEvaluate(t, testCases)
+}
+
+func TestMathFunctions_Evaluate(t *testing.T) {
+	testCases := []evaluateTestCase{
+		{
+			name:           "integer power",
+			inputPath:      "2.power(3)",
+			wantCollection: system.Collection{system.Integer(8)},
+		},
+		{
+			name:           "decimal power",
+			inputPath:      "2.power(0.5) > 1.41",
+			wantCollection: system.Collection{system.Boolean(true)},
+		},
+		{
+			name:           "undefined power",
+			inputPath:      "(-1).power(0.5)",
+			wantCollection: system.Collection{},
+		},
+		{
+			name:           "log with base",
+			inputPath:      "100.log(10)",
+			wantCollection: system.Collection{system.MustParseDecimal("2")},
+		},
+		{
+			name:           "round with precision",
+			inputPath:      "3.14159.round(3)",
+			wantCollection: system.Collection{system.MustParseDecimal("3.142")},
+		},
+		{
+			name:           "round quantity",
+			inputPath:      "1.55 'mg'.round(1)",
+			wantCollection: system.Collection{system.MustParseQuantity("1.6", "mg")},
+		},
+		{
+			name:           "floor quantity",
+			inputPath:      "(-1.5 'mg').floor()",
+			wantCollection: system.Collection{system.MustParseQuantity("-2", "mg")},
+		},
+		{
+			name:           "sqrt of negative number",
+			inputPath:      "(-1).sqrt()",
+			wantCollection: system.Collection{},
+		},
+	}
+
+	testEvaluate(t, testCases)
+}
+
+func TestStringFunctions_Evaluate(t *testing.T) {
+	testCases := []evaluateTestCase{
+		{
+			name:           "split and join",
+			inputPath:      "'a,b,c'.split(',').join('|')",
+			wantCollection: system.Collection{system.String("a|b|c")},
+		},
+		{
+			name:            "join FHIR strings",
+			inputPath:       "Patient.name.given.join(', ')",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.String("Senpai, Kang")},
+		},
+		{
+			name:           "trim",
+			inputPath:      "'  padded  '.trim()",
+			wantCollection: system.Collection{system.String("padded")},
+		},
+		{
+			name:           "lastIndexOf",
+			inputPath:      "'banana'.lastIndexOf('a')",
+			wantCollection: system.Collection{system.Integer(5)},
+		},
+		{
+			name:            "matchesFull on FHIR code",
+			inputPath:       "Patient.gender.matchesFull('fem.*')",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:           "encode and decode round trip",
+			inputPath:      "'Jieun'.encode('base64').decode('base64')",
+			wantCollection: system.Collection{system.String("Jieun")},
+		},
+		{
+			name:           "escape html",
+			inputPath:      "'<div>'.escape('html')",
+			wantCollection: system.Collection{system.String("&lt;div&gt;")},
+		},
+		{
+			name:           "empty input propagates",
+			inputPath:      "{}.encode('hex')",
+			wantCollection: system.Collection{},
+		},
+	}
+
+	testEvaluate(t, testCases)
+}
+
+func TestSortingFunctions_Evaluate(t *testing.T) {
+	testCases := []evaluateTestCase{
+		{
+			name:           "sort without keys",
+			inputPath:      "(3 | 1 | 2).sort()",
+			wantCollection: system.Collection{system.Integer(1), system.Integer(2), system.Integer(3)},
+		},
+		{
+			name:           "sort descending with desc keyword",
+			inputPath:      "(3 | 1 | 2).sort($this desc)",
+			wantCollection: system.Collection{system.Integer(3), system.Integer(2), system.Integer(1)},
+		},
+		{
+			name:           "sort by negated key",
+			inputPath:      "(3 | 1 | 2).sort(-$this)",
+			wantCollection: system.Collection{system.Integer(3), system.Integer(2), system.Integer(1)},
+		},
+		{
+			name:           "sort descending by negated key",
+			inputPath:      "(3 | 1 | 2).sort(-$this desc)",
+			wantCollection: system.Collection{system.Integer(1), system.Integer(2), system.Integer(3)},
+		},
+		{
+			name:           "sort descending with nested sort",
+			inputPath:      "(1 | 2).sort((3 | 4).sort($this desc).first() + $this desc)",
+			wantCollection: system.Collection{system.Integer(2), system.Integer(1)},
+		},
+		{
+			name:           "sort with asc keyword",
+			inputPath:      "('b' | 'c' | 'a').sort($this asc)",
+			wantCollection: system.Collection{system.String("a"), system.String("b"), system.String("c")},
+		},
+		{
+			name:            "sort by multiple keys",
+			inputPath:       "Patient.name.sort(family desc, given.first()).given",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{fhir.String("Kang"), fhir.String("Senpai")},
+		},
+		{
+			name:            "coalesce returns first non-empty argument",
+			inputPath:       "Patient.coalesce(deceased, active)",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{fhir.Boolean(true)},
+		},
+		{
+			name:            "coalesce doesn't evaluate later arguments",
+			inputPath:       "Patient.coalesce(id, name.given.single())",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{fhir.ID("123")},
+		},
+		{
+			name:           "repeatAll retains duplicates",
+			inputPath:      "(1 | 2).repeatAll(iif($this < 3, $this + 1)).count()",
+			wantCollection: system.Collection{system.Integer(3)},
+		},
+	}
+
+	testEvaluate(t, testCases)
+}
+
+func TestTraceFunction_SendsToTraceSink(t *testing.T) {
+	var names []string
+	var collections []system.Collection
+	sink := func(name string, collection system.Collection) {
+		names = append(names, name)
+		collections = append(collections, collection)
+	}
+	expression := fhirpath.MustCompile("Patient.name.trace('names', given).family.trace('families')")
+
+	got, err := expression.Evaluate([]fhir.Resource{patientChu}, evalopts.TraceSink(sink))
+	if err != nil {
+		t.Fatalf("Evaluating trace() returned unexpected error: %v", err)
+	}
+
+	wantResult := system.Collection{fhir.String("Chu"), fhir.String("Chu")}
+	if diff := cmp.Diff(wantResult, got, protocmp.Transform()); diff != "" {
+		t.Errorf("Evaluating trace() returned unexpected diff (-want, +got)\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"names", "families"}, names); diff != "" {
+		t.Errorf("trace() sent unexpected names (-want, +got)\n%s", diff)
+	}
+	wantCollections := []system.Collection{
+		{fhir.String("Senpai"), fhir.String("Kang")},
+		wantResult,
+	}
+	if diff := cmp.Diff(wantCollections, collections, protocmp.Transform()); diff != "" {
+		t.Errorf("trace() sent unexpected collections (-want, +got)\n%s", diff)
+	}
+}
+
+func TestToQuantity_Evaluate(t *testing.T) {
+	testCases := []evaluateTestCase{
+		{
+			name:           "parses quoted UCUM unit",
+			inputPath:      "'5 \\'mg\\''.toQuantity()",
+			wantCollection: system.Collection{system.MustParseQuantity("5", "mg")},
+		},
+		{
+			name:           "parses calendar duration keyword",
+			inputPath:      "'4 days'.toQuantity() = 4 days",
+			wantCollection: system.Collection{system.Boolean(true)},
+		},
+		{
+			name:           "converts to commensurable unit",
+			inputPath:      "'1 \\'kg\\''.toQuantity('g')",
+			wantCollection: system.Collection{system.MustParseQuantity("1000", "g")},
+		},
+		{
+			name:           "converts calendar duration to UCUM day",
+			inputPath:      "'4 days'.toQuantity('d')",
+			wantCollection: system.Collection{system.MustParseQuantity("4", "d")},
+		},
+		{
+			name:           "converts calendar duration to UCUM hour",
+			inputPath:      "'4 days'.toQuantity('h')",
+			wantCollection: system.Collection{system.MustParseQuantity("96", "h")},
+		},
+		{
+			name:           "returns empty for quoted unit that isn't UCUM",
+			inputPath:      "'10 \\'foo\\''.toQuantity()",
+			wantCollection: system.Collection{},
+		},
+		{
+			name:           "doesn't convert quoted unit that isn't UCUM",
+			inputPath:      "'10 \\'foo\\''.convertsToQuantity()",
+			wantCollection: system.Collection{system.Boolean(false)},
+		},
+		{
+			name:           "returns empty for calendar month to UCUM unit",
+			inputPath:      "'1 month'.toQuantity('d')",
+			wantCollection: system.Collection{},
+		},
+		{
+			name:           "converts integer",
+			inputPath:      "5.toQuantity()",
+			wantCollection: system.Collection{system.MustParseQuantity("5", "1")},
+		},
+		{
+			name:           "returns empty for incommensurable unit",
+			inputPath:      "'1 \\'kg\\''.toQuantity('m')",
+			wantCollection: system.Collection{},
+		},
+		{
+			name:           "converts between calendar durations",
+			inputPath:      "'2 years'.convertsToQuantity('months')",
+			wantCollection: system.Collection{system.Boolean(true)},
+		},
+		{
+			name:           "rejects unquoted UCUM unit",
+			inputPath:      "'5 mg'.convertsToQuantity()",
+			wantCollection: system.Collection{system.Boolean(false)},
+		},
+	}
+
+	testEvaluate(t, testCases)
+}
+
+func TestTraceFunction_WithoutTraceSink_ReturnsInput(t *testing.T) {
+	expression := fhirpath.MustCompile("Patient.name.family.trace('families')")
+
+	got, err := expression.Evaluate([]fhir.Resource{patientChu})
+	if err != nil {
+		t.Fatalf("Evaluating trace() returned unexpected error: %v", err)
+	}
+
+	wantResult := system.Collection{fhir.String("Chu"), fhir.String("Chu")}
+	if diff := cmp.Diff(wantResult, got, protocmp.Transform()); diff != "" {
+		t.Errorf("Evaluating trace() returned unexpected diff (-want, +got)\n%s", diff)
+	}
+}
+
 func TestExternalConstantExpression_ReturnsConstant(t *testing.T) {
 	testCases := []evaluateTestCase{
 		{
@@ -30,7 +30,7 @@ func Tree(expr string) (grammar.IProgContext, error) {
 	lexer := grammar.NewfhirpathLexer(inputStream)
 	lexer.RemoveErrorListeners()
 	lexer.AddErrorListener(errorListener)
-	tokens := antlr.NewCommonTokenStream(lexer, antlr.TokenDefaultChannel)
+	tokens := antlr.NewCommonTokenStream(newSortDirectionSource(lexer), antlr.TokenDefaultChannel)
 
 	// Parse the tokens
 	p := grammar.NewfhirpathParser(tokens)
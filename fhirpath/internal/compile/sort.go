@@ -0,0 +1,120 @@
+package compile
+
+import (
+	"unicode"
+
+	"github.com/antlr4-go/antlr/v4"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/internal/parser"
+)
+
+// operators are the tokens that can't end an expression, since they must be
+// followed by an operand. A direction keyword following one of these is the
+// operand, rather than a direction.
+var operators = map[string]bool{
+	".": true, "+": true, "-": true, "*": true, "/": true, "&": true, "|": true,
+	"=": true, "!=": true, "~": true, "!~": true, "<": true, ">": true, "<=": true,
+	">=": true, "%": true, "and": true, "or": true, "xor": true, "implies": true,
+	"div": true, "mod": true, "is": true, "as": true, "in": true, "contains": true,
+}
+
+// sortDirectionSource is a lexer that moves the direction keywords
+// that may follow the keys of a sort() invocation, as in sort($this desc), to
+// the parser.SortDirectionChannel. The FHIRPath N1 grammar doesn't allow for
+// these keywords, so they are hidden from the parser, and are instead read
+// back by the visitor when it constructs the sort() invocation.
+type sortDirectionSource struct {
+	antlr.Lexer
+	tokens []antlr.Token
+}
+
+// sortDirectionToken is a direction keyword on the sort direction channel.
+type sortDirectionToken struct {
+	antlr.Token
+}
+
+// GetChannel returns the sort direction channel.
+func (sortDirectionToken) GetChannel() int {
+	return parser.SortDirectionChannel
+}
+
+// newSortDirectionSource reads all tokens of lexer, moving the sort direction
+// keywords to the sort direction channel.
+func newSortDirectionSource(lexer antlr.Lexer) *sortDirectionSource {
+	var tokens []antlr.Token
+	for {
+		token := lexer.NextToken()
+		tokens = append(tokens, token)
+		if token.GetTokenType() == antlr.TokenEOF {
+			break
+		}
+	}
+
+	var onChannel []int
+	for i, token := range tokens {
+		if token.GetChannel() == antlr.TokenDefaultChannel && token.GetTokenType() != antlr.TokenEOF {
+			onChannel = append(onChannel, i)
+		}
+	}
+	text := func(i int) string { return tokens[onChannel[i]].GetText() }
+
+	// group is an open bracket, tracking the first token of its current
+	// argument.
+	type group struct {
+		isSort   bool
+		argStart int
+	}
+	var groups []group
+	for i := range onChannel {
+		switch keyword := text(i); keyword {
+		case "(":
+			isSort := i > 0 && text(i-1) == parser.SortFunction
+			groups = append(groups, group{isSort: isSort, argStart: i + 1})
+		case "[", "{":
+			groups = append(groups, group{argStart: i + 1})
+		case ")", "]", "}":
+			if len(groups) > 0 {
+				groups = groups[:len(groups)-1]
+			}
+		case ",":
+			if len(groups) > 0 {
+				groups[len(groups)-1].argStart = i + 1
+			}
+		case "asc", "desc":
+			if len(groups) == 0 || i+1 == len(onChannel) {
+				continue
+			}
+			current := groups[len(groups)-1]
+			// The keyword must follow a complete key, rather than being the key
+			// itself or an operand within it, and must end the argument. Keywords
+			// such as contains are identifiers when they begin the key or follow a
+			// dot.
+			if !current.isSort || i == current.argStart {
+				continue
+			}
+			if prev := text(i - 1); operators[prev] && !isIdentifier(prev, i-1 == current.argStart || text(i-2) == ".") {
+				continue
+			}
+			if next := text(i + 1); next != "," && next != ")" {
+				continue
+			}
+			tokens[onChannel[i]] = sortDirectionToken{tokens[onChannel[i]]}
+		}
+	}
+	return &sortDirectionSource{Lexer: lexer, tokens: tokens}
+}
+
+// isIdentifier reports whether the operator keyword is used as an identifier,
+// which is the case for words that begin an expression or follow a dot.
+func isIdentifier(operator string, beginsTerm bool) bool {
+	return beginsTerm && unicode.IsLetter(rune(operator[0]))
+}
+
+// NextToken returns the next token, repeating the EOF token at the end of the
+// input.
+func (s *sortDirectionSource) NextToken() antlr.Token {
+	token := s.tokens[0]
+	if len(s.tokens) > 1 {
+		s.tokens = s.tokens[1:]
+	}
+	return token
+}
@@ -0,0 +1,123 @@
+package compile
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/antlr4-go/antlr/v4"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/internal/grammar"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/internal/parser"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSortDirectionSource(t *testing.T) {
+	testCases := []struct {
+		name           string
+		expr           string
+		wantParsed     string
+		wantDirections []string
+	}{
+		{
+			name:           "descending key",
+			expr:           "Observation.effective.sort($this desc)",
+			wantParsed:     "Observation.effective.sort($this)",
+			wantDirections: []string{"desc"},
+		},
+		{
+			name:           "ascending key",
+			expr:           "Observation.effective.sort($this asc)",
+			wantParsed:     "Observation.effective.sort($this)",
+			wantDirections: []string{"asc"},
+		},
+		{
+			name:           "multiple keys",
+			expr:           "Patient.name.sort(family desc, given.first() asc, use)",
+			wantParsed:     "Patient.name.sort(family,given.first(),use)",
+			wantDirections: []string{"desc", "asc"},
+		},
+		{
+			name:           "compound key",
+			expr:           "(1 | 2).sort($this + 1 desc)",
+			wantParsed:     "(1|2).sort($this+1)",
+			wantDirections: []string{"desc"},
+		},
+		{
+			name:           "nested sort",
+			expr:           "sort(a.sort(b desc).first() desc)",
+			wantParsed:     "sort(a.sort(b).first())",
+			wantDirections: []string{"desc", "desc"},
+		},
+		{
+			name:           "key within brackets",
+			expr:           "sort(a[0] desc, b)",
+			wantParsed:     "sort(a[0],b)",
+			wantDirections: []string{"desc"},
+		},
+		{
+			name:       "field named desc",
+			expr:       "sort(desc).select(x.desc)",
+			wantParsed: "sort(desc).select(x.desc)",
+		},
+		{
+			name:           "field named desc with direction",
+			expr:           "sort(desc desc)",
+			wantParsed:     "sort(desc)",
+			wantDirections: []string{"desc"},
+		},
+		{
+			name:       "field named desc as operand",
+			expr:       "(3 | 1 | 2).sort($this + desc)",
+			wantParsed: "(3|1|2).sort($this+desc)",
+		},
+		{
+			name:           "field named desc as operand with direction",
+			expr:           "sort(a and desc desc)",
+			wantParsed:     "sort(aanddesc)",
+			wantDirections: []string{"desc"},
+		},
+		{
+			name:       "negated field named desc",
+			expr:       "sort(-desc)",
+			wantParsed: "sort(-desc)",
+		},
+		{
+			name:           "identifier named like operator",
+			expr:           "sort(contains desc, x.is asc)",
+			wantParsed:     "sort(contains,x.is)",
+			wantDirections: []string{"desc", "asc"},
+		},
+		{
+			name:       "keyword outside of sort",
+			expr:       "where(x desc)",
+			wantParsed: "where(xdesc)",
+		},
+		{
+			name:       "no sort",
+			expr:       "Patient.name.given",
+			wantParsed: "Patient.name.given",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			source := newSortDirectionSource(grammar.NewfhirpathLexer(antlr.NewInputStream(tc.expr)))
+
+			var parsed strings.Builder
+			var directions []string
+			for token := source.NextToken(); token.GetTokenType() != antlr.TokenEOF; token = source.NextToken() {
+				switch token.GetChannel() {
+				case antlr.TokenDefaultChannel:
+					parsed.WriteString(token.GetText())
+				case parser.SortDirectionChannel:
+					directions = append(directions, token.GetText())
+				}
+			}
+			if got := parsed.String(); got != tc.wantParsed {
+				t.Errorf("newSortDirectionSource(%q) parsed tokens = %q, want %q", tc.expr, got, tc.wantParsed)
+			}
+			if diff := cmp.Diff(tc.wantDirections, directions); diff != "" {
+				t.Errorf("newSortDirectionSource(%q) directions returned unexpected diff (-want, +got)\n%s", tc.expr, diff)
+			}
+		})
+	}
+}
@@ -18,11 +18,17 @@ func EvaluateAdd(lhs, rhs system.Any) (system.Any, error) {
 		if right, ok := rhs.(system.Integer); ok {
 			return left.Add(right)
 		}
+		if right, ok := rhs.(system.Quantity); ok {
+			return inUnitOf(left, right).Add(right)
+		}
 		return nil, typeMismatch(Add, lhs, rhs)
 	case system.Decimal:
 		if right, ok := rhs.(system.Decimal); ok {
 			return left.Add(right), nil
 		}
+		if right, ok := rhs.(system.Quantity); ok {
+			return inUnitOf(left, right).Add(right)
+		}
 		return nil, typeMismatch(Add, lhs, rhs)
 	case system.Time:
 		if right, ok := rhs.(system.Quantity); ok {
@@ -40,8 +46,11 @@ func EvaluateAdd(lhs, rhs system.Any) (system.Any, error) {
 		}
 		return nil, typeMismatch(Add, lhs, rhs)
 	case system.Quantity:
-		if right, ok := rhs.(system.Quantity); ok {
+		switch right := rhs.(type) {
+		case system.Quantity:
 			return left.Add(right)
+		case system.Integer, system.Decimal:
+			return left.Add(inUnitOf(right, left))
 		}
 		return nil, typeMismatch(Add, lhs, rhs)
 	default:
@@ -56,11 +65,17 @@ func EvaluateSub(lhs, rhs system.Any) (system.Any, error) {
 		if right, ok := rhs.(system.Integer); ok {
 			return left.Sub(right)
 		}
+		if right, ok := rhs.(system.Quantity); ok {
+			return inUnitOf(left, right).Sub(right)
+		}
 		return nil, typeMismatch(Sub, lhs, rhs)
 	case system.Decimal:
 		if right, ok := rhs.(system.Decimal); ok {
 			return left.Sub(right), nil
 		}
+		if right, ok := rhs.(system.Quantity); ok {
+			return inUnitOf(left, right).Sub(right)
+		}
 		return nil, typeMismatch(Sub, lhs, rhs)
 	case system.Time:
 		if right, ok := rhs.(system.Quantity); ok {
@@ -78,8 +93,11 @@ func EvaluateSub(lhs, rhs system.Any) (system.Any, error) {
 		}
 		return nil, typeMismatch(Sub, lhs, rhs)
 	case system.Quantity:
-		if right, ok := rhs.(system.Quantity); ok {
+		switch right := rhs.(type) {
+		case system.Quantity:
 			return left.Sub(right)
+		case system.Integer, system.Decimal:
+			return left.Sub(inUnitOf(right, left))
 		}
 		return nil, typeMismatch(Sub, lhs, rhs)
 	default:
@@ -94,20 +112,26 @@ func EvaluateMul(lhs, rhs system.Any) (system.Any, error) {
 		if right, ok := rhs.(system.Integer); ok {
 			return left.Mul(right)
 		}
-		if _, ok := rhs.(system.Quantity); ok {
-			return nil, fmt.Errorf("%w: PHP-7340", ErrToBeImplemented)
+		if right, ok := rhs.(system.Quantity); ok {
+			return unitless(left).Mul(right)
 		}
 		return nil, typeMismatch(Mul, lhs, rhs)
 	case system.Decimal:
 		if right, ok := rhs.(system.Decimal); ok {
 			return left.Mul(right), nil
 		}
-		if _, ok := rhs.(system.Quantity); ok {
-			return nil, fmt.Errorf("%w: PHP-7340", ErrToBeImplemented)
+		if right, ok := rhs.(system.Quantity); ok {
+			return unitless(left).Mul(right)
 		}
 		return nil, typeMismatch(Mul, lhs, rhs)
 	case system.Quantity:
-		return nil, fmt.Errorf("%w: PHP-7171", ErrToBeImplemented)
+		switch right := rhs.(type) {
+		case system.Quantity:
+			return left.Mul(right)
+		case system.Integer, system.Decimal:
+			return left.Mul(unitless(right))
+		}
+		return nil, typeMismatch(Mul, lhs, rhs)
 	default:
 		return nil, typeMismatch(Mul, lhs, rhs)
 	}
@@ -120,20 +144,26 @@ func EvaluateDiv(lhs, rhs system.Any) (system.Any, error) {
 		if right, ok := rhs.(system.Integer); ok {
 			return left.Div(right), nil
 		}
-		if _, ok := rhs.(system.Quantity); ok {
-			return nil, fmt.Errorf("%w: PHP-7340", ErrToBeImplemented)
+		if right, ok := rhs.(system.Quantity); ok {
+			return unitless(left).Div(right)
 		}
 		return nil, typeMismatch(Div, lhs, rhs)
 	case system.Decimal:
 		if right, ok := rhs.(system.Decimal); ok {
 			return left.Div(right), nil
 		}
-		if _, ok := rhs.(system.Quantity); ok {
-			return nil, fmt.Errorf("%w: PHP-7340", ErrToBeImplemented)
+		if right, ok := rhs.(system.Quantity); ok {
+			return unitless(left).Div(right)
 		}
 		return nil, typeMismatch(Div, lhs, rhs)
 	case system.Quantity:
-		return nil, fmt.Errorf("%w: PHP-7171", ErrToBeImplemented)
+		switch right := rhs.(type) {
+		case system.Quantity:
+			return left.Div(right)
+		case system.Integer, system.Decimal:
+			return left.Div(unitless(right))
+		}
+		return nil, typeMismatch(Div, lhs, rhs)
 	default:
 		return nil, typeMismatch(Div, lhs, rhs)
 	}
@@ -191,6 +221,34 @@ func EvaluateMod(lhs, rhs system.Any) (system.Any, error) {
 	}
 }
 
+// unity is the Quantity of the unity '1'.
+var unity = system.MustParseQuantity("1", "1")
+
+// unitless returns an Integer or Decimal as a Quantity of the unity, which
+// scales the Quantity it's multiplied with or divided by.
+func unitless(number system.Any) system.Quantity {
+	return system.Normalize(number, unity).(system.Quantity)
+}
+
+// inUnitOf returns an Integer or Decimal as a Quantity in the unit of q, as
+// it's implicitly converted for addition and subtraction.
+func inUnitOf(number system.Any, q system.Quantity) system.Quantity {
+	return system.Normalize(number, q).(system.Quantity)
+}
+
+// normalizeOperands implicitly converts lhs and rhs to a common type. Integers
+// and Decimals aren't converted to Quantities, as they're scalars in Quantity
+// multiplication and division, so the operators convert them instead.
+func normalizeOperands(lhs, rhs system.Any) (system.Any, system.Any) {
+	_, leftQuantity := lhs.(system.Quantity)
+	_, rightQuantity := rhs.(system.Quantity)
+	if leftQuantity != rightQuantity {
+		return lhs, rhs
+	}
+	lhs = system.Normalize(lhs, rhs)
+	return lhs, system.Normalize(rhs, lhs)
+}
+
 // typeMismatch generates an unsupported operation error.
 func typeMismatch(op Operator, lhs, rhs system.Any) error {
 	return fmt.Errorf("%w: %T %s %T", system.ErrTypeMismatch, lhs, op, rhs)
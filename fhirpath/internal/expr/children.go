@@ -0,0 +1,98 @@
+package expr
+
+import (
+	"github.com/fhir-fli/fhirpath-go/fhirpath/system"
+	"github.com/fhir-fli/fhirpath-go/internal/protofields"
+	"github.com/fhir-fli/fhirpath-go/pkg/containedresource"
+	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	bcrpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// Children returns the immediate child nodes of every item in the input
+// collection, in field declaration order. Choice types and contained resources
+// are unwrapped in the same way as field navigation, so that the returned
+// nodes are what an equivalent FieldExpression would produce.
+//
+// Items that are not protos (ie. System types) have no children. The
+// google/fhir specific fields that hold primitive values (such as 'value',
+// 'value_us', 'precision', and 'timezone') are not considered nodes, since they
+// are not elements in the FHIR spec.
+func Children(input system.Collection) system.Collection {
+	output := system.Collection{}
+	for _, item := range input {
+		message, ok := item.(proto.Message)
+		if !ok {
+			continue
+		}
+		output = append(output, childrenOf(unwrapNode(message))...)
+	}
+	return output
+}
+
+// childrenOf enumerates the populated message fields of a single node.
+func childrenOf(message proto.Message) system.Collection {
+	output := system.Collection{}
+	reflect := message.ProtoReflect()
+	fields := reflect.Descriptor().Fields()
+
+	reference, isReference := message.(*dtpb.Reference)
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		if field.Kind() != protoreflect.MessageKind || !reflect.Has(field) {
+			continue
+		}
+		// The literal reference of a Reference is modelled as a oneof of typed IDs,
+		// which is normalized into a single string, just as with field access.
+		if isReference && field.ContainingOneof() != nil {
+			continue
+		}
+		if !field.IsList() {
+			output = appendNode(output, reflect.Get(field).Message().Interface())
+			continue
+		}
+		list := reflect.Get(field).List()
+		for j := 0; j < list.Len(); j++ {
+			output = appendNode(output, list.Get(j).Message().Interface())
+		}
+	}
+	if isReference {
+		if ref := unwrapReference(reference); ref != nil {
+			output = append(output, ref)
+		}
+	}
+	return output
+}
+
+func appendNode(output system.Collection, message proto.Message) system.Collection {
+	if node := unwrapNode(message); node != nil {
+		return append(output, node)
+	}
+	return output
+}
+
+// unwrapNode removes any wrapping around the node that isn't modelled in
+// FHIR, such as choice-type oneofs, ContainedResources, and the Any protos that
+// hold contained resources. Returns nil if the wrapper holds no value.
+func unwrapNode(message proto.Message) proto.Message {
+	if packed, ok := message.(*anypb.Any); ok {
+		unpacked, err := packed.UnmarshalNew()
+		if err != nil {
+			return nil
+		}
+		message = unpacked
+	}
+	if contained, ok := message.(*bcrpb.ContainedResource); ok {
+		resource := containedresource.Unwrap(contained)
+		if resource == nil {
+			return nil
+		}
+		return resource
+	}
+	if message.ProtoReflect().Descriptor().Oneofs().ByName("choice") != nil {
+		return protofields.UnwrapOneofField(message, "choice")
+	}
+	return message
+}
@@ -0,0 +1,85 @@
+package expr_test
+
+import (
+	"testing"
+
+	"github.com/fhir-fli/fhirpath-go/fhir"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/internal/expr"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/system"
+	"github.com/fhir-fli/fhirpath-go/internal/element/reference"
+	"github.com/fhir-fli/fhirpath-go/pkg/containedresource"
+	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+func TestChildren_ReturnsChildNodes(t *testing.T) {
+	given := fhir.String("Kobe")
+	family := fhir.String("Bryant")
+	name := &dtpb.HumanName{
+		Given:  []*dtpb.String{given},
+		Family: family,
+	}
+	deceased := fhir.Boolean(true)
+	patient := &ppb.Patient{
+		Id:   fhir.ID("123"),
+		Name: []*dtpb.HumanName{name},
+		Deceased: &ppb.Patient_DeceasedX{
+			Choice: &ppb.Patient_DeceasedX_Boolean{Boolean: deceased},
+		},
+	}
+	ref, _ := reference.Typed("Patient", "123")
+
+	testCases := []struct {
+		name  string
+		input system.Collection
+		want  system.Collection
+	}{
+		{
+			name:  "empty input",
+			input: system.Collection{},
+			want:  system.Collection{},
+		},
+		{
+			name:  "system types have no children",
+			input: system.Collection{system.String("hello"), system.Integer(1)},
+			want:  system.Collection{},
+		},
+		{
+			name:  "primitive value is not a child",
+			input: system.Collection{fhir.String("hello")},
+			want:  system.Collection{},
+		},
+		{
+			name:  "returns fields in declaration order",
+			input: system.Collection{name},
+			want:  system.Collection{family, given},
+		},
+		{
+			name:  "unwraps choice types",
+			input: system.Collection{patient},
+			want:  system.Collection{patient.Id, name, deceased},
+		},
+		{
+			name:  "unwraps contained resources",
+			input: system.Collection{containedresource.Wrap(patient)},
+			want:  system.Collection{patient.Id, name, deceased},
+		},
+		{
+			name:  "normalizes literal references",
+			input: system.Collection{ref},
+			want:  system.Collection{ref.GetType(), fhir.String("Patient/123")},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := expr.Children(tc.input)
+
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("Children(%s) returned unexpected diff (-want, +got):\n%s", tc.name, diff)
+			}
+		})
+	}
+}
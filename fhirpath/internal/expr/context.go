@@ -3,7 +3,14 @@ package expr
 import (
 	"time"
 
+	"github.com/fhir-fli/fhirpath-go/fhir"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/profile"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/resolver"
 	"github.com/fhir-fli/fhirpath-go/fhirpath/system"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/terminology"
+	"github.com/fhir-fli/fhirpath-go/pkg/containedresource"
+	bcrpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	"google.golang.org/protobuf/proto"
 )
 
 // Context holds the global time and external constant
@@ -23,6 +30,142 @@ type Context struct {
 	// the 'LastResult' will be the unwrapped list from 'given', but we need the
 	// 'name' element that contains the 'given' list in order to alter the list.
 	BeforeLastResult system.Collection
+
+	// Iteration holds the state of the innermost iterating function, such as
+	// where() or select(), which binds the $this, $index, and $total
+	// invocations. This is nil when not evaluating within such a function.
+	Iteration *Iteration
+
+	// TraceSink receives the collections logged by the trace() function. Tracing
+	// is disabled if this is nil.
+	TraceSink func(name string, collection system.Collection)
+
+	// Resolver locates the resources referenced by the resolve() function. No
+	// references are resolved if this is nil.
+	Resolver resolver.Resolver
+
+	// Terminology answers the terminology questions of the memberOf(),
+	// subsumes(), and subsumedBy() functions. These functions yield empty
+	// results if this is nil.
+	Terminology terminology.Provider
+
+	// ProfileValidator decides the result of the conformsTo() function. The
+	// profiles claimed by resources are used if this is nil.
+	ProfileValidator profile.Validator
+
+	// Variables holds the values of the variables defined by the
+	// defineVariable() function. Each clone of the Context evaluates in a child
+	// scope, so that definitions are not visible outside of the operand or
+	// argument that makes them; the compiler ensures that variables are only
+	// referenced within the scope of their definition.
+	Variables *Variables
+
+	// Containers records the resource that holds each element reached by
+	// navigation, so that the resolve() function can resolve fragment
+	// references in the resource that holds them. It is shared by all clones
+	// of the Context. Elements are not recorded if this is nil.
+	Containers *Containers
+}
+
+// Containers maps the elements reached by navigation to the innermost
+// resources that hold them. Elements are recorded as they are navigated to,
+// so only elements that are the result of navigation are known; those created
+// by other operations, such as literals, are not.
+type Containers struct {
+	holders map[proto.Message]fhir.Resource
+}
+
+// NewContainers returns an empty record of containing resources.
+func NewContainers() *Containers {
+	return &Containers{holders: map[proto.Message]fhir.Resource{}}
+}
+
+// Record records that the elements were reached by navigating from parent.
+// They are held by parent if it is a resource, and by the resource that holds
+// parent otherwise.
+func (c *Containers) Record(parent any, elements system.Collection) {
+	if c == nil {
+		return
+	}
+	if contained, ok := parent.(*bcrpb.ContainedResource); ok {
+		parent = containedresource.Unwrap(contained)
+	}
+	holder, ok := parent.(fhir.Resource)
+	if !ok {
+		if holder, ok = c.Holder(parent); !ok {
+			return
+		}
+	}
+	for _, element := range elements {
+		if message, ok := element.(proto.Message); ok {
+			c.holders[message] = holder
+		}
+	}
+}
+
+// Holder returns the resource that holds element. Returns false if element
+// wasn't recorded.
+func (c *Containers) Holder(element any) (fhir.Resource, bool) {
+	message, ok := element.(proto.Message)
+	if c == nil || !ok {
+		return nil, false
+	}
+	holder, ok := c.holders[message]
+	return holder, ok
+}
+
+// Variables is a scope of the variables defined by the defineVariable()
+// function. The variables of a scope are visible to its child scopes.
+type Variables struct {
+	values map[string]system.Collection
+	parent *Variables
+}
+
+// NewVariables returns an empty root scope of variables.
+func NewVariables() *Variables {
+	return &Variables{}
+}
+
+// Define sets the value of the variable name in the scope v.
+func (v *Variables) Define(name string, value system.Collection) {
+	if v.values == nil {
+		v.values = map[string]system.Collection{}
+	}
+	v.values[name] = value
+}
+
+// Lookup returns the value of the variable name, as defined in the scope v or
+// the innermost of its ancestors. Returns false if the variable isn't
+// defined.
+func (v *Variables) Lookup(name string) (system.Collection, bool) {
+	for ; v != nil; v = v.parent {
+		if value, ok := v.values[name]; ok {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// child returns a new scope nested in v, or nil if v is nil.
+func (v *Variables) child() *Variables {
+	if v == nil {
+		return nil
+	}
+	return &Variables{parent: v}
+}
+
+// Iteration holds the state of an iterating function while it evaluates its
+// argument for a single item of the input collection.
+type Iteration struct {
+	// This is the item currently under evaluation.
+	This any
+
+	// Index is the 0-based index of This within the collection being iterated.
+	Index int
+
+	// Total is the running total of an aggregate() call, and is empty for all
+	// other iterating functions.
+	Total system.Collection
 }
 
 // Clone copies this Context object to produce a new instance.
@@ -31,9 +174,24 @@ func (c *Context) Clone() *Context {
 		Now:               c.Now,
 		ExternalConstants: c.ExternalConstants,
 		LastResult:        c.LastResult,
+		Iteration:         c.Iteration,
+		TraceSink:         c.TraceSink,
+		Resolver:          c.Resolver,
+		Terminology:       c.Terminology,
+		ProfileValidator:  c.ProfileValidator,
+		Variables:         c.Variables.child(),
+		Containers:        c.Containers,
 	}
 }
 
+// WithIteration returns a copy of this Context with the given iteration
+// state, for evaluating the argument of an iterating function.
+func (c *Context) WithIteration(iteration Iteration) *Context {
+	ctx := c.Clone()
+	ctx.Iteration = &iteration
+	return ctx
+}
+
 // InitializeContext returns a base context, initialized with current time and initial
 // constant variables set.
 func InitializeContext(input system.Collection) *Context {
@@ -43,5 +201,6 @@ func InitializeContext(input system.Collection) *Context {
 			"context": input,
 			"ucum":    system.String("http://unitsofmeasure.org"),
 		},
+		Variables: NewVariables(),
 	}
 }
@@ -27,6 +27,7 @@ var (
 	ErrToBeImplemented  = errors.New("expression not yet implemented")
 	ErrInvalidField     = errors.New("invalid field")
 	ErrConstantNotFound = errors.New("external constant not found")
+	ErrVariableNotFound = errors.New("variable not defined")
 )
 
 // Expression is the abstraction for all FHIRPath expressions,
@@ -73,6 +74,52 @@ func (*IdentityExpression) Evaluate(ctx *Context, input system.Collection) (syst
 
 var _ Expression = (*IdentityExpression)(nil)
 
+// ThisExpression evaluates the $this invocation, which refers to the item
+// currently under evaluation by an iterating function. Outside of an iterating
+// function, $this refers to the input collection.
+type ThisExpression struct{}
+
+// Evaluate returns the item under evaluation, or the input collection if not
+// evaluating within an iterating function.
+func (*ThisExpression) Evaluate(ctx *Context, input system.Collection) (system.Collection, error) {
+	if ctx.Iteration == nil {
+		return input, nil
+	}
+	return system.Collection{ctx.Iteration.This}, nil
+}
+
+var _ Expression = (*ThisExpression)(nil)
+
+// IterationIndexExpression evaluates the $index invocation, which refers to
+// the index of the item currently under evaluation by an iterating function.
+type IterationIndexExpression struct{}
+
+// Evaluate returns the index of the item under evaluation, or an empty
+// collection if not evaluating within an iterating function.
+func (*IterationIndexExpression) Evaluate(ctx *Context, input system.Collection) (system.Collection, error) {
+	if ctx.Iteration == nil {
+		return system.Collection{}, nil
+	}
+	return system.Collection{system.Integer(ctx.Iteration.Index)}, nil
+}
+
+var _ Expression = (*IterationIndexExpression)(nil)
+
+// TotalExpression evaluates the $total invocation, which refers to the
+// running total of an aggregate() call.
+type TotalExpression struct{}
+
+// Evaluate returns the running total, or an empty collection if not
+// evaluating within aggregate().
+func (*TotalExpression) Evaluate(ctx *Context, input system.Collection) (system.Collection, error) {
+	if ctx.Iteration == nil || ctx.Iteration.Total == nil {
+		return system.Collection{}, nil
+	}
+	return ctx.Iteration.Total, nil
+}
+
+var _ Expression = (*TotalExpression)(nil)
+
 // FieldExpression is the expression that accesses the specified
 // FieldName in the input collection.
 type FieldExpression struct {
@@ -83,6 +130,23 @@ type FieldExpression struct {
 // Evaluate filters the input collections by those that contain
 // the FieldName string, and returns the result.
 func (e *FieldExpression) Evaluate(ctx *Context, input system.Collection) (system.Collection, error) {
+	if ctx.Containers == nil {
+		return e.evaluate(input)
+	}
+	output := system.Collection{}
+	for _, item := range input {
+		fields, err := e.evaluate(system.Collection{item})
+		if err != nil {
+			return nil, err
+		}
+		ctx.Containers.Record(item, fields)
+		output = append(output, fields...)
+	}
+	return output, nil
+}
+
+// evaluate returns the FieldName fields of the items of input.
+func (e *FieldExpression) evaluate(input system.Collection) (system.Collection, error) {
 	output := system.Collection{}
 
 	for _, item := range input {
@@ -120,7 +184,7 @@ func (e *FieldExpression) Evaluate(ctx *Context, input system.Collection) (syste
 			// which it is to find the appropriate field.
 			if fieldName == "reference" {
 				if reference, ok := message.(*dtpb.Reference); ok {
-					refString := e.unwrapReference(reference)
+					refString := unwrapReference(reference)
 					if refString != nil {
 						output = append(output, refString)
 					}
@@ -172,7 +236,7 @@ func (e *FieldExpression) Evaluate(ctx *Context, input system.Collection) (syste
 			continue
 		}
 
-		unwrap := e.unwrapOneof
+		unwrap := unwrapOneof
 		if e.Permissive {
 			unwrap = func(obj proto.Message) proto.Message { return obj }
 		}
@@ -223,7 +287,9 @@ func (e *FieldExpression) errField(object any) error {
 	return fmt.Errorf("%w: %s not a field on %T", ErrInvalidField, e.FieldName, object)
 }
 
-func (e *FieldExpression) unwrapReference(ref *dtpb.Reference) *dtpb.String {
+// unwrapReference converts the literal reference held in the Reference's
+// oneof into its string form, e.g. Type/ID.
+func unwrapReference(ref *dtpb.Reference) *dtpb.String {
 	if ref.GetReference() == nil {
 		return nil
 	}
@@ -253,7 +319,9 @@ func (e *FieldExpression) unwrapReference(ref *dtpb.Reference) *dtpb.String {
 	}
 }
 
-func (e *FieldExpression) unwrapOneof(obj proto.Message) proto.Message {
+// unwrapOneof returns the message held by a ValueX or ContainedResource
+// wrapper, or the input if it isn't one.
+func unwrapOneof(obj proto.Message) proto.Message {
 	message := obj.ProtoReflect()
 	descriptor := message.Descriptor()
 	if name := string(descriptor.Name()); !(strings.HasSuffix(name, "ValueX") || name == "ContainedResource") {
@@ -408,6 +476,103 @@ func (e *EqualityExpression) Evaluate(ctx *Context, input system.Collection) (sy
 
 var _ Expression = (*EqualityExpression)(nil)
 
+// EquivalenceExpression allows checking equivalence between the results of
+// two expressions, as with the '~' and '!~' operators.
+type EquivalenceExpression struct {
+	Left  Expression
+	Right Expression
+	Not   bool
+}
+
+// Evaluate evaluates the two subexpressions, and returns true if their
+// contents are equivalent, using the functionality of
+// system.Collection.Equivalent. Unlike equality, equivalence always yields a
+// boolean result; two empty collections are equivalent.
+func (e *EquivalenceExpression) Evaluate(ctx *Context, input system.Collection) (system.Collection, error) {
+	leftResult, err := e.Left.Evaluate(ctx.Clone(), input)
+	if err != nil {
+		return nil, err
+	}
+	rightResult, err := e.Right.Evaluate(ctx.Clone(), input)
+	if err != nil {
+		return nil, err
+	}
+
+	result := leftResult.Equivalent(rightResult)
+	if e.Not {
+		result = !result
+	}
+	return system.Collection{system.Boolean(result)}, nil
+}
+
+var _ Expression = (*EquivalenceExpression)(nil)
+
+// MembershipExpression enables evaluation of the membership operators 'in'
+// and 'contains'.
+type MembershipExpression struct {
+	Left  Expression
+	Right Expression
+	Op    Operator
+}
+
+// Evaluate evaluates the two subexpressions, and returns true if the singleton
+// operand is a member of the collection operand. For 'in', the singleton is the
+// left operand; for 'contains', it is the right operand. If the singleton is
+// empty, returns an empty collection. If the collection is empty, returns false.
+func (e *MembershipExpression) Evaluate(ctx *Context, input system.Collection) (system.Collection, error) {
+	leftResult, err := e.Left.Evaluate(ctx.Clone(), input)
+	if err != nil {
+		return nil, err
+	}
+	rightResult, err := e.Right.Evaluate(ctx.Clone(), input)
+	if err != nil {
+		return nil, err
+	}
+
+	var item, collection system.Collection
+	switch e.Op {
+	case In:
+		item, collection = leftResult, rightResult
+	case Contains:
+		item, collection = rightResult, leftResult
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrInvalidOperator, e.Op)
+	}
+
+	if len(item) == 0 {
+		return system.Collection{}, nil
+	}
+	if len(item) != 1 {
+		return nil, fmt.Errorf("%w: '%s' operand contains %v elements", ErrNotSingleton, e.Op, len(item))
+	}
+	return system.Collection{system.Boolean(collection.Contains(item[0]))}, nil
+}
+
+var _ Expression = (*MembershipExpression)(nil)
+
+// UnionExpression enables evaluation of the union operator '|'.
+type UnionExpression struct {
+	Left  Expression
+	Right Expression
+}
+
+// Evaluate evaluates both subexpressions and merges the results into a single
+// collection, eliminating any duplicate values as determined by the equals (=)
+// operator.
+func (e *UnionExpression) Evaluate(ctx *Context, input system.Collection) (system.Collection, error) {
+	leftResult, err := e.Left.Evaluate(ctx.Clone(), input)
+	if err != nil {
+		return nil, err
+	}
+	rightResult, err := e.Right.Evaluate(ctx.Clone(), input)
+	if err != nil {
+		return nil, err
+	}
+	return leftResult.Union(rightResult), nil
+}
+
+var _ Expression = (*UnionExpression)(nil)
+
 // FunctionExpression enables evaluation of Function Invocation expressions.
 // It holds the function and function arguments.
 type FunctionExpression struct {
@@ -492,6 +657,34 @@ func (e *AsExpression) Evaluate(ctx *Context, input system.Collection) (system.C
 
 var _ Expression = (*AsExpression)(nil)
 
+// TypeSpecifierExpression holds the type specifier argument of a type
+// function, such as ofType(). A type specifier is not a value, so this raises
+// an error if it is evaluated.
+type TypeSpecifierExpression struct {
+	Type reflection.TypeSpecifier
+}
+
+// Evaluate raises an error, since type specifiers can't be evaluated.
+func (e *TypeSpecifierExpression) Evaluate(*Context, system.Collection) (system.Collection, error) {
+	return nil, fmt.Errorf("%w: type specifier can't be evaluated", ErrInvalidType)
+}
+
+var _ Expression = (*TypeSpecifierExpression)(nil)
+
+// SortKeyExpression holds a key of the sort() function that is followed by a
+// direction keyword, as in sort($this desc).
+type SortKeyExpression struct {
+	Expr       Expression
+	Descending bool
+}
+
+// Evaluate evaluates the key, irrespective of its direction.
+func (e *SortKeyExpression) Evaluate(ctx *Context, input system.Collection) (system.Collection, error) {
+	return e.Expr.Evaluate(ctx, input)
+}
+
+var _ Expression = (*SortKeyExpression)(nil)
+
 // BooleanExpression enables evaluation of boolean expressions,
 // including "and", "or", "xor", and "implies".
 type BooleanExpression struct {
@@ -563,10 +756,16 @@ func (e *ComparisonExpression) Evaluate(ctx *Context, input system.Collection) (
 	}
 
 	leftPrimitive, err := system.From(leftResult[0])
+	if errors.Is(err, system.ErrNoValue) {
+		return system.Collection{}, nil // Primitives with only extensions are treated as empty.
+	}
 	if err != nil {
 		return nil, fmt.Errorf("%w: %w", ErrInvalidType, err)
 	}
 	rightPrimitive, err := system.From(rightResult[0])
+	if errors.Is(err, system.ErrNoValue) {
+		return system.Collection{}, nil
+	}
 	if err != nil {
 		return nil, fmt.Errorf("%w: %w", ErrInvalidType, err)
 	}
@@ -636,22 +835,30 @@ func (e *ArithmeticExpression) Evaluate(ctx *Context, input system.Collection) (
 
 	// Cast contents to system types. Addition and subtraction is not supported for protos.
 	leftPrimitive, err := system.From(leftResult[0])
+	if errors.Is(err, system.ErrNoValue) {
+		return system.Collection{}, nil // Primitives with only extensions are treated as empty.
+	}
 	if err != nil {
 		return nil, fmt.Errorf("%w: %w", ErrInvalidType, err)
 	}
 	rightPrimitive, err := system.From(rightResult[0])
+	if errors.Is(err, system.ErrNoValue) {
+		return system.Collection{}, nil
+	}
 	if err != nil {
 		return nil, fmt.Errorf("%w: %w", ErrInvalidType, err)
 	}
 
 	// Implicitly convert types
-	leftPrimitive = system.Normalize(leftPrimitive, rightPrimitive)
-	rightPrimitive = system.Normalize(rightPrimitive, leftPrimitive)
+	leftPrimitive, rightPrimitive = normalizeOperands(leftPrimitive, rightPrimitive)
 
 	result, err := e.Op(leftPrimitive, rightPrimitive)
 	if errors.Is(err, system.ErrIntOverflow) {
 		return system.Collection{}, nil // "Operations that cause arithmetic overflow or underflow will result in empty ( { } )".
 	}
+	if errors.Is(err, system.ErrDivisionByZero) {
+		return system.Collection{}, nil
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -680,12 +887,13 @@ func (e *ConcatExpression) Evaluate(ctx *Context, input system.Collection) (syst
 		return nil, err
 	}
 
-	// Convert empty collection to empty string
-	if len(leftResult) == 0 {
-		leftResult = append(leftResult, system.String(""))
+	// Convert empty collections, and primitives with only extensions, to empty
+	// strings.
+	if len(leftResult) == 0 || (len(leftResult) == 1 && !system.HasValue(leftResult[0])) {
+		leftResult = system.Collection{system.String("")}
 	}
-	if len(rightResult) == 0 {
-		rightResult = append(rightResult, system.String(""))
+	if len(rightResult) == 0 || (len(rightResult) == 1 && !system.HasValue(rightResult[0])) {
+		rightResult = system.Collection{system.String("")}
 	}
 
 	if len(leftResult) > 1 || len(rightResult) > 1 {
@@ -736,6 +944,49 @@ func (e *ExternalConstantExpression) Evaluate(ctx *Context, input system.Collect
 
 var _ Expression = (*ExternalConstantExpression)(nil)
 
+// VariableExpression enables evaluation of the variables defined by the
+// defineVariable() function.
+type VariableExpression struct {
+	Name string
+}
+
+// Evaluate retrieves the value of the variable from the Context. Returns an
+// error if the variable has not been defined.
+func (e *VariableExpression) Evaluate(ctx *Context, input system.Collection) (system.Collection, error) {
+	value, ok := ctx.Variables.Lookup(e.Name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrVariableNotFound, e.Name)
+	}
+	return value, nil
+}
+
+var _ Expression = (*VariableExpression)(nil)
+
+// DefineVariableExpression enables evaluation of the defineVariable()
+// function, defining the variable Name in the scope of the invocation. This is
+// separate from the function invocation Value, which yields the value of the
+// variable, since functions are evaluated in a child scope.
+type DefineVariableExpression struct {
+	Name  string
+	Value Expression
+}
+
+// Evaluate defines the variable to hold the result of Value, and returns the
+// input unchanged. Returns an error if the Context doesn't hold variables.
+func (e *DefineVariableExpression) Evaluate(ctx *Context, input system.Collection) (system.Collection, error) {
+	if ctx.Variables == nil {
+		return nil, errors.New("defineVariable() requires a context that holds variables")
+	}
+	value, err := e.Value.Evaluate(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	ctx.Variables.Define(e.Name, value)
+	return input, nil
+}
+
+var _ Expression = (*DefineVariableExpression)(nil)
+
 // NegationExpression enables negation of number values (Integer, Decimal, Quantity).
 type NegationExpression struct {
 	Expr Expression
@@ -758,6 +1009,9 @@ func (e *NegationExpression) Evaluate(ctx *Context, input system.Collection) (sy
 	}
 
 	primitive, err := system.From(result[0])
+	if errors.Is(err, system.ErrNoValue) {
+		return system.Collection{}, nil
+	}
 	if err != nil {
 		return nil, fmt.Errorf("%w: can't negate complex type %T", ErrInvalidType, result[0])
 	}
@@ -682,6 +682,317 @@ func TestEqualityExpression_RaisesError(t *testing.T) {
 	}
 }
 
+func TestIterationExpressions_ReturnResult(t *testing.T) {
+	iteration := &expr.Context{
+		Iteration: &expr.Iteration{
+			This:  system.String("item"),
+			Index: 2,
+			Total: system.Collection{system.Integer(10)},
+		},
+	}
+	input := system.Collection{system.String("input")}
+
+	testCases := []struct {
+		name           string
+		expr           expr.Expression
+		ctx            *expr.Context
+		wantCollection system.Collection
+	}{
+		{
+			name:           "$this returns item under evaluation",
+			expr:           &expr.ThisExpression{},
+			ctx:            iteration,
+			wantCollection: system.Collection{system.String("item")},
+		},
+		{
+			name:           "$this returns input outside iteration",
+			expr:           &expr.ThisExpression{},
+			ctx:            &expr.Context{},
+			wantCollection: input,
+		},
+		{
+			name:           "$index returns index of item",
+			expr:           &expr.IterationIndexExpression{},
+			ctx:            iteration,
+			wantCollection: system.Collection{system.Integer(2)},
+		},
+		{
+			name:           "$index returns empty outside iteration",
+			expr:           &expr.IterationIndexExpression{},
+			ctx:            &expr.Context{},
+			wantCollection: system.Collection{},
+		},
+		{
+			name:           "$total returns running total",
+			expr:           &expr.TotalExpression{},
+			ctx:            iteration,
+			wantCollection: system.Collection{system.Integer(10)},
+		},
+		{
+			name:           "$total returns empty outside aggregate",
+			expr:           &expr.TotalExpression{},
+			ctx:            &expr.Context{Iteration: &expr.Iteration{This: system.String("item")}},
+			wantCollection: system.Collection{},
+		},
+		{
+			name:           "iteration state is preserved by Clone",
+			expr:           &expr.IterationIndexExpression{},
+			ctx:            iteration.Clone(),
+			wantCollection: system.Collection{system.Integer(2)},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.expr.Evaluate(tc.ctx, input)
+
+			if err != nil {
+				t.Fatalf("Evaluate raised unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.wantCollection, got, protocmp.Transform()); diff != "" {
+				t.Errorf("Evaluate returned unexpected diff: (-want, +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestEquivalenceExpression_ReturnsResult(t *testing.T) {
+	testCases := []struct {
+		name            string
+		equivalenceExpr *expr.EquivalenceExpression
+		wantCollection  system.Collection
+	}{
+		{
+			name:            "both collections empty",
+			equivalenceExpr: &expr.EquivalenceExpression{exprtest.Return(), exprtest.Return(), false},
+			wantCollection:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:            "one collection empty",
+			equivalenceExpr: &expr.EquivalenceExpression{exprtest.Return(), exprtest.Return(system.String("a")), false},
+			wantCollection:  system.Collection{system.Boolean(false)},
+		},
+		{
+			name:            "equivalent strings",
+			equivalenceExpr: &expr.EquivalenceExpression{exprtest.Return(fhir.String("Hello  World")), exprtest.Return(system.String("hello world")), false},
+			wantCollection:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:            "dates with different precision",
+			equivalenceExpr: &expr.EquivalenceExpression{exprtest.Return(system.MustParseDate("2012")), exprtest.Return(system.MustParseDate("2012-01")), false},
+			wantCollection:  system.Collection{system.Boolean(false)},
+		},
+		{
+			name:            "not equivalent",
+			equivalenceExpr: &expr.EquivalenceExpression{exprtest.Return(system.Integer(1)), exprtest.Return(system.Integer(2)), true},
+			wantCollection:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:            "not equivalent with empty collections",
+			equivalenceExpr: &expr.EquivalenceExpression{exprtest.Return(), exprtest.Return(), true},
+			wantCollection:  system.Collection{system.Boolean(false)},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.equivalenceExpr.Evaluate(&expr.Context{}, system.Collection{})
+
+			if err != nil {
+				t.Fatalf("EquivalenceExpression.Evaluate raised unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.wantCollection, got, protocmp.Transform()); diff != "" {
+				t.Errorf("EquivalenceExpression.Evaluate returned unexpected diff: (-want, +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestEquivalenceExpression_RaisesError(t *testing.T) {
+	testCases := []struct {
+		name            string
+		equivalenceExpr *expr.EquivalenceExpression
+	}{
+		{
+			name:            "subexpression one errors",
+			equivalenceExpr: &expr.EquivalenceExpression{exprtest.Error(errMock), exprtest.Return(system.Boolean(true)), false},
+		},
+		{
+			name:            "subexpression two errors",
+			equivalenceExpr: &expr.EquivalenceExpression{exprtest.Return(system.Boolean(true)), exprtest.Error(errMock), false},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.equivalenceExpr.Evaluate(&expr.Context{}, system.Collection{})
+
+			if err == nil {
+				t.Fatalf("EquivalenceExpression.Evaluate didn't propagate error when it should have")
+			}
+		})
+	}
+}
+
+func TestMembershipExpression_ReturnsResult(t *testing.T) {
+	testCases := []struct {
+		name           string
+		membershipExpr *expr.MembershipExpression
+		wantCollection system.Collection
+	}{
+		{
+			name:           "item is in collection",
+			membershipExpr: &expr.MembershipExpression{exprtest.Return(system.String("a")), exprtest.Return(system.String("b"), system.String("a")), expr.In},
+			wantCollection: system.Collection{system.Boolean(true)},
+		},
+		{
+			name:           "item is not in collection",
+			membershipExpr: &expr.MembershipExpression{exprtest.Return(system.String("c")), exprtest.Return(system.String("b"), system.String("a")), expr.In},
+			wantCollection: system.Collection{system.Boolean(false)},
+		},
+		{
+			name:           "collection contains item",
+			membershipExpr: &expr.MembershipExpression{exprtest.Return(fhir.String("b"), fhir.String("a")), exprtest.Return(system.String("a")), expr.Contains},
+			wantCollection: system.Collection{system.Boolean(true)},
+		},
+		{
+			name:           "collection contains proto item",
+			membershipExpr: &expr.MembershipExpression{exprtest.Return(fhir.Coding("sys", "code")), exprtest.Return(fhir.Coding("sys", "code")), expr.Contains},
+			wantCollection: system.Collection{system.Boolean(true)},
+		},
+		{
+			name:           "integer is implicitly converted to decimal",
+			membershipExpr: &expr.MembershipExpression{exprtest.Return(system.Integer(1)), exprtest.Return(system.MustParseDecimal("1.0")), expr.In},
+			wantCollection: system.Collection{system.Boolean(true)},
+		},
+		{
+			name:           "empty item returns empty",
+			membershipExpr: &expr.MembershipExpression{exprtest.Return(), exprtest.Return(system.String("a")), expr.In},
+			wantCollection: system.Collection{},
+		},
+		{
+			name:           "empty collection returns false",
+			membershipExpr: &expr.MembershipExpression{exprtest.Return(), exprtest.Return(system.String("a")), expr.Contains},
+			wantCollection: system.Collection{system.Boolean(false)},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.membershipExpr.Evaluate(&expr.Context{}, system.Collection{})
+
+			if err != nil {
+				t.Fatalf("MembershipExpression.Evaluate raised unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.wantCollection, got, protocmp.Transform()); diff != "" {
+				t.Errorf("MembershipExpression.Evaluate returned unexpected diff: (-want, +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMembershipExpression_RaisesError(t *testing.T) {
+	testCases := []struct {
+		name           string
+		membershipExpr *expr.MembershipExpression
+	}{
+		{
+			name:           "subexpression one errors",
+			membershipExpr: &expr.MembershipExpression{exprtest.Error(errMock), exprtest.Return(system.Boolean(true)), expr.In},
+		},
+		{
+			name:           "subexpression two errors",
+			membershipExpr: &expr.MembershipExpression{exprtest.Return(system.Boolean(true)), exprtest.Error(errMock), expr.In},
+		},
+		{
+			name:           "item is not a singleton",
+			membershipExpr: &expr.MembershipExpression{exprtest.Return(system.Integer(1), system.Integer(2)), exprtest.Return(system.Integer(1)), expr.In},
+		},
+		{
+			name:           "invalid operator",
+			membershipExpr: &expr.MembershipExpression{exprtest.Return(system.Integer(1)), exprtest.Return(system.Integer(1)), expr.Equals},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.membershipExpr.Evaluate(&expr.Context{}, system.Collection{})
+
+			if err == nil {
+				t.Fatalf("MembershipExpression.Evaluate didn't return error when it should have")
+			}
+		})
+	}
+}
+
+func TestUnionExpression_ReturnsResult(t *testing.T) {
+	testCases := []struct {
+		name           string
+		unionExpr      *expr.UnionExpression
+		wantCollection system.Collection
+	}{
+		{
+			name:           "both collections empty",
+			unionExpr:      &expr.UnionExpression{exprtest.Return(), exprtest.Return()},
+			wantCollection: system.Collection{},
+		},
+		{
+			name:           "one empty collection",
+			unionExpr:      &expr.UnionExpression{exprtest.Return(), exprtest.Return(system.Integer(1))},
+			wantCollection: system.Collection{system.Integer(1)},
+		},
+		{
+			name:           "eliminates duplicates",
+			unionExpr:      &expr.UnionExpression{exprtest.Return(system.Integer(1), system.Integer(1)), exprtest.Return(system.Integer(2), system.Integer(1))},
+			wantCollection: system.Collection{system.Integer(1), system.Integer(2)},
+		},
+		{
+			name:           "merges proto and system types",
+			unionExpr:      &expr.UnionExpression{exprtest.Return(fhir.String("a")), exprtest.Return(system.String("a"), system.String("b"))},
+			wantCollection: system.Collection{fhir.String("a"), system.String("b")},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.unionExpr.Evaluate(&expr.Context{}, system.Collection{})
+
+			if err != nil {
+				t.Fatalf("UnionExpression.Evaluate raised unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.wantCollection, got, protocmp.Transform()); diff != "" {
+				t.Errorf("UnionExpression.Evaluate returned unexpected diff: (-want, +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestUnionExpression_RaisesError(t *testing.T) {
+	testCases := []struct {
+		name      string
+		unionExpr *expr.UnionExpression
+	}{
+		{
+			name:      "subexpression one errors",
+			unionExpr: &expr.UnionExpression{exprtest.Error(errMock), exprtest.Return(system.Boolean(true))},
+		},
+		{
+			name:      "subexpression two errors",
+			unionExpr: &expr.UnionExpression{exprtest.Return(system.Boolean(true)), exprtest.Error(errMock)},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.unionExpr.Evaluate(&expr.Context{}, system.Collection{})
+
+			if err == nil {
+				t.Fatalf("UnionExpression.Evaluate didn't propagate error when it should have")
+			}
+		})
+	}
+}
+
 func TestIsExpression_ReturnsResult(t *testing.T) {
 	testCases := []struct {
 		name           string
@@ -1247,6 +1558,69 @@ func TestArithmeticExpression_ReturnsResult(t *testing.T) {
 			},
 			want: system.Collection{system.Decimal(decimal.NewFromFloat(2.5))},
 		},
+		{
+			name: "multiplies quantities",
+			expr: &expr.ArithmeticExpression{
+				Left:  exprtest.Return(system.MustParseQuantity("2", "kg")),
+				Right: exprtest.Return(system.MustParseQuantity("3", "m")),
+				Op:    expr.EvaluateMul,
+			},
+			want: system.Collection{system.MustParseQuantity("6", "kg.m")},
+		},
+		{
+			name: "multiplies quantity by integer",
+			expr: &expr.ArithmeticExpression{
+				Left:  exprtest.Return(system.MustParseQuantity("2.5", "lbs")),
+				Right: exprtest.Return(system.Integer(2)),
+				Op:    expr.EvaluateMul,
+			},
+			want: system.Collection{system.MustParseQuantity("5", "lbs")},
+		},
+		{
+			name: "divides quantities",
+			expr: &expr.ArithmeticExpression{
+				Left:  exprtest.Return(system.MustParseQuantity("10", "km")),
+				Right: exprtest.Return(system.MustParseQuantity("4", "h")),
+				Op:    expr.EvaluateDiv,
+			},
+			want: system.Collection{system.MustParseQuantity("2.5", "km/h")},
+		},
+		{
+			name: "divides decimal by quantity",
+			expr: &expr.ArithmeticExpression{
+				Left:  exprtest.Return(system.MustParseDecimal("1")),
+				Right: exprtest.Return(system.MustParseQuantity("4", "min")),
+				Op:    expr.EvaluateDiv,
+			},
+			want: system.Collection{system.MustParseQuantity("0.25", "/min")},
+		},
+		{
+			name: "returns empty on quantity division by zero",
+			expr: &expr.ArithmeticExpression{
+				Left:  exprtest.Return(system.MustParseQuantity("1", "kg")),
+				Right: exprtest.Return(system.Integer(0)),
+				Op:    expr.EvaluateDiv,
+			},
+			want: system.Collection{},
+		},
+		{
+			name: "returns error on quantities without UCUM units",
+			expr: &expr.ArithmeticExpression{
+				Left:  exprtest.Return(system.MustParseQuantity("1", "lbs")),
+				Right: exprtest.Return(system.MustParseQuantity("1", "lbs")),
+				Op:    expr.EvaluateMul,
+			},
+			wantErr: system.ErrMismatchedUnit,
+		},
+		{
+			name: "subtracts integer from quantity",
+			expr: &expr.ArithmeticExpression{
+				Left:  exprtest.Return(system.MustParseQuantity("8", "kg")),
+				Right: exprtest.Return(system.Integer(3)),
+				Op:    expr.EvaluateSub,
+			},
+			want: system.Collection{system.MustParseQuantity("5", "kg")},
+		},
 		{
 			name: "performs floor division",
 			expr: &expr.ArithmeticExpression{
@@ -1463,3 +1837,148 @@ func TestNegationExpression(t *testing.T) {
 		})
 	}
 }
+
+func TestVariableExpression(t *testing.T) {
+	testCases := []struct {
+		name    string
+		expr    *expr.VariableExpression
+		context *expr.Context
+		want    system.Collection
+		wantErr error
+	}{
+		{
+			name:    "returns variable",
+			expr:    &expr.VariableExpression{Name: "value"},
+			context: contextWithVariable("value", system.Collection{system.Integer(1), system.Integer(2)}),
+			want:    system.Collection{system.Integer(1), system.Integer(2)},
+		},
+		{
+			name:    "returns variable of parent scope",
+			expr:    &expr.VariableExpression{Name: "value"},
+			context: contextWithVariable("value", system.Collection{system.Integer(1)}).Clone(),
+			want:    system.Collection{system.Integer(1)},
+		},
+		{
+			name: "ignores external constants",
+			expr: &expr.VariableExpression{Name: "value"},
+			context: &expr.Context{
+				ExternalConstants: map[string]any{"value": system.String("some string")},
+			},
+			wantErr: expr.ErrVariableNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.expr.Evaluate(tc.context, system.Collection{})
+
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("VariableExpression.Evaluate returned unexpected error: got %v, want %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("VariableExpression.Evaluate returned unexpected diff: (-want, +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDefineVariableExpression(t *testing.T) {
+	input := system.Collection{system.Integer(1)}
+	ctx := expr.InitializeContext(input)
+	define := &expr.DefineVariableExpression{Name: "value", Value: exprtest.Return(system.String("a"))}
+
+	got, err := define.Evaluate(ctx, input)
+	if err != nil {
+		t.Fatalf("DefineVariableExpression.Evaluate returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(input, got); diff != "" {
+		t.Errorf("DefineVariableExpression.Evaluate returned unexpected diff: (-want, +got)\n%s", diff)
+	}
+	if value, _ := ctx.Variables.Lookup("value"); !cmp.Equal(value, system.Collection{system.String("a")}) {
+		t.Errorf("DefineVariableExpression.Evaluate defined %v, want %v", value, system.String("a"))
+	}
+}
+
+func TestDefineVariableExpression_RaisesError(t *testing.T) {
+	errMock := errors.New("some error")
+	testCases := []struct {
+		name    string
+		context *expr.Context
+		expr    *expr.DefineVariableExpression
+		wantErr error
+	}{
+		{
+			name:    "value raises error",
+			context: expr.InitializeContext(system.Collection{}),
+			expr:    &expr.DefineVariableExpression{Name: "value", Value: exprtest.Error(errMock)},
+			wantErr: errMock,
+		},
+		{
+			name:    "context without variables",
+			context: &expr.Context{},
+			expr:    &expr.DefineVariableExpression{Name: "value", Value: exprtest.Return()},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.expr.Evaluate(tc.context, system.Collection{})
+
+			if err == nil || (tc.wantErr != nil && !errors.Is(err, tc.wantErr)) {
+				t.Errorf("DefineVariableExpression.Evaluate returned unexpected error: got %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestContextClone_ScopesVariables(t *testing.T) {
+	parent := contextWithVariable("outer", system.Collection{system.Integer(1)})
+	child := parent.Clone()
+	child.Variables.Define("inner", system.Collection{system.Integer(2)})
+
+	if _, ok := child.Variables.Lookup("outer"); !ok {
+		t.Errorf("Clone() scope doesn't see variable of parent scope")
+	}
+	if _, ok := parent.Variables.Lookup("inner"); ok {
+		t.Errorf("Clone() scope leaked variable to parent scope")
+	}
+	if _, ok := parent.Clone().Variables.Lookup("inner"); ok {
+		t.Errorf("Clone() scope leaked variable to sibling scope")
+	}
+}
+
+// contextWithVariable returns a context that holds the variable name.
+func contextWithVariable(name string, value system.Collection) *expr.Context {
+	ctx := expr.InitializeContext(system.Collection{})
+	ctx.Variables.Define(name, value)
+	return ctx
+}
+
+func TestFieldExpression_RecordsContainers(t *testing.T) {
+	patient := &ppb.Patient{
+		Name: []*dtpb.HumanName{{Family: fhir.String("Chu")}},
+	}
+	ctx := expr.InitializeContext(system.Collection{patient})
+	ctx.Containers = expr.NewContainers()
+	path := &expr.ExpressionSequence{
+		Expressions: []expr.Expression{
+			&expr.FieldExpression{FieldName: "name"},
+			&expr.FieldExpression{FieldName: "family"},
+		},
+	}
+
+	got, err := path.Evaluate(ctx, system.Collection{patient})
+	if err != nil {
+		t.Fatalf("Evaluate returned unexpected error: %v", err)
+	}
+
+	if len(got) != 1 {
+		t.Fatalf("Evaluate returned %v items, want 1", len(got))
+	}
+	if holder, ok := ctx.Containers.Holder(got[0]); !ok || holder != patient {
+		t.Errorf("Containers.Holder() = %v, %v, want %v, true", holder, ok, patient)
+	}
+	if _, ok := ctx.Containers.Holder(fhir.String("Chu")); ok {
+		t.Errorf("Containers.Holder() found the holder of an element that wasn't navigated to")
+	}
+}
@@ -0,0 +1,37 @@
+package impl
+
+import (
+	"fmt"
+
+	"github.com/fhir-fli/fhirpath-go/fhirpath/internal/expr"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/system"
+)
+
+// Aggregate evaluates the aggregator expression args[0] on each input item,
+// with $total bound to the result of the previous evaluation. The value of
+// $total starts out as the result of the optional init expression args[1], or
+// empty if it isn't provided. Returns the value of $total after the last item.
+// FHIRPath docs here: https://hl7.org/fhirpath/N1/#aggregateaggregator-expression-init-value-value
+func Aggregate(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if len(args) != 1 && len(args) != 2 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 1 or 2", ErrWrongArity, len(args))
+	}
+	total := system.Collection{}
+	if len(args) == 2 {
+		init, err := args[1].Evaluate(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		total = init
+	}
+	aggregator := args[0]
+	for i, item := range input {
+		iteration := expr.Iteration{This: item, Index: i, Total: total}
+		result, err := aggregator.Evaluate(ctx.WithIteration(iteration), system.Collection{item})
+		if err != nil {
+			return nil, err
+		}
+		total = result
+	}
+	return total, nil
+}
@@ -0,0 +1,88 @@
+package impl_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/fhir-fli/fhirpath-go/fhirpath/internal/expr"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/internal/expr/exprtest"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/internal/funcs/impl"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/system"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+func TestAggregate(t *testing.T) {
+	sum := &expr.ArithmeticExpression{
+		Left:  &expr.ThisExpression{},
+		Right: &expr.TotalExpression{},
+		Op:    expr.EvaluateAdd,
+	}
+	testCases := []struct {
+		name    string
+		input   system.Collection
+		args    []expr.Expression
+		want    system.Collection
+		wantErr bool
+	}{
+		{
+			name:  "returns init if input is empty",
+			input: system.Collection{},
+			args:  []expr.Expression{sum, exprtest.Return(system.Integer(0))},
+			want:  system.Collection{system.Integer(0)},
+		},
+		{
+			name:  "returns empty if input is empty without init",
+			input: system.Collection{},
+			args:  []expr.Expression{sum},
+			want:  system.Collection{},
+		},
+		{
+			name:  "accumulates total",
+			input: system.Collection{system.Integer(1), system.Integer(2), system.Integer(3)},
+			args:  []expr.Expression{sum, exprtest.Return(system.Integer(0))},
+			want:  system.Collection{system.Integer(6)},
+		},
+		{
+			name:  "total starts empty without init",
+			input: system.Collection{system.Integer(1), system.Integer(2), system.Integer(3)},
+			args:  []expr.Expression{sum},
+			want:  system.Collection{},
+		},
+		{
+			name:  "binds $index for each item",
+			input: system.Collection{system.String("a"), system.String("b"), system.String("c")},
+			args:  []expr.Expression{&expr.IterationIndexExpression{}},
+			want:  system.Collection{system.Integer(2)},
+		},
+		{
+			name:    "raises error with wrong arity",
+			input:   system.Collection{system.Integer(1)},
+			wantErr: true,
+		},
+		{
+			name:    "propagates aggregator error",
+			input:   system.Collection{system.Integer(1)},
+			args:    []expr.Expression{exprtest.Error(errors.New("some error"))},
+			wantErr: true,
+		},
+		{
+			name:    "propagates init error",
+			input:   system.Collection{system.Integer(1)},
+			args:    []expr.Expression{sum, exprtest.Error(errors.New("some error"))},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := impl.Aggregate(&expr.Context{}, tc.input, tc.args...)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Aggregate() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("Aggregate() returned unexpected diff (-want, +got)\n%s", diff)
+			}
+		})
+	}
+}
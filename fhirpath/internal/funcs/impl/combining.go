@@ -0,0 +1,40 @@
+package impl
+
+import (
+	"fmt"
+
+	"github.com/fhir-fli/fhirpath-go/fhirpath/internal/expr"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/system"
+)
+
+// Union merges the input and other collections into a single collection,
+// eliminating any duplicate values as determined by the equals (=) operator.
+// This is synonymous with the union operator '|'.
+// FHIRPath docs here: https://hl7.org/fhirpath/N1/#unionother-collection
+func Union(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 1", ErrWrongArity, len(args))
+	}
+	other, err := args[0].Evaluate(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return input.Union(other), nil
+}
+
+// Combine merges the input and other collections into a single collection
+// without eliminating duplicate values. Order is preserved, with the input
+// items first.
+// FHIRPath docs here: https://hl7.org/fhirpath/N1/#combineother-collection-collection
+func Combine(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 1", ErrWrongArity, len(args))
+	}
+	other, err := args[0].Evaluate(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	result := system.Collection{}
+	result = append(result, input...)
+	return append(result, other...), nil
+}
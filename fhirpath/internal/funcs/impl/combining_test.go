@@ -0,0 +1,124 @@
+package impl_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/fhir-fli/fhirpath-go/fhir"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/internal/expr"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/internal/expr/exprtest"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/internal/funcs/impl"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/system"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+func TestUnion(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   system.Collection
+		args    []expr.Expression
+		want    system.Collection
+		wantErr bool
+	}{
+		{
+			name:  "returns empty when both collections are empty",
+			input: system.Collection{},
+			args:  []expr.Expression{exprtest.Return()},
+			want:  system.Collection{},
+		},
+		{
+			name:  "returns other collection when input is empty",
+			input: system.Collection{},
+			args:  []expr.Expression{exprtest.Return(system.Integer(1))},
+			want:  system.Collection{system.Integer(1)},
+		},
+		{
+			name:  "eliminates duplicates",
+			input: system.Collection{system.Integer(1), system.Integer(2), system.Integer(2)},
+			args:  []expr.Expression{exprtest.Return(system.Integer(2), system.Integer(3))},
+			want:  system.Collection{system.Integer(1), system.Integer(2), system.Integer(3)},
+		},
+		{
+			name:  "compares mixed proto and system types",
+			input: system.Collection{fhir.String("a"), fhir.Integer(1)},
+			args:  []expr.Expression{exprtest.Return(system.String("a"), system.Integer(1), fhir.Coding("system", "code"))},
+			want:  system.Collection{fhir.String("a"), fhir.Integer(1), fhir.Coding("system", "code")},
+		},
+		{
+			name:    "raises error with wrong arity",
+			input:   system.Collection{},
+			wantErr: true,
+		},
+		{
+			name:    "propagates argument error",
+			input:   system.Collection{},
+			args:    []expr.Expression{exprtest.Error(errors.New("some error"))},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := impl.Union(&expr.Context{}, tc.input, tc.args...)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Union() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("Union() returned unexpected diff (-want, +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCombine(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   system.Collection
+		args    []expr.Expression
+		want    system.Collection
+		wantErr bool
+	}{
+		{
+			name:  "returns empty when both collections are empty",
+			input: system.Collection{},
+			args:  []expr.Expression{exprtest.Return()},
+			want:  system.Collection{},
+		},
+		{
+			name:  "keeps duplicates",
+			input: system.Collection{system.Integer(1), system.Integer(2)},
+			args:  []expr.Expression{exprtest.Return(system.Integer(2), system.Integer(1))},
+			want:  system.Collection{system.Integer(1), system.Integer(2), system.Integer(2), system.Integer(1)},
+		},
+		{
+			name:  "merges mixed proto and system types",
+			input: system.Collection{fhir.String("a")},
+			args:  []expr.Expression{exprtest.Return(system.String("a"))},
+			want:  system.Collection{fhir.String("a"), system.String("a")},
+		},
+		{
+			name:    "raises error with wrong arity",
+			input:   system.Collection{},
+			wantErr: true,
+		},
+		{
+			name:    "propagates argument error",
+			input:   system.Collection{},
+			args:    []expr.Expression{exprtest.Error(errors.New("some error"))},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := impl.Combine(&expr.Context{}, tc.input, tc.args...)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Combine() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("Combine() returned unexpected diff (-want, +got)\n%s", diff)
+			}
+		})
+	}
+}
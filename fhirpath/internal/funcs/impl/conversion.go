@@ -3,14 +3,12 @@ package impl
 import (
 	"errors"
 	"fmt"
-	"math"
 	"regexp"
-	"strconv"
 	"strings"
-	"time"
 
 	"github.com/fhir-fli/fhirpath-go/fhirpath/internal/expr"
 	"github.com/fhir-fli/fhirpath-go/fhirpath/system"
+	"github.com/fhir-fli/fhirpath-go/internal/units"
 )
 
 // DefaultQuantityUnit is defined by the following FHIRPath rules:
@@ -25,6 +23,35 @@ const fhirQuantityRegexp = `^(?P<value>(\+|-)?\d+(\.\d+)?)\s*('(?P<unit>[^']+)'|
 
 var regex = regexp.MustCompile(fhirQuantityRegexp)
 
+// Iif evaluates the criterion expression args[0], and returns the result of the
+// true-result expression args[1] if the criterion is true, or the result of the
+// optional otherwise-result expression args[2] if it is not. An empty criterion
+// is treated as false, and a criterion with multiple items raises an error.
+// Only the expression for the branch that is taken gets evaluated, so the
+// other branch may contain expressions that would fail for this input.
+// If the criterion is not true and no otherwise-result is given, the result is empty.
+// FHIRPath docs here: https://hl7.org/fhirpath/N1/#iifcriterion-expression-true-result-collection-otherwise-result-collection-collection
+func Iif(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if len(args) != 2 && len(args) != 3 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 2 or 3", ErrWrongArity, len(args))
+	}
+	criterion, err := args[0].Evaluate(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	pass, err := criterion.ToSingletonBoolean()
+	if err != nil {
+		return nil, fmt.Errorf("evaluating iif criterion as boolean resulted in an error: %w", err)
+	}
+	if len(pass) == 1 && bool(pass[0]) {
+		return args[1].Evaluate(ctx, input)
+	}
+	if len(args) == 3 {
+		return args[2].Evaluate(ctx, input)
+	}
+	return system.Collection{}, nil
+}
+
 // ConvertsToBoolean checks if the input can be converted to a Boolean
 // FHIRPath docs here: https://hl7.org/fhirpath/N1/#convertstoboolean-boolean
 func ConvertsToBoolean(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
@@ -401,7 +428,10 @@ func ToInteger(ctx *expr.Context, input system.Collection, args ...expr.Expressi
 	return system.Collection{}, nil
 }
 
-// ToQuantity converts the input to a Quantity
+// ToQuantity converts the input to a Quantity. Strings are parsed as a number
+// followed by an optional quoted UCUM unit or calendar duration keyword. If a
+// unit is given, the result is converted to it, and is empty if the units
+// aren't commensurable.
 // FHIRPath docs here: https://hl7.org/fhirpath/N1/#toquantityunit-string-quantity
 func ToQuantity(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
 	// Input validation
@@ -425,6 +455,7 @@ func ToQuantity(ctx *expr.Context, input system.Collection, args ...expr.Express
 		if err != nil {
 			return nil, err
 		}
+		argStr = strings.TrimSuffix(strings.TrimPrefix(argStr, "'"), "'")
 	}
 	// Input reading
 	value, err := system.From(input[0])
@@ -432,65 +463,68 @@ func ToQuantity(ctx *expr.Context, input system.Collection, args ...expr.Express
 		return nil, err
 	}
 	// Input conversion
+	var result system.Quantity
 	switch value := value.(type) {
 	case system.Integer:
-		matches := regex.FindStringSubmatch(fmt.Sprintf("%v %v", value, argStr))
-		if matches == nil {
-			return system.Collection{}, nil
-		}
-		if argStr != "" {
-			unit := regex.SubexpIndex("unit")
-			t := regex.SubexpIndex("time")
-			if matches[unit] != "" {
-				result := system.MustParseQuantity(fmt.Sprintf("%v", value), matches[unit])
-				return system.Collection{result}, nil
-			}
-			if matches[t] != "" {
-				result := system.MustParseQuantity(fmt.Sprintf("%v", value), matches[t])
-				return system.Collection{result}, nil
-			}
-		}
-		result := system.MustParseQuantity(fmt.Sprintf("%v", value), DefaultQuantityUnit)
-		return system.Collection{result}, nil
+		result = system.MustParseQuantity(fmt.Sprintf("%v", value), DefaultQuantityUnit)
 	case system.Decimal:
-		str := value.String()
-		result, err := system.ParseQuantity(string(str), DefaultQuantityUnit)
-		if err != nil {
-			return nil, err
-		}
-		return system.Collection{result}, nil
+		result = system.MustParseQuantity(value.String(), DefaultQuantityUnit)
 	case system.Quantity:
-		return system.Collection{value}, nil
+		result = value
 	case system.String:
-		matches := regex.FindStringSubmatch(string(value))
-		if matches == nil {
+		quantity, ok := parseQuantityString(string(value))
+		if !ok {
 			return system.Collection{}, nil
 		}
-		if argStr != "" {
-			if !isValidUnitConversion(argStr) {
-				return nil, fmt.Errorf("invalid unit of time: %v", input)
-			}
-			conversion, err := convertDuration(string(value), argStr)
-			if err != nil {
-				return nil, err
-			}
-			res := strings.SplitN(conversion, " ", 2)
-			result := system.MustParseQuantity(res[0], res[1])
-			return system.Collection{result}, nil
-		}
-		res := strings.SplitN(string(value), " ", 2)
-		unit := strings.Trim(res[1], "'")
-		result := system.MustParseQuantity(res[0], unit)
-		return system.Collection{result}, nil
+		result = quantity
 	case system.Boolean:
 		if value {
-			result := system.MustParseQuantity("1.0", DefaultQuantityUnit)
-			return system.Collection{result}, nil
+			result = system.MustParseQuantity("1.0", DefaultQuantityUnit)
+		} else {
+			result = system.MustParseQuantity("0.0", DefaultQuantityUnit)
 		}
-		result := system.MustParseQuantity("0.0", DefaultQuantityUnit)
-		return system.Collection{result}, nil
+	default:
+		return system.Collection{}, nil
 	}
-	return system.Collection{}, nil
+	if argStr != "" {
+		converted, ok := result.ConvertTo(argStr)
+		if !ok {
+			return system.Collection{}, nil
+		}
+		result = converted
+	}
+	return system.Collection{result}, nil
+}
+
+// parseQuantityString parses a string in the FHIRPath Quantity format, such as
+// "10 'mg'" or "4 days". Returns false if the string doesn't match the format,
+// its quoted unit isn't a UCUM unit, or its unquoted unit isn't a calendar
+// duration keyword.
+func parseQuantityString(str string) (system.Quantity, bool) {
+	matches := regex.FindStringSubmatch(str)
+	if matches == nil {
+		return system.Quantity{}, false
+	}
+	unit := matches[regex.SubexpIndex("unit")]
+	if unit != "" {
+		if _, err := units.ParseUCUM(unit); err != nil {
+			return system.Quantity{}, false
+		}
+	}
+	if keyword := matches[regex.SubexpIndex("time")]; keyword != "" {
+		if !system.IsDurationKeyword(keyword) {
+			return system.Quantity{}, false
+		}
+		unit = keyword
+	}
+	if unit == "" {
+		unit = DefaultQuantityUnit
+	}
+	quantity, err := system.ParseQuantity(matches[regex.SubexpIndex("value")], unit)
+	if err != nil {
+		return system.Quantity{}, false
+	}
+	return quantity, true
 }
 
 // ToString converts the input to a String
@@ -569,78 +603,3 @@ func ToTime(ctx *expr.Context, input system.Collection, args ...expr.Expression)
 	}
 	return system.Collection{}, nil
 }
-
-func isValidUnitConversion(outputFormat string) bool {
-	validFormats := map[string]bool{
-		"years":   true,
-		"months":  true,
-		"days":    true,
-		"hours":   true,
-		"minutes": true,
-		"seconds": true,
-	}
-
-	return validFormats[outputFormat]
-}
-
-func convertDuration(input string, outputFormat string) (string, error) {
-	duration, err := parseHumanDuration(input)
-	if err != nil {
-		return "", err
-	}
-
-	var convertedValue float64
-	switch outputFormat {
-	case "years":
-		convertedValue = duration.Hours() / (24 * 365)
-	case "months":
-		convertedValue = duration.Hours() / (24 * 30)
-	case "days":
-		convertedValue = duration.Hours() / 24
-	case "hours":
-		convertedValue = duration.Hours()
-	case "minutes":
-		convertedValue = duration.Minutes()
-	case "seconds":
-		convertedValue = duration.Seconds()
-	}
-
-	if outputFormat == "years" {
-		convertedValue = math.Ceil(convertedValue / 12.0)
-	}
-
-	return fmt.Sprintf("%.0f %s", convertedValue, outputFormat), nil
-}
-
-func parseHumanDuration(input string) (time.Duration, error) {
-	re := regexp.MustCompile(`(\d+)\s*(\w+)`)
-	matches := re.FindAllStringSubmatch(input, -1)
-	totalSeconds := int64(0)
-
-	for _, match := range matches {
-		value, err := strconv.ParseInt(match[1], 10, 64)
-		if err != nil {
-			return 0, err
-		}
-
-		unit := strings.ToLower(match[2])
-		switch unit {
-		case "second", "seconds":
-			totalSeconds += value
-		case "minute", "minutes":
-			totalSeconds += value * 60
-		case "hour", "hours":
-			totalSeconds += value * 3600
-		case "day", "days":
-			totalSeconds += value * 86400
-		case "month", "months":
-			totalSeconds += value * 30 * 86400 // Assuming one month is 30 days
-		case "year", "years":
-			totalSeconds += value * 365 * 86400 // Assuming one year is 365 days
-		default:
-			return 0, fmt.Errorf("invalid unit: %s", unit)
-		}
-	}
-
-	return time.Duration(totalSeconds) * time.Second, nil
-}
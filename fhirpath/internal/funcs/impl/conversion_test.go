@@ -1,6 +1,7 @@
 package impl_test
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/fhir-fli/fhirpath-go/fhir"
@@ -14,6 +15,90 @@ import (
 	"github.com/google/go-cmp/cmp"
 )
 
+func TestIif(t *testing.T) {
+	errMock := errors.New("some error")
+	testCases := []struct {
+		name    string
+		input   system.Collection
+		args    []expr.Expression
+		want    system.Collection
+		wantErr bool
+	}{
+		{
+			name:  "returns true-result when criterion is true",
+			input: system.Collection{},
+			args:  []expr.Expression{exprtest.Return(system.Boolean(true)), exprtest.Return(system.String("yes")), exprtest.Return(system.String("no"))},
+			want:  system.Collection{system.String("yes")},
+		},
+		{
+			name:  "returns otherwise-result when criterion is false",
+			input: system.Collection{},
+			args:  []expr.Expression{exprtest.Return(fhir.Boolean(false)), exprtest.Return(system.String("yes")), exprtest.Return(system.String("no"))},
+			want:  system.Collection{system.String("no")},
+		},
+		{
+			name:  "treats empty criterion as false",
+			input: system.Collection{},
+			args:  []expr.Expression{exprtest.Return(), exprtest.Return(system.String("yes")), exprtest.Return(system.String("no"))},
+			want:  system.Collection{system.String("no")},
+		},
+		{
+			name:  "returns empty when criterion is false without otherwise-result",
+			input: system.Collection{},
+			args:  []expr.Expression{exprtest.Return(system.Boolean(false)), exprtest.Return(system.String("yes"))},
+			want:  system.Collection{},
+		},
+		{
+			name:  "doesn't evaluate otherwise-result when criterion is true",
+			input: system.Collection{},
+			args:  []expr.Expression{exprtest.Return(system.Boolean(true)), exprtest.Return(system.String("yes")), exprtest.Error(errMock)},
+			want:  system.Collection{system.String("yes")},
+		},
+		{
+			name:  "doesn't evaluate true-result when criterion is false",
+			input: system.Collection{},
+			args:  []expr.Expression{exprtest.Return(system.Boolean(false)), exprtest.Error(errMock), exprtest.Return(system.String("no"))},
+			want:  system.Collection{system.String("no")},
+		},
+		{
+			name:    "raises error when criterion has multiple items",
+			input:   system.Collection{},
+			args:    []expr.Expression{exprtest.Return(system.Boolean(true), system.Boolean(true)), exprtest.Return(system.String("yes"))},
+			wantErr: true,
+		},
+		{
+			name:    "propagates criterion error",
+			input:   system.Collection{},
+			args:    []expr.Expression{exprtest.Error(errMock), exprtest.Return(system.String("yes"))},
+			wantErr: true,
+		},
+		{
+			name:    "propagates error from branch that is taken",
+			input:   system.Collection{},
+			args:    []expr.Expression{exprtest.Return(system.Boolean(true)), exprtest.Error(errMock)},
+			wantErr: true,
+		},
+		{
+			name:    "raises error with wrong arity",
+			input:   system.Collection{},
+			args:    []expr.Expression{exprtest.Return(system.Boolean(true))},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := impl.Iif(&expr.Context{}, tc.input, tc.args...)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Iif() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("Iif() returned unexpected diff (-want, +got)\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestConvertsToBoolean(t *testing.T) {
 	testCases := []struct {
 		name    string
@@ -722,7 +807,7 @@ func TestConvertsToQuantity(t *testing.T) {
 		{
 			name:    "input is system.String '100 km'",
 			input:   system.Collection{system.String("100 km")},
-			want:    system.Collection{system.Boolean(true)},
+			want:    system.Collection{system.Boolean(false)},
 			wantErr: false,
 		},
 		{
@@ -740,7 +825,7 @@ func TestConvertsToQuantity(t *testing.T) {
 		{
 			name:    "input is system.String '100 'km per hour''",
 			input:   system.Collection{system.String("100 'km per hour'")},
-			want:    system.Collection{system.Boolean(true)},
+			want:    system.Collection{system.Boolean(false)},
 			wantErr: false,
 		},
 		{
@@ -752,7 +837,7 @@ func TestConvertsToQuantity(t *testing.T) {
 		{
 			name:    "input is system.String '100           km'",
 			input:   system.Collection{system.String("100           km")},
-			want:    system.Collection{system.Boolean(true)},
+			want:    system.Collection{system.Boolean(false)},
 			wantErr: false,
 		},
 		{
@@ -764,6 +849,12 @@ func TestConvertsToQuantity(t *testing.T) {
 		{
 			name:    "input is system.String '10 'km per hr''",
 			input:   system.Collection{system.String("10 'km per hr'")},
+			want:    system.Collection{system.Boolean(false)},
+			wantErr: false,
+		},
+		{
+			name:    "input is system.String '100 'km/h''",
+			input:   system.Collection{system.String("100 'km/h'")},
 			want:    system.Collection{system.Boolean(true)},
 			wantErr: false,
 		},
@@ -782,7 +873,7 @@ func TestConvertsToQuantity(t *testing.T) {
 			args: []expr.Expression{
 				exprtest.Return(system.String("days")),
 			},
-			want:    system.Collection{system.Boolean(true)},
+			want:    system.Collection{system.Boolean(false)},
 			wantErr: false,
 		},
 		{
@@ -824,7 +915,7 @@ func TestConvertsToQuantity(t *testing.T) {
 			args: []expr.Expression{
 				exprtest.Return(system.String("'km'")),
 			},
-			want:    system.Collection{system.Boolean(true)},
+			want:    system.Collection{system.Boolean(false)},
 			wantErr: false,
 		},
 		{
@@ -833,9 +924,33 @@ func TestConvertsToQuantity(t *testing.T) {
 			args: []expr.Expression{
 				exprtest.Return(system.String("days")),
 			},
+			want:    system.Collection{system.Boolean(false)},
+			wantErr: false,
+		},
+		{
+			name:  "input is system.String '1 'kg'' with arg 'g'",
+			input: system.Collection{system.String("1 'kg'")},
+			args: []expr.Expression{
+				exprtest.Return(system.String("g")),
+			},
 			want:    system.Collection{system.Boolean(true)},
 			wantErr: false,
 		},
+		{
+			name:  "input is system.String '1 'kg'' with arg 'm'",
+			input: system.Collection{system.String("1 'kg'")},
+			args: []expr.Expression{
+				exprtest.Return(system.String("m")),
+			},
+			want:    system.Collection{system.Boolean(false)},
+			wantErr: false,
+		},
+		{
+			name:    "input is system.String '100 decades'",
+			input:   system.Collection{system.String("100 decades")},
+			want:    system.Collection{system.Boolean(false)},
+			wantErr: false,
+		},
 		{
 			name:    "input is system.Boolean 'true'",
 			input:   system.Collection{system.Boolean(true)},
@@ -1704,13 +1819,13 @@ func TestToQuantity(t *testing.T) {
 			wantErr: true,
 		},
 		{
-			name:  "errors if args is not a valid unit of time",
+			name:  "returns an empty collection if args is not a valid unit",
 			input: system.Collection{system.String("100 years")},
 			args: []expr.Expression{
 				exprtest.Return(system.String("decades")),
 			},
-			want:    nil,
-			wantErr: true,
+			want:    system.Collection{},
+			wantErr: false,
 		},
 		{
 			name:    "returns an empty collection if input is empty",
@@ -1745,7 +1860,7 @@ func TestToQuantity(t *testing.T) {
 		{
 			name:    "input is system.String '100 km'",
 			input:   system.Collection{system.String("100 km")},
-			want:    system.Collection{system.MustParseQuantity("100", "km")},
+			want:    system.Collection{},
 			wantErr: false,
 		},
 		{
@@ -1763,7 +1878,7 @@ func TestToQuantity(t *testing.T) {
 		{
 			name:    "input is system.String '100 'km per hour''",
 			input:   system.Collection{system.String("100 'km per hour'")},
-			want:    system.Collection{system.MustParseQuantity("100", "km per hour")},
+			want:    system.Collection{},
 			wantErr: false,
 		},
 		{
@@ -1775,7 +1890,7 @@ func TestToQuantity(t *testing.T) {
 		{
 			name:    "input is system.String '100           km'",
 			input:   system.Collection{system.String("100           km")},
-			want:    system.Collection{system.MustParseQuantity("100", "          km")},
+			want:    system.Collection{},
 			wantErr: false,
 		},
 		{
@@ -1787,7 +1902,19 @@ func TestToQuantity(t *testing.T) {
 		{
 			name:    "input is system.String '10 'km per hr''",
 			input:   system.Collection{system.String("10 'km per hr'")},
-			want:    system.Collection{system.MustParseQuantity("10", "km per hr")},
+			want:    system.Collection{},
+			wantErr: false,
+		},
+		{
+			name:    "input is system.String '100 'km/h''",
+			input:   system.Collection{system.String("100 'km/h'")},
+			want:    system.Collection{system.MustParseQuantity("100", "km/h")},
+			wantErr: false,
+		},
+		{
+			name:    "input is system.String '10 'foo''",
+			input:   system.Collection{system.String("10 'foo'")},
+			want:    system.Collection{},
 			wantErr: false,
 		},
 		{
@@ -1805,7 +1932,7 @@ func TestToQuantity(t *testing.T) {
 			args: []expr.Expression{
 				exprtest.Return(system.String("days")),
 			},
-			want:    system.Collection{system.MustParseQuantity("60", "days")},
+			want:    system.Collection{},
 			wantErr: false,
 		},
 		{
@@ -1847,7 +1974,7 @@ func TestToQuantity(t *testing.T) {
 			args: []expr.Expression{
 				exprtest.Return(system.String("'km'")),
 			},
-			want:    system.Collection{system.MustParseQuantity("100", "km")},
+			want:    system.Collection{},
 			wantErr: false,
 		},
 		{
@@ -1856,7 +1983,76 @@ func TestToQuantity(t *testing.T) {
 			args: []expr.Expression{
 				exprtest.Return(system.String("days")),
 			},
-			want:    system.Collection{system.MustParseQuantity("100", "days")},
+			want:    system.Collection{},
+			wantErr: false,
+		},
+		{
+			name:    "input is system.String '10 'mg''",
+			input:   system.Collection{system.String("10 'mg'")},
+			want:    system.Collection{system.MustParseQuantity("10", "mg")},
+			wantErr: false,
+		},
+		{
+			name:    "input is system.String '-1.5 week'",
+			input:   system.Collection{system.String("-1.5 week")},
+			want:    system.Collection{system.MustParseQuantity("-1.5", "week")},
+			wantErr: false,
+		},
+		{
+			name:    "input is system.String '5'",
+			input:   system.Collection{system.String("5")},
+			want:    system.Collection{system.MustParseQuantity("5", "1")},
+			wantErr: false,
+		},
+		{
+			name:    "input is system.String '100 decades'",
+			input:   system.Collection{system.String("100 decades")},
+			want:    system.Collection{},
+			wantErr: false,
+		},
+		{
+			name:  "input is system.String '1 'kg'' with arg 'g'",
+			input: system.Collection{system.String("1 'kg'")},
+			args: []expr.Expression{
+				exprtest.Return(system.String("g")),
+			},
+			want:    system.Collection{system.MustParseQuantity("1000", "g")},
+			wantErr: false,
+		},
+		{
+			name:  "input is system.String '1 'kg'' with arg 'm'",
+			input: system.Collection{system.String("1 'kg'")},
+			args: []expr.Expression{
+				exprtest.Return(system.String("m")),
+			},
+			want:    system.Collection{},
+			wantErr: false,
+		},
+		{
+			name:  "input is system.Quantity '1 'wk'' with arg 'd'",
+			input: system.Collection{system.MustParseQuantity("1", "wk")},
+			args: []expr.Expression{
+				exprtest.Return(system.String("d")),
+			},
+			want:    system.Collection{system.MustParseQuantity("7", "d")},
+			wantErr: false,
+		},
+		{
+			name:  "input is system.Quantity '1 'wk'' with arg 'days'",
+			input: system.Collection{system.MustParseQuantity("1", "wk")},
+			args: []expr.Expression{
+				exprtest.Return(system.String("days")),
+			},
+			want:    system.Collection{system.MustParseQuantity("7", "days")},
+			wantErr: false,
+		},
+		{
+			name:  "input is system.Decimal '0.5' with arg '%'",
+			input: system.Collection{system.MustParseDecimal("0.5")},
+			args: []expr.Expression{
+				exprtest.Return(system.String("%")),
+			},
+			want:    system.Collection{system.MustParseQuantity("50", "%")},
 			wantErr: false,
 		},
 		{
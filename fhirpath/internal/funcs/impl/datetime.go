@@ -0,0 +1,293 @@
+package impl
+
+import (
+	"fmt"
+
+	"github.com/fhir-fli/fhirpath-go/fhirpath/internal/expr"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/system"
+	"github.com/fhir-fli/fhirpath-go/internal/narrow"
+	"github.com/fhir-fli/fhirpath-go/internal/units"
+)
+
+// YearOf returns the year component of the input Date or DateTime.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/#yearof--integer
+func YearOf(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	value, err := componentInput(input, args)
+	if err != nil || value == nil {
+		return system.Collection{}, err
+	}
+	switch v := value.(type) {
+	case system.Date:
+		return componentResult(v.Year())
+	case system.DateTime:
+		return componentResult(v.Year())
+	}
+	return nil, componentTypeError("yearOf", value)
+}
+
+// MonthOf returns the month component of the input Date or DateTime, or empty
+// if it is less precise than a month.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/#monthof--integer
+func MonthOf(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	value, err := componentInput(input, args)
+	if err != nil || value == nil {
+		return system.Collection{}, err
+	}
+	switch v := value.(type) {
+	case system.Date:
+		return componentResult(v.Month())
+	case system.DateTime:
+		return componentResult(v.Month())
+	}
+	return nil, componentTypeError("monthOf", value)
+}
+
+// DayOf returns the day component of the input Date or DateTime, or empty if
+// it is less precise than a day.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/#dayof--integer
+func DayOf(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	value, err := componentInput(input, args)
+	if err != nil || value == nil {
+		return system.Collection{}, err
+	}
+	switch v := value.(type) {
+	case system.Date:
+		return componentResult(v.Day())
+	case system.DateTime:
+		return componentResult(v.Day())
+	}
+	return nil, componentTypeError("dayOf", value)
+}
+
+// HourOf returns the hour component of the input DateTime or Time, or empty if
+// it is less precise than an hour.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/#hourof--integer
+func HourOf(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	value, err := componentInput(input, args)
+	if err != nil || value == nil {
+		return system.Collection{}, err
+	}
+	switch v := value.(type) {
+	case system.DateTime:
+		return componentResult(v.Hour())
+	case system.Time:
+		return componentResult(v.Hour())
+	}
+	return nil, componentTypeError("hourOf", value)
+}
+
+// MinuteOf returns the minute component of the input DateTime or Time, or
+// empty if it is less precise than a minute.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/#minuteof--integer
+func MinuteOf(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	value, err := componentInput(input, args)
+	if err != nil || value == nil {
+		return system.Collection{}, err
+	}
+	switch v := value.(type) {
+	case system.DateTime:
+		return componentResult(v.Minute())
+	case system.Time:
+		return componentResult(v.Minute())
+	}
+	return nil, componentTypeError("minuteOf", value)
+}
+
+// SecondOf returns the second component of the input DateTime or Time, or
+// empty if it is less precise than a second.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/#secondof--integer
+func SecondOf(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	value, err := componentInput(input, args)
+	if err != nil || value == nil {
+		return system.Collection{}, err
+	}
+	switch v := value.(type) {
+	case system.DateTime:
+		return componentResult(v.Second())
+	case system.Time:
+		return componentResult(v.Second())
+	}
+	return nil, componentTypeError("secondOf", value)
+}
+
+// MillisecondOf returns the millisecond component of the input DateTime or
+// Time, or empty if it is less precise than a millisecond.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/#millisecondof--integer
+func MillisecondOf(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	value, err := componentInput(input, args)
+	if err != nil || value == nil {
+		return system.Collection{}, err
+	}
+	switch v := value.(type) {
+	case system.DateTime:
+		return componentResult(v.Millisecond())
+	case system.Time:
+		return componentResult(v.Millisecond())
+	}
+	return nil, componentTypeError("millisecondOf", value)
+}
+
+// TimezoneOffsetOf returns the timezone offset of the input DateTime as a
+// Decimal number of hours, or empty if it has no timezone.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/#timezoneoffsetof--decimal
+func TimezoneOffsetOf(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	value, err := componentInput(input, args)
+	if err != nil || value == nil {
+		return system.Collection{}, err
+	}
+	if v, ok := value.(system.DateTime); ok {
+		return componentResult(v.TimezoneOffset())
+	}
+	return nil, componentTypeError("timezoneOffsetOf", value)
+}
+
+// DateOf returns the date component of the input DateTime, to the precision
+// of the input if it is less precise than a day.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/#dateof--date
+func DateOf(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	value, err := componentInput(input, args)
+	if err != nil || value == nil {
+		return system.Collection{}, err
+	}
+	if v, ok := value.(system.DateTime); ok {
+		return system.Collection{v.Date()}, nil
+	}
+	return nil, componentTypeError("dateOf", value)
+}
+
+// TimeOf returns the time component of the input DateTime, or empty if it is
+// less precise than an hour.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/#timeof--time
+func TimeOf(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	value, err := componentInput(input, args)
+	if err != nil || value == nil {
+		return system.Collection{}, err
+	}
+	if v, ok := value.(system.DateTime); ok {
+		return componentResult(v.Time())
+	}
+	return nil, componentTypeError("timeOf", value)
+}
+
+// Duration returns the number of whole calendar periods of the precision
+// args[1] from the input to args[0], such as the whole years between two
+// Dates. The result is negative if args[0] is earlier than the input, and
+// empty if either value is less precise than the requested precision.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/#durationvalue-t-precision-string--integer
+func Duration(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	return calendarArithmetic(ctx, input, args, "duration", system.DateTime.Duration, system.Time.Duration)
+}
+
+// Difference returns the number of boundaries of the precision args[1] that
+// are crossed from the input to args[0], such that the difference in years
+// from 2023-12-31 to 2024-01-01 is 1. The result is negative if args[0] is
+// earlier than the input, and empty if either value is less precise than the
+// requested precision.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/#differencevalue-t-precision-string--integer
+func Difference(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	return calendarArithmetic(ctx, input, args, "difference", system.DateTime.Difference, system.Time.Difference)
+}
+
+// calendarArithmetic implements duration() and difference(), which measure
+// Dates and DateTimes with dateTimeFn, and Times with timeFn. Dates are
+// measured as DateTimes, so that they can be compared with either type.
+func calendarArithmetic(
+	ctx *expr.Context, input system.Collection, args []expr.Expression, name string,
+	dateTimeFn func(system.DateTime, system.DateTime, units.Time) (int64, bool),
+	timeFn func(system.Time, system.Time, units.Time) (int64, bool),
+) (system.Collection, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 2", ErrWrongArity, len(args))
+	}
+	start, err := singletonInput(input)
+	if err != nil || start == nil {
+		return system.Collection{}, err
+	}
+	output, err := args[0].Evaluate(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	end, err := singletonInput(output)
+	if err != nil || end == nil {
+		return system.Collection{}, err
+	}
+	precisionOutput, err := args[1].Evaluate(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	if precisionOutput.IsEmpty() {
+		return system.Collection{}, nil
+	}
+	keyword, err := precisionOutput.ToString()
+	if err != nil {
+		return nil, err
+	}
+	unit, err := units.TimeFromKeyword(keyword)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidInput, err)
+	}
+
+	var result int64
+	var ok bool
+	switch s := toDateTime(start).(type) {
+	case system.DateTime:
+		e, isDateTime := toDateTime(end).(system.DateTime)
+		if !isDateTime {
+			return nil, fmt.Errorf("%w: %s() is not defined between %s and %s", expr.ErrInvalidType, name, start.Name(), end.Name())
+		}
+		result, ok = dateTimeFn(s, e, unit)
+	case system.Time:
+		e, isTime := end.(system.Time)
+		if !isTime {
+			return nil, fmt.Errorf("%w: %s() is not defined between %s and %s", expr.ErrInvalidType, name, start.Name(), end.Name())
+		}
+		if unit == units.Years || unit == units.Months || unit == units.Weeks || unit == units.Days {
+			return nil, fmt.Errorf("%w: %s() in %s is not defined for Time", ErrInvalidInput, name, keyword)
+		}
+		result, ok = timeFn(s, e, unit)
+	default:
+		return nil, componentTypeError(name, start)
+	}
+	if !ok {
+		return system.Collection{}, nil
+	}
+	integer, ok := narrow.ToInt32(result)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s() result %v overflows Integer", ErrInvalidInput, name, result)
+	}
+	return system.Collection{system.Integer(integer)}, nil
+}
+
+// toDateTime returns value as a DateTime if it is a Date, and unchanged
+// otherwise.
+func toDateTime(value system.Any) system.Any {
+	if date, ok := value.(system.Date); ok {
+		return date.ToDateTime()
+	}
+	return value
+}
+
+// componentInput validates that a component function received no arguments,
+// and returns its singleton input as a system type, or nil if the input is
+// empty.
+func componentInput(input system.Collection, args []expr.Expression) (system.Any, error) {
+	if len(args) != 0 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 0", ErrWrongArity, len(args))
+	}
+	return singletonInput(input)
+}
+
+// componentResult returns a collection holding the component value, or an
+// empty collection if the input is less precise than the component.
+func componentResult[T system.Any](value T, ok bool) (system.Collection, error) {
+	if !ok {
+		return system.Collection{}, nil
+	}
+	return system.Collection{value}, nil
+}
+
+// componentTypeError returns the error raised when the component function
+// name is called on an input of an unsupported type.
+func componentTypeError(name string, value system.Any) error {
+	return fmt.Errorf("%w: %s() is not defined for %s", expr.ErrInvalidType, name, value.Name())
+}
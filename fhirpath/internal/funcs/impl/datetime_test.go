@@ -0,0 +1,276 @@
+package impl_test
+
+import (
+	"testing"
+
+	"github.com/fhir-fli/fhirpath-go/fhir"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/internal/expr"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/internal/expr/exprtest"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/internal/funcs/impl"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/system"
+	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+func TestDateTimeComponentFunctions(t *testing.T) {
+	dateTime := system.MustParseDateTime("2012-01-31T12:30:59.042-07:00")
+
+	testCases := []struct {
+		name    string
+		fn      func(*expr.Context, system.Collection, ...expr.Expression) (system.Collection, error)
+		input   system.Collection
+		args    []expr.Expression
+		want    system.Collection
+		wantErr bool
+	}{
+		{
+			name:  "yearOf date",
+			fn:    impl.YearOf,
+			input: system.Collection{system.MustParseDate("2012")},
+			want:  system.Collection{system.Integer(2012)},
+		},
+		{
+			name:  "monthOf FHIR date",
+			fn:    impl.MonthOf,
+			input: system.Collection{fhir.MustParseDate("2012-03")},
+			want:  system.Collection{system.Integer(3)},
+		},
+		{
+			name:  "dayOf date below precision",
+			fn:    impl.DayOf,
+			input: system.Collection{system.MustParseDate("2012-03")},
+			want:  system.Collection{},
+		},
+		{
+			name:  "dayOf dateTime",
+			fn:    impl.DayOf,
+			input: system.Collection{dateTime},
+			want:  system.Collection{system.Integer(31)},
+		},
+		{
+			name:  "hourOf time",
+			fn:    impl.HourOf,
+			input: system.Collection{system.MustParseTime("12:30")},
+			want:  system.Collection{system.Integer(12)},
+		},
+		{
+			name:  "minuteOf FHIR instant",
+			fn:    impl.MinuteOf,
+			input: system.Collection{fhir.MustParseInstant("2012-01-31T12:30:59Z")},
+			want:  system.Collection{system.Integer(30)},
+		},
+		{
+			name:  "secondOf FHIR dateTime",
+			fn:    impl.SecondOf,
+			input: system.Collection{fhir.MustParseDateTime("2012-01-31T12:30:59Z")},
+			want:  system.Collection{system.Integer(59)},
+		},
+		{
+			name:  "millisecondOf dateTime",
+			fn:    impl.MillisecondOf,
+			input: system.Collection{dateTime},
+			want:  system.Collection{system.Integer(42)},
+		},
+		{
+			name:  "millisecondOf time below precision",
+			fn:    impl.MillisecondOf,
+			input: system.Collection{system.MustParseTime("12:30:59")},
+			want:  system.Collection{},
+		},
+		{
+			name:  "timezoneOffsetOf dateTime",
+			fn:    impl.TimezoneOffsetOf,
+			input: system.Collection{dateTime},
+			want:  system.Collection{system.MustParseDecimal("-7")},
+		},
+		{
+			name:  "dateOf dateTime",
+			fn:    impl.DateOf,
+			input: system.Collection{dateTime},
+			want:  system.Collection{system.MustParseDate("2012-01-31")},
+		},
+		{
+			name:  "timeOf dateTime",
+			fn:    impl.TimeOf,
+			input: system.Collection{dateTime},
+			want:  system.Collection{system.MustParseTime("12:30:59.042")},
+		},
+		{
+			name:  "timeOf dateTime without time",
+			fn:    impl.TimeOf,
+			input: system.Collection{system.MustParseDateTime("2012-01-31T")},
+			want:  system.Collection{},
+		},
+		{
+			name:  "empty input",
+			fn:    impl.YearOf,
+			input: system.Collection{},
+			want:  system.Collection{},
+		},
+		{
+			name:  "date without value",
+			fn:    impl.YearOf,
+			input: system.Collection{&dtpb.Date{}},
+			want:  system.Collection{},
+		},
+		{
+			name:    "yearOf time",
+			fn:      impl.YearOf,
+			input:   system.Collection{system.MustParseTime("12:30")},
+			wantErr: true,
+		},
+		{
+			name:    "hourOf date",
+			fn:      impl.HourOf,
+			input:   system.Collection{system.MustParseDate("2012")},
+			wantErr: true,
+		},
+		{
+			name:    "multiple inputs",
+			fn:      impl.YearOf,
+			input:   system.Collection{dateTime, dateTime},
+			wantErr: true,
+		},
+		{
+			name:    "unexpected argument",
+			fn:      impl.YearOf,
+			input:   system.Collection{dateTime},
+			args:    []expr.Expression{exprtest.Return(system.Integer(1))},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.fn(&expr.Context{}, tc.input, tc.args...)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("returned unexpected diff (-want, +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCalendarArithmeticFunctions(t *testing.T) {
+	testCases := []struct {
+		name    string
+		fn      func(*expr.Context, system.Collection, ...expr.Expression) (system.Collection, error)
+		input   system.Collection
+		args    []expr.Expression
+		want    system.Collection
+		wantErr bool
+	}{
+		{
+			name:  "duration in years between dates",
+			fn:    impl.Duration,
+			input: system.Collection{system.MustParseDate("2000-03-22")},
+			args:  []expr.Expression{exprtest.Return(system.MustParseDate("2024-03-21")), exprtest.Return(system.String("years"))},
+			want:  system.Collection{system.Integer(23)},
+		},
+		{
+			name:  "difference in years between dates",
+			fn:    impl.Difference,
+			input: system.Collection{system.MustParseDate("2000-03-22")},
+			args:  []expr.Expression{exprtest.Return(system.MustParseDate("2024-03-21")), exprtest.Return(system.String("year"))},
+			want:  system.Collection{system.Integer(24)},
+		},
+		{
+			name:  "duration in days between FHIR date and dateTime",
+			fn:    impl.Duration,
+			input: system.Collection{fhir.MustParseDate("2024-01-01")},
+			args:  []expr.Expression{exprtest.Return(system.MustParseDateTime("2024-01-31T12:00:00Z")), exprtest.Return(system.String("days"))},
+			want:  system.Collection{system.Integer(30)},
+		},
+		{
+			name:  "duration in minutes between times",
+			fn:    impl.Duration,
+			input: system.Collection{system.MustParseTime("10:30:00")},
+			args:  []expr.Expression{exprtest.Return(system.MustParseTime("09:00:00")), exprtest.Return(system.String("minutes"))},
+			want:  system.Collection{system.Integer(-90)},
+		},
+		{
+			name:  "below precision",
+			fn:    impl.Duration,
+			input: system.Collection{system.MustParseDate("2024-01")},
+			args:  []expr.Expression{exprtest.Return(system.MustParseDate("2024-03-01")), exprtest.Return(system.String("days"))},
+			want:  system.Collection{},
+		},
+		{
+			name:  "empty input",
+			fn:    impl.Duration,
+			input: system.Collection{},
+			args:  []expr.Expression{exprtest.Return(system.MustParseDate("2024")), exprtest.Return(system.String("years"))},
+			want:  system.Collection{},
+		},
+		{
+			name:  "empty value",
+			fn:    impl.Difference,
+			input: system.Collection{system.MustParseDate("2024")},
+			args:  []expr.Expression{exprtest.Return(), exprtest.Return(system.String("years"))},
+			want:  system.Collection{},
+		},
+		{
+			name:  "empty precision",
+			fn:    impl.Difference,
+			input: system.Collection{system.MustParseDate("2024")},
+			args:  []expr.Expression{exprtest.Return(system.MustParseDate("2025")), exprtest.Return()},
+			want:  system.Collection{},
+		},
+		{
+			name:    "unknown precision",
+			fn:      impl.Duration,
+			input:   system.Collection{system.MustParseDate("2024")},
+			args:    []expr.Expression{exprtest.Return(system.MustParseDate("2025")), exprtest.Return(system.String("decades"))},
+			wantErr: true,
+		},
+		{
+			name:    "date and time",
+			fn:      impl.Duration,
+			input:   system.Collection{system.MustParseDate("2024")},
+			args:    []expr.Expression{exprtest.Return(system.MustParseTime("10:00")), exprtest.Return(system.String("hours"))},
+			wantErr: true,
+		},
+		{
+			name:    "time in days",
+			fn:      impl.Difference,
+			input:   system.Collection{system.MustParseTime("10:00")},
+			args:    []expr.Expression{exprtest.Return(system.MustParseTime("11:00")), exprtest.Return(system.String("days"))},
+			wantErr: true,
+		},
+		{
+			name:    "non-temporal input",
+			fn:      impl.Duration,
+			input:   system.Collection{system.Integer(1)},
+			args:    []expr.Expression{exprtest.Return(system.Integer(2)), exprtest.Return(system.String("days"))},
+			wantErr: true,
+		},
+		{
+			name:    "missing precision",
+			fn:      impl.Duration,
+			input:   system.Collection{system.MustParseDate("2024")},
+			args:    []expr.Expression{exprtest.Return(system.MustParseDate("2025"))},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.fn(&expr.Context{}, tc.input, tc.args...)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("returned unexpected diff (-want, +got)\n%s", diff)
+			}
+		})
+	}
+}
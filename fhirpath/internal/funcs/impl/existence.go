@@ -83,6 +83,8 @@ func Count(ctx *expr.Context, input system.Collection, args ...expr.Expression)
 
 // Exists evaluates the expression args[0] on each input item, returns whether
 // there exists at least one item that cause the expression to evaluate to true.
+// FHIR primitives that carry only extensions are items of the collection, so
+// exist even though they have no value; see HasValue.
 // http://hl7.org/fhirpath/N1/#existscriteria-expression-boolean
 func Exists(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
 	if len(args) == 0 {
@@ -95,6 +97,73 @@ func Exists(ctx *expr.Context, input system.Collection, args ...expr.Expression)
 	return system.Collection{system.Boolean(len(whereOutput) > 0)}, nil
 }
 
+// All evaluates the expression args[0] on each input item, returns whether
+// the expression evaluates to true for every item. If the input collection is
+// empty, the result is true.
+// http://hl7.org/fhirpath/N1/#allcriteria-expression-boolean
+func All(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 1", ErrWrongArity, len(args))
+	}
+	e := args[0]
+	for i, item := range input {
+		output, err := e.Evaluate(ctx.WithIteration(expr.Iteration{This: item, Index: i}), system.Collection{item})
+		if err != nil {
+			return nil, err
+		}
+		pass, err := output.ToSingletonBoolean()
+		if err != nil {
+			return nil, fmt.Errorf("evaluating all criteria as boolean resulted in an error: %w", err)
+		}
+		if len(pass) == 0 || !pass[0] {
+			return system.Collection{system.Boolean(false)}, nil
+		}
+	}
+	return system.Collection{system.Boolean(true)}, nil
+}
+
+// SubsetOf returns true if all items in the input collection are members of
+// the collection passed as the other argument args[0], as determined by the
+// equals (=) operator. If the input collection is empty, the result is true.
+// If the other collection is empty and the input is not, the result is false.
+// FHIRPath docs here: https://hl7.org/fhirpath/N1/#subsetofother-collection-boolean
+func SubsetOf(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 1", ErrWrongArity, len(args))
+	}
+	other, err := args[0].Evaluate(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return system.Collection{system.Boolean(isSubset(input, other))}, nil
+}
+
+// SupersetOf returns true if all items in the collection passed as the other
+// argument args[0] are members of the input collection, as determined by the
+// equals (=) operator. If the other collection is empty, the result is true.
+// If the input collection is empty and the other is not, the result is false.
+// FHIRPath docs here: https://hl7.org/fhirpath/N1/#supersetofother-collection-boolean
+func SupersetOf(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 1", ErrWrongArity, len(args))
+	}
+	other, err := args[0].Evaluate(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return system.Collection{system.Boolean(isSubset(other, input))}, nil
+}
+
+// isSubset returns true if every item in subset is contained in superset.
+func isSubset(subset, superset system.Collection) bool {
+	for _, item := range subset {
+		if !superset.Contains(item) {
+			return false
+		}
+	}
+	return true
+}
+
 // Empty evaluates the expression args[0] on each input item, returns whether
 // none of the items causes the expression to evaluate to true.
 // http://hl7.org/fhirpath/N1/#empty-boolean
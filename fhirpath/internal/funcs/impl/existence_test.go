@@ -24,6 +24,200 @@ var coding = []*dtpb.Coding{
 	{},
 }
 
+func TestAll(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   system.Collection
+		args    []expr.Expression
+		want    system.Collection
+		wantErr bool
+	}{
+		{
+			name:  "returns true if input is empty",
+			input: system.Collection{},
+			args:  []expr.Expression{exprtest.Return(system.Boolean(false))},
+			want:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:  "returns true if criteria is true for every item",
+			input: system.Collection{system.Integer(1), system.Integer(2)},
+			args:  []expr.Expression{exprtest.Return(system.Boolean(true))},
+			want:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:  "returns false if criteria is false for an item",
+			input: system.Collection{system.Integer(1), system.Integer(2)},
+			args: []expr.Expression{&expr.ComparisonExpression{
+				Left:  &expr.ThisExpression{},
+				Right: exprtest.Return(system.Integer(2)),
+				Op:    expr.Lt,
+			}},
+			want: system.Collection{system.Boolean(false)},
+		},
+		{
+			name:  "returns false if criteria is empty for an item",
+			input: system.Collection{system.Integer(1)},
+			args:  []expr.Expression{exprtest.Return()},
+			want:  system.Collection{system.Boolean(false)},
+		},
+		{
+			name:  "binds $index for each item",
+			input: system.Collection{system.String("a"), system.String("b")},
+			args: []expr.Expression{&expr.ComparisonExpression{
+				Left:  &expr.IterationIndexExpression{},
+				Right: exprtest.Return(system.Integer(2)),
+				Op:    expr.Lt,
+			}},
+			want: system.Collection{system.Boolean(true)},
+		},
+		{
+			name:    "raises error with wrong arity",
+			input:   system.Collection{system.Integer(1)},
+			wantErr: true,
+		},
+		{
+			name:    "raises error if criteria is not a boolean",
+			input:   system.Collection{system.Integer(1)},
+			args:    []expr.Expression{exprtest.Return(system.Integer(1), system.Integer(2))},
+			wantErr: true,
+		},
+		{
+			name:    "propagates criteria error",
+			input:   system.Collection{system.Integer(1)},
+			args:    []expr.Expression{exprtest.Error(errors.New("some error"))},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := impl.All(&expr.Context{}, tc.input, tc.args...)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("All() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("All() returned unexpected diff (-want, +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSubsetOf(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   system.Collection
+		args    []expr.Expression
+		want    system.Collection
+		wantErr bool
+	}{
+		{
+			name:  "returns true if input is empty",
+			input: system.Collection{},
+			args:  []expr.Expression{exprtest.Return()},
+			want:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:  "returns false if other is empty",
+			input: system.Collection{system.Integer(1)},
+			args:  []expr.Expression{exprtest.Return()},
+			want:  system.Collection{system.Boolean(false)},
+		},
+		{
+			name:  "returns true if all items are in other",
+			input: system.Collection{fhir.String("a"), system.Integer(1)},
+			args:  []expr.Expression{exprtest.Return(system.Integer(1), system.Integer(2), system.String("a"))},
+			want:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:  "returns false if an item is not in other",
+			input: system.Collection{system.Integer(1), system.Integer(3)},
+			args:  []expr.Expression{exprtest.Return(system.Integer(1), system.Integer(2))},
+			want:  system.Collection{system.Boolean(false)},
+		},
+		{
+			name:    "propagates argument error",
+			input:   system.Collection{system.Integer(1)},
+			args:    []expr.Expression{exprtest.Error(errors.New("some error"))},
+			wantErr: true,
+		},
+		{
+			name:    "raises error with wrong arity",
+			input:   system.Collection{system.Integer(1)},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := impl.SubsetOf(&expr.Context{}, tc.input, tc.args...)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("SubsetOf() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("SubsetOf() returned unexpected diff (-want, +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSupersetOf(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   system.Collection
+		args    []expr.Expression
+		want    system.Collection
+		wantErr bool
+	}{
+		{
+			name:  "returns true if other is empty",
+			input: system.Collection{},
+			args:  []expr.Expression{exprtest.Return()},
+			want:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:  "returns false if input is empty",
+			input: system.Collection{},
+			args:  []expr.Expression{exprtest.Return(system.Integer(1))},
+			want:  system.Collection{system.Boolean(false)},
+		},
+		{
+			name:  "returns true if all items of other are in input",
+			input: system.Collection{fhir.Integer(1), system.Integer(2), system.String("a")},
+			args:  []expr.Expression{exprtest.Return(system.Integer(1), system.String("a"))},
+			want:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:  "returns false if an item of other is not in input",
+			input: system.Collection{system.Integer(1), system.Integer(2)},
+			args:  []expr.Expression{exprtest.Return(system.Integer(3))},
+			want:  system.Collection{system.Boolean(false)},
+		},
+		{
+			name:    "propagates argument error",
+			input:   system.Collection{system.Integer(1)},
+			args:    []expr.Expression{exprtest.Error(errors.New("some error"))},
+			wantErr: true,
+		},
+		{
+			name:    "raises error with wrong arity",
+			input:   system.Collection{system.Integer(1)},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := impl.SupersetOf(&expr.Context{}, tc.input, tc.args...)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("SupersetOf() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("SupersetOf() returned unexpected diff (-want, +got)\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestAllTrue(t *testing.T) {
 	testCases := []struct {
 		name    string
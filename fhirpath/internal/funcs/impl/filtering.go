@@ -3,8 +3,11 @@ package impl
 import (
 	"fmt"
 
+	"github.com/fhir-fli/fhirpath-go/fhir"
 	"github.com/fhir-fli/fhirpath-go/fhirpath/internal/expr"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/internal/reflection"
 	"github.com/fhir-fli/fhirpath-go/fhirpath/system"
+	"github.com/fhir-fli/fhirpath-go/internal/protofields"
 )
 
 // Where evaluates the expression args[0] on each input item, collects the items that cause
@@ -15,8 +18,8 @@ func Where(ctx *expr.Context, input system.Collection, args ...expr.Expression)
 	}
 	e := args[0]
 	result := system.Collection{}
-	for _, item := range input {
-		output, err := e.Evaluate(ctx, system.Collection{item})
+	for i, item := range input {
+		output, err := e.Evaluate(ctx.WithIteration(expr.Iteration{This: item, Index: i}), system.Collection{item})
 		if err != nil {
 			return nil, err
 		}
@@ -27,9 +30,38 @@ func Where(ctx *expr.Context, input system.Collection, args ...expr.Expression)
 		if err != nil {
 			return nil, fmt.Errorf("evaluating where condition as boolean resulted in an error: %w", err)
 		}
-		if pass[0] {
+		if len(pass) == 1 && bool(pass[0]) {
 			result = append(result, item)
 		}
 	}
 	return result, nil
 }
+
+// OfType returns the items in the input collection that are of the type given
+// by the type specifier args[0], or a subtype thereof. Type specifiers are
+// resolved with the same namespace and case-sensitivity rules as the 'is'
+// operator, and choice types are unwrapped as with the 'as' operator.
+// FHIRPath docs here: https://hl7.org/fhirpath/N1/#oftypetype-type-specifier-collection
+func OfType(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 1", ErrWrongArity, len(args))
+	}
+	specifier, ok := args[0].(*expr.TypeSpecifierExpression)
+	if !ok {
+		return nil, fmt.Errorf("%w: ofType() expects a type specifier argument", expr.ErrInvalidType)
+	}
+	result := system.Collection{}
+	for _, item := range input {
+		typeSpecifier, err := reflection.TypeOf(item)
+		if err != nil || !typeSpecifier.Is(specifier.Type) {
+			continue
+		}
+		if message, ok := item.(fhir.Base); ok {
+			if oneOf := protofields.UnwrapOneofField(message, "choice"); oneOf != nil {
+				item = oneOf
+			}
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
@@ -8,9 +8,11 @@ import (
 	"github.com/fhir-fli/fhirpath-go/fhirpath/internal/expr"
 	"github.com/fhir-fli/fhirpath-go/fhirpath/internal/expr/exprtest"
 	"github.com/fhir-fli/fhirpath-go/fhirpath/internal/funcs/impl"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/internal/reflection"
 	"github.com/fhir-fli/fhirpath-go/fhirpath/system"
 	"github.com/fhir-fli/fhirpath-go/internal/slices"
 	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	opb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/observation_go_proto"
 	"github.com/google/go-cmp/cmp"
 	"google.golang.org/protobuf/testing/protocmp"
 )
@@ -112,3 +114,75 @@ func TestWhere_RaisesError(t *testing.T) {
 		})
 	}
 }
+
+func TestOfType(t *testing.T) {
+	quantity := &dtpb.Quantity{Value: fhir.Decimal(1)}
+	choice := &opb.Observation_ValueX{
+		Choice: &opb.Observation_ValueX_Quantity{Quantity: quantity},
+	}
+	typeArg := func(namespace, name string) expr.Expression {
+		return &expr.TypeSpecifierExpression{Type: reflection.MustCreateTypeSpecifier(namespace, name)}
+	}
+
+	testCases := []struct {
+		name    string
+		input   system.Collection
+		args    []expr.Expression
+		want    system.Collection
+		wantErr bool
+	}{
+		{
+			name:  "returns empty if input is empty",
+			input: system.Collection{},
+			args:  []expr.Expression{typeArg(reflection.FHIR, "string")},
+			want:  system.Collection{},
+		},
+		{
+			name:  "filters FHIR types",
+			input: system.Collection{fhir.String("a"), fhir.Integer(1), fhir.String("b")},
+			args:  []expr.Expression{typeArg(reflection.FHIR, "string")},
+			want:  system.Collection{fhir.String("a"), fhir.String("b")},
+		},
+		{
+			name:  "includes subtypes",
+			input: system.Collection{fhir.Code("a"), fhir.Integer(1), fhir.ID("b")},
+			args:  []expr.Expression{typeArg(reflection.FHIR, "string")},
+			want:  system.Collection{fhir.Code("a"), fhir.ID("b")},
+		},
+		{
+			name:  "filters System types",
+			input: system.Collection{system.Integer(1), fhir.Integer(2), system.String("a")},
+			args:  []expr.Expression{typeArg(reflection.System, "Integer")},
+			want:  system.Collection{system.Integer(1)},
+		},
+		{
+			name:  "unwraps choice types",
+			input: system.Collection{choice},
+			args:  []expr.Expression{typeArg(reflection.FHIR, "Quantity")},
+			want:  system.Collection{quantity},
+		},
+		{
+			name:    "raises error if argument is not a type specifier",
+			input:   system.Collection{system.Integer(1)},
+			args:    []expr.Expression{exprtest.Return(system.String("Integer"))},
+			wantErr: true,
+		},
+		{
+			name:    "raises error with wrong arity",
+			input:   system.Collection{system.Integer(1)},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := impl.OfType(&expr.Context{}, tc.input, tc.args...)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("OfType() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("OfType() returned unexpected diff (-want, +got)\n%s", diff)
+			}
+		})
+	}
+}
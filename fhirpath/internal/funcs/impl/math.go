@@ -4,8 +4,6 @@ import (
 	"errors"
 	"fmt"
 	"math"
-	"strconv"
-	"strings"
 
 	"github.com/fhir-fli/fhirpath-go/fhirpath/internal/expr"
 	"github.com/fhir-fli/fhirpath-go/fhirpath/system"
@@ -17,11 +15,15 @@ var (
 )
 
 // Abs returns the absolute value of the input.
-// When taking the absolute value of a quantity, the unit is unchanged.
+// When taking the absolute value of a quantity, the unit is unchanged. The
+// absolute value of the least Integer overflows, so it is empty, as in Power.
 // FHIRPath docs here: https://hl7.org/fhirpath/N1/#abs-integer-decimal-quantity
 func Abs(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
 	// Input validations
-	if input.IsEmpty() {
+	value, err := numberInput(input)
+	if err != nil {
+		return nil, err
+	} else if value == nil {
 		return system.Collection{}, nil
 	}
 	// Argument validations
@@ -29,339 +31,359 @@ func Abs(ctx *expr.Context, input system.Collection, args ...expr.Expression) (s
 		return nil, fmt.Errorf("%w: received %v arguments, expected 0", ErrWrongArity, len(args))
 	}
 
-	switch input[0].(type) {
+	switch number := value.(type) {
 	case system.Integer:
-		// Input type conversion to int32
-		number, err := input.ToInt32()
-		if err != nil {
-			return nil, err
+		// The absolute value of the least Integer overflows.
+		if number == math.MinInt32 {
+			return system.Collection{}, nil
 		}
-		// Absolution number
-		res := math.Abs(float64(number))
-		return system.Collection{system.Integer(res)}, nil
-	case system.Decimal:
-		// Input type conversion to float64
-		number, err := input.ToFloat64()
-		if err != nil {
-			return nil, err
+		if number < 0 {
+			return system.Collection{-number}, nil
 		}
-		// Absolution number
-		res := math.Abs(number)
-		result := decimal.NewFromFloat(res)
-		return system.Collection{system.Decimal(result)}, nil
+		return system.Collection{number}, nil
+	case system.Decimal:
+		return system.Collection{system.Decimal(decimal.Decimal(number).Abs())}, nil
 	case system.Quantity:
-		quantity := strings.Split(input[0].(system.Quantity).String(), " ")
-		// Input type conversion
-		f, err := strconv.ParseFloat(quantity[0], 64)
-		if err != nil {
-			return nil, err
-		}
-		// Absolution number
-		res := math.Abs(f)
-		return system.Collection{system.MustParseQuantity(fmt.Sprintf("%f", res), quantity[1])}, nil
+		abs := decimal.Decimal(number.Value()).Abs()
+		return system.Collection{number.WithValue(system.Decimal(abs))}, nil
 	}
-	return nil, errors.New("input is not a number")
+	return nil, notANumber(value)
 }
 
 // Ceiling returns the first integer greater than or equal to the input.
+// When taking the ceiling of a quantity, the unit is unchanged.
 // FHIRPath docs here: https://hl7.org/fhirpath/N1/#ceiling-integer
 func Ceiling(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
-	// Input validations
-	if input.IsEmpty() {
-		return system.Collection{}, nil
-	}
-	// Argument validations
-	if len(args) != 0 {
-		return nil, fmt.Errorf("%w: received %v arguments, expected 0", ErrWrongArity, len(args))
-	}
-	// Input type conversion to float64
-	number, err := input.ToFloat64()
-	if err != nil {
-		return nil, err
-	}
-	// Ceiling number
-	result := math.Ceil(number)
-	return system.Collection{system.Integer(result)}, nil
+	return roundToInteger(input, args, decimal.Decimal.Ceil)
 }
 
 // Exp returns e raised to the power of the input.
 // FHIRPath docs here: https://hl7.org/fhirpath/N1/#exp-decimal
 func Exp(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
 	// Input validations
-	if input.IsEmpty() {
+	value, err := numberInput(input)
+	if err != nil {
+		return nil, err
+	} else if value == nil {
 		return system.Collection{}, nil
 	}
 	// Argument validations
 	if len(args) != 0 {
 		return nil, fmt.Errorf("%w: received %v arguments, expected 0", ErrWrongArity, len(args))
 	}
-	// Reading input
-	number, err := input.ToFloat64()
+	number, err := toFloat64(value)
 	if err != nil {
 		return nil, err
 	}
-	// Exp number
-	res := math.Pow(math.E, number)
-	result := system.MustParseDecimal(fmt.Sprintf("%v", res))
-	return system.Collection{result}, nil
+	return decimalResult(math.Exp(number)), nil
 }
 
 // Floor returns the first integer less than or equal to the input.
+// When taking the floor of a quantity, the unit is unchanged.
 // FHIRPath docs here: https://hl7.org/fhirpath/n1/#floor-integer
 func Floor(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
-	// Input validations
-	if input.IsEmpty() {
-		return system.Collection{}, nil
-	}
-	// Argument validations
-	if len(args) != 0 {
-		return nil, fmt.Errorf("%w: received %v arguments, expected 0", ErrWrongArity, len(args))
-	}
-	// Input type conversion to float64
-	number, err := input.ToFloat64()
-	if err != nil {
-		return nil, err
-	}
-	// Flooring number
-	result := math.Floor(number)
-	return system.Collection{system.Integer(result)}, nil
+	return roundToInteger(input, args, decimal.Decimal.Floor)
 }
 
 // Ln returns the natural logarithm of the input number.
 // FHIRPath docs here: https://hl7.org/fhirpath/N1/#ln-decimal
 func Ln(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
 	// Input validations
-	if input.IsEmpty() {
+	value, err := numberInput(input)
+	if err != nil {
+		return nil, err
+	} else if value == nil {
 		return system.Collection{}, nil
 	}
 	// Argument validations
 	if len(args) != 0 {
 		return nil, fmt.Errorf("%w: received %v arguments, expected 0", ErrWrongArity, len(args))
 	}
-	// Input type conversion to float64
-	number, err := input.ToFloat64()
+	number, err := toFloat64(value)
 	if err != nil {
 		return nil, err
 	}
-	res := math.Log(number)
-	// Validating NaN case
-	if math.IsNaN(res) {
-		return system.Collection{}, nil
-	}
-	// Type conversion to system.Decimal
-	result := decimal.NewFromFloat(res)
-	return system.Collection{system.Decimal(result)}, nil
+	return decimalResult(math.Log(number)), nil
 }
 
 // Log returns the logarithm base of the input number.
 // FHIRPath docs here: https://hl7.org/fhirpath/N1/#logbase-decimal-decimal
 func Log(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
 	// Input validations
-	if input.IsEmpty() {
-		return system.Collection{}, nil
-	}
-	// Input type conversion to float64
-	number, err := input.ToFloat64()
+	value, err := numberInput(input)
 	if err != nil {
 		return nil, err
+	} else if value == nil {
+		return system.Collection{}, nil
 	}
-	// Validating args
+	// Argument validations
 	if len(args) != 1 {
 		return nil, fmt.Errorf("%w: received %v arguments, expected 1", ErrWrongArity, len(args))
 	}
-	argValues, err := args[0].Evaluate(ctx, input)
+	number, err := toFloat64(value)
 	if err != nil {
 		return nil, err
 	}
-	base, err := argValues.ToFloat64()
+	baseValue, err := numberArgument(ctx, input, args[0])
 	if err != nil {
 		return nil, err
-	}
-	// Log number to base
-	res := logToBase(number, base)
-	// Validating NaN case
-	if math.IsNaN(res) {
+	} else if baseValue == nil {
 		return system.Collection{}, nil
 	}
-	// Type conversion to system.Decimal
-	result := decimal.NewFromFloat(res)
-	return system.Collection{system.Decimal(result)}, nil
+	base, err := toFloat64(baseValue)
+	if err != nil {
+		return nil, err
+	}
+	// Use the exact functions for the common bases, so that 1000.log(10) is 3.
+	switch base {
+	case 10:
+		return decimalResult(math.Log10(number)), nil
+	case 2:
+		return decimalResult(math.Log2(number)), nil
+	}
+	return decimalResult(math.Log(number) / math.Log(base)), nil
 }
 
-// Power returns a number to the exponent power.
+// Power returns a number to the exponent power. If both the number and the
+// exponent are Integers, and the exponent isn't negative, the result is an
+// Integer, which is empty if it overflows; otherwise it is a Decimal.
 // FHIRPath docs here: https://hl7.org/fhirpath/N1/#powerexponent-integer-decimal-integer-decimal
 func Power(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
-	// Validating input
-	if input.IsEmpty() {
+	// Input validations
+	value, err := numberInput(input)
+	if err != nil {
+		return nil, err
+	} else if value == nil {
 		return system.Collection{}, nil
 	}
-	// Validating args
+	// Argument validations
 	if len(args) != 1 {
 		return nil, fmt.Errorf("%w: received %v arguments, expected 1", ErrWrongArity, len(args))
 	}
-	argValues, err := args[0].Evaluate(ctx, input)
+	expValue, err := numberArgument(ctx, input, args[0])
 	if err != nil {
 		return nil, err
+	} else if expValue == nil {
+		return system.Collection{}, nil
 	}
 	// Validating integers case
-	_, ok := input[0].(system.Integer)
-	_, ok2 := argValues[0].(system.Integer)
-	if ok && ok2 {
-		// Input type conversion to int32
-		number, err := input.ToInt32()
-		if err != nil {
-			return nil, err
-		}
-		// Input type conversion to int32
-		exp, err := argValues.ToInt32()
-		if err != nil {
-			return nil, err
+	number, ok := value.(system.Integer)
+	exp, ok2 := expValue.(system.Integer)
+	if ok && ok2 && exp >= 0 {
+		result, ok := powInt32(int32(number), int32(exp))
+		if !ok {
+			return system.Collection{}, nil
 		}
-		// Powering ints
-		res := powInt32(number, exp)
-		return system.Collection{system.Integer(res)}, nil
+		return system.Collection{system.Integer(result)}, nil
 	}
-	// Input type conversion to float64
-	number, err := input.ToFloat64()
+	base, err := toFloat64(value)
 	if err != nil {
 		return nil, err
 	}
-	// Input type conversion to float64
-	exp, err := argValues.ToFloat64()
+	power, err := toFloat64(expValue)
 	if err != nil {
 		return nil, err
 	}
-	// Powering number
-	res := math.Pow(number, exp)
-	// Validating NaN case
-	if math.IsNaN(res) {
-		return system.Collection{}, nil
-	}
-	// Type conversion to system.Decimal
-	result := decimal.NewFromFloat(res)
-	return system.Collection{system.Decimal(result)}, nil
+	return decimalResult(math.Pow(base, power)), nil
 }
 
 // Round rounds the decimal to the nearest whole number using a traditional round (i.e. 0.5 or higher will round to 1).
 // If specified, the precision argument determines the decimal place at which the rounding will occur.
 // If not specified, the rounding will default to 0 decimal places.
+// When rounding a quantity, the unit is unchanged.
 // FHIRPath docs here: https://hl7.org/fhirpath/N1/#roundprecision-integer-decimal
 func Round(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
 	// Validating input
-	if input.IsEmpty() {
+	value, err := numberInput(input)
+	if err != nil {
+		return nil, err
+	} else if value == nil {
 		return system.Collection{}, nil
 	}
-	if !input.IsSingleton() {
-		return nil, errors.New("invalid input, is not a singleton")
-	}
 	// Validating args
 	if len(args) > 1 {
 		return nil, fmt.Errorf("%w: received %v arguments, expected 0 or 1 arguments", ErrWrongArity, len(args))
 	}
 	precision := int32(0)
 	if len(args) == 1 {
-		argValues, err := args[0].Evaluate(ctx, input)
+		argValue, err := numberArgument(ctx, input, args[0])
 		if err != nil {
 			return nil, err
+		} else if argValue == nil {
+			return system.Collection{}, nil
 		}
-		// Arg type conversion to int32
-		precision, err = argValues.ToInt32()
-		if err != nil {
-			return nil, err
+		integer, ok := argValue.(system.Integer)
+		if !ok {
+			return nil, fmt.Errorf("%w: precision must be an Integer, got %T", ErrInvalidInput, argValue)
 		}
-		if precision < 0 {
-			return nil, errors.New("precision must be greater or equal than 0")
+		if integer < 0 {
+			return nil, fmt.Errorf("%w: precision must be greater or equal than 0", ErrInvalidInput)
 		}
-	}
-	value, err := system.From(input[0])
-	if err != nil {
-		return nil, err
+		precision = int32(integer)
 	}
 	// Rounding number
-	switch value.(type) {
+	switch number := value.(type) {
 	case system.Decimal:
-		res, _ := input[0].(system.Decimal)
-		result := res.Round(precision)
-		return system.Collection{result}, nil
+		return system.Collection{number.Round(precision)}, nil
 	case system.Integer:
-		number, err := input.ToInt32()
-		if err != nil {
-			return nil, err
-		}
-		res := system.MustParseDecimal(fmt.Sprintf("%d", number))
-		result := res.Round(precision)
-		return system.Collection{result}, nil
+		return system.Collection{toDecimal(number).Round(precision)}, nil
+	case system.Quantity:
+		return system.Collection{number.WithValue(number.Value().Round(precision))}, nil
 	}
-	return nil, errors.New("input is not a number")
+	return nil, notANumber(value)
 }
 
-// Sqrt returns the square root of the input number as a Decimal.
+// Sqrt returns the square root of the input number as a Decimal. The result
+// is empty for negative numbers.
 // FHIRPath docs here: https://hl7.org/fhirpath/N1/#sqrt-decimal
 func Sqrt(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
 	// Input validations
-	if input.IsEmpty() {
+	value, err := numberInput(input)
+	if err != nil {
+		return nil, err
+	} else if value == nil {
 		return system.Collection{}, nil
 	}
 	// Argument validations
 	if len(args) != 0 {
 		return nil, fmt.Errorf("%w: received %v arguments, expected 0", ErrWrongArity, len(args))
 	}
-	// Input type conversion to float64
-	number, err := input.ToFloat64()
+	number, err := toFloat64(value)
 	if err != nil {
 		return nil, err
 	}
-	// Validate negative input
-	if number < 0 {
-		return nil, fmt.Errorf("%w: unable to sqrt negative value", ErrInvalidInput)
-	}
-	// Ceiling number
-	value := math.Sqrt(number)
-	result := decimal.NewFromFloat(value)
-	return system.Collection{system.Decimal(result)}, nil
+	return decimalResult(math.Sqrt(number)), nil
 }
 
 // Truncate returns the integer portion of the input.
+// When truncating a quantity, the unit is unchanged.
 // FHIRPath docs here: https://hl7.org/fhirpath/N1/#truncate-integer
 func Truncate(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	return roundToInteger(input, args, func(d decimal.Decimal) decimal.Decimal {
+		return d.Truncate(0)
+	})
+}
+
+// roundToInteger implements the functions that take no arguments and round
+// the input to a whole number with the given rounding function. Integers are
+// returned as is, Decimals are converted to Integers, and Quantities retain
+// their unit.
+func roundToInteger(input system.Collection, args []expr.Expression, round func(decimal.Decimal) decimal.Decimal) (system.Collection, error) {
 	// Input validations
-	if input.IsEmpty() {
+	value, err := numberInput(input)
+	if err != nil {
+		return nil, err
+	} else if value == nil {
 		return system.Collection{}, nil
 	}
 	// Argument validations
 	if len(args) != 0 {
 		return nil, fmt.Errorf("%w: received %v arguments, expected 0", ErrWrongArity, len(args))
 	}
-	// Input type conversion to float64
-	number, err := input.ToFloat64()
+
+	switch number := value.(type) {
+	case system.Integer:
+		return system.Collection{number}, nil
+	case system.Decimal:
+		rounded := round(decimal.Decimal(number))
+		if !rounded.Equal(decimal.NewFromInt32(int32(rounded.IntPart()))) {
+			return nil, fmt.Errorf("%w: %v overflows Integer", ErrInvalidInput, rounded)
+		}
+		return system.Collection{system.Integer(rounded.IntPart())}, nil
+	case system.Quantity:
+		rounded := round(decimal.Decimal(number.Value()))
+		return system.Collection{number.WithValue(system.Decimal(rounded))}, nil
+	}
+	return nil, notANumber(value)
+}
+
+// numberInput returns the single Integer, Decimal or Quantity of the input
+// collection, converting FHIR primitives to their System types. Returns nil if
+// the input is empty, or holds a primitive without a value.
+func numberInput(input system.Collection) (system.Any, error) {
+	if input.IsEmpty() {
+		return nil, nil
+	}
+	if !input.IsSingleton() {
+		return nil, fmt.Errorf("%w: input has length %v, expected 1", expr.ErrNotSingleton, len(input))
+	}
+	value, err := system.From(input[0])
+	if errors.Is(err, system.ErrNoValue) {
+		return nil, nil
+	}
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: %w", ErrInvalidInput, err)
 	}
-	// Ceiling number
-	result := math.Trunc(number)
-	return system.Collection{system.Integer(result)}, nil
+	switch value.(type) {
+	case system.Integer, system.Decimal, system.Quantity:
+		return value, nil
+	}
+	return nil, notANumber(value)
 }
 
-func logToBase(number, base float64) float64 {
-	if number <= 0 || base <= 1 {
-		return math.NaN() // Return NaN for invalid inputs
+// numberArgument evaluates the argument arg of a math function in the same way
+// as numberInput.
+func numberArgument(ctx *expr.Context, input system.Collection, arg expr.Expression) (system.Any, error) {
+	output, err := arg.Evaluate(ctx, input)
+	if err != nil {
+		return nil, err
 	}
+	return numberInput(output)
+}
 
-	return math.Log(number) / math.Log(base)
+// toDecimal promotes an Integer to a Decimal.
+func toDecimal(i system.Integer) system.Decimal {
+	return system.Decimal(decimal.NewFromInt32(int32(i)))
 }
 
-// powInt32 returns the powering of a number to a given exponential.
-func powInt32(base, exp int32) int32 {
-	if exp == 0 {
-		return 1
-	}
-	if exp < 0 {
-		return 0
+// toFloat64 converts an Integer or Decimal to a float64. Quantities aren't
+// supported by the functions that use this.
+func toFloat64(value system.Any) (float64, error) {
+	switch number := value.(type) {
+	case system.Integer:
+		return float64(number), nil
+	case system.Decimal:
+		return decimal.Decimal(number).InexactFloat64(), nil
 	}
+	return 0, fmt.Errorf("%w: %T is not an Integer or Decimal", ErrInvalidInput, value)
+}
+
+// decimalResult returns a collection holding the Decimal value of f, or an
+// empty collection if f is undefined or infinite, such as for the square root
+// of a negative number.
+func decimalResult(f float64) system.Collection {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return system.Collection{}
+	}
+	return system.Collection{system.Decimal(decimal.NewFromFloat(f))}
+}
 
-	result := base
-	for i := int32(2); i <= exp; i++ {
-		result *= base
+// notANumber returns the error raised by math functions when value isn't a
+// number.
+func notANumber(value system.Any) error {
+	return fmt.Errorf("%w: %T is not a number", ErrInvalidInput, value)
+}
+
+// powInt32 returns the powering of a number to a given non-negative
+// exponential, by repeated squaring. Returns false if the result overflows an
+// int32.
+func powInt32(base, exp int32) (int32, bool) {
+	result, square := int64(1), int64(base)
+	for ; exp > 0; exp >>= 1 {
+		if exp&1 == 1 {
+			result *= square
+			if result > math.MaxInt32 || result < math.MinInt32 {
+				return 0, false
+			}
+		}
+		if exp > 1 {
+			// The square is multiplied into the result for a later bit of the
+			// exponent, so the result overflows if the square does.
+			square *= square
+			if square > math.MaxInt32 {
+				return 0, false
+			}
+		}
 	}
-	return result
+	return int32(result), true
 }
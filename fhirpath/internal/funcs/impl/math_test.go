@@ -1,6 +1,8 @@
 package impl_test
 
 import (
+	"errors"
+	"math"
 	"testing"
 
 	"github.com/fhir-fli/fhirpath-go/fhir"
@@ -87,6 +89,12 @@ func TestAbs(t *testing.T) {
 			want:    system.Collection{system.MustParseQuantity("10.5", "kg")},
 			wantErr: false,
 		},
+		{
+			name:    "returns an empty collection if the absolute value overflows",
+			input:   system.Collection{system.Integer(math.MinInt32)},
+			want:    system.Collection{},
+			wantErr: false,
+		},
 		{
 			name:    "abs zero number",
 			input:   system.Collection{system.Integer(0)},
@@ -526,6 +534,24 @@ func TestLog(t *testing.T) {
 			want:    system.Collection{system.MustParseDecimal("2")},
 			wantErr: false,
 		},
+		{
+			name:  "logs a number with base 10 exactly",
+			input: system.Collection{system.Integer(1000)},
+			args: []expr.Expression{
+				exprtest.Return(system.Integer(10)),
+			},
+			want:    system.Collection{system.MustParseDecimal("3")},
+			wantErr: false,
+		},
+		{
+			name:  "logs a number with base 2 exactly",
+			input: system.Collection{system.Integer(1 << 29)},
+			args: []expr.Expression{
+				exprtest.Return(system.Integer(2)),
+			},
+			want:    system.Collection{system.MustParseDecimal("29")},
+			wantErr: false,
+		},
 		{
 			name:  "logs a PositiveInt number with base 2",
 			input: system.Collection{fhir.PositiveInt(16)},
@@ -657,9 +683,72 @@ func TestPower(t *testing.T) {
 			args: []expr.Expression{
 				exprtest.Return(system.Integer(-2)),
 			},
+			want:    system.Collection{system.MustParseDecimal("0.0625")},
+			wantErr: false,
+		},
+		{
+			name:  "powers an integer number to an int arg",
+			input: system.Collection{system.Integer(-3)},
+			args: []expr.Expression{
+				exprtest.Return(system.Integer(3)),
+			},
+			want:    system.Collection{system.Integer(-27)},
+			wantErr: false,
+		},
+		{
+			name:  "returns an empty collection if integer result overflows",
+			input: system.Collection{system.Integer(2)},
+			args: []expr.Expression{
+				exprtest.Return(system.Integer(40)),
+			},
+			want:    system.Collection{},
+			wantErr: false,
+		},
+		{
+			name:  "powers a negative integer to the least Integer",
+			input: system.Collection{system.Integer(-2)},
+			args: []expr.Expression{
+				exprtest.Return(system.Integer(31)),
+			},
+			want:    system.Collection{system.Integer(math.MinInt32)},
+			wantErr: false,
+		},
+		{
+			name:  "powers one to the greatest Integer",
+			input: system.Collection{system.Integer(1)},
+			args: []expr.Expression{
+				exprtest.Return(system.Integer(math.MaxInt32)),
+			},
+			want:    system.Collection{system.Integer(1)},
+			wantErr: false,
+		},
+		{
+			name:  "powers minus one to the greatest Integer",
+			input: system.Collection{system.Integer(-1)},
+			args: []expr.Expression{
+				exprtest.Return(system.Integer(math.MaxInt32)),
+			},
+			want:    system.Collection{system.Integer(-1)},
+			wantErr: false,
+		},
+		{
+			name:  "powers zero to the greatest Integer",
+			input: system.Collection{system.Integer(0)},
+			args: []expr.Expression{
+				exprtest.Return(system.Integer(math.MaxInt32)),
+			},
 			want:    system.Collection{system.Integer(0)},
 			wantErr: false,
 		},
+		{
+			name:  "returns an empty collection if negative integer result overflows",
+			input: system.Collection{system.Integer(-2)},
+			args: []expr.Expression{
+				exprtest.Return(system.Integer(33)),
+			},
+			want:    system.Collection{},
+			wantErr: false,
+		},
 		{
 			name:  "powers a PositiveInt number",
 			input: system.Collection{fhir.PositiveInt(10)},
@@ -885,10 +974,10 @@ func TestSqrt(t *testing.T) {
 			wantErr: false,
 		},
 		{
-			name:    "errors if input is negative",
+			name:    "returns an empty collection if input is negative",
 			input:   system.Collection{system.MustParseDecimal("-16.0")},
-			want:    nil,
-			wantErr: true,
+			want:    system.Collection{},
+			wantErr: false,
 		},
 		{
 			name:  "errors if args length is more than 0",
@@ -1057,3 +1146,87 @@ func TestTruncate(t *testing.T) {
 		})
 	}
 }
+
+func TestMathFunctions_Quantity(t *testing.T) {
+	quantity := system.Collection{system.MustParseQuantity("-10.45", "mg")}
+
+	testCases := []struct {
+		name    string
+		fn      func(*expr.Context, system.Collection, ...expr.Expression) (system.Collection, error)
+		args    []expr.Expression
+		want    system.Collection
+		wantErr bool
+	}{
+		{
+			name: "abs keeps unit",
+			fn:   impl.Abs,
+			want: system.Collection{system.MustParseQuantity("10.45", "mg")},
+		},
+		{
+			name: "ceiling keeps unit",
+			fn:   impl.Ceiling,
+			want: system.Collection{system.MustParseQuantity("-10", "mg")},
+		},
+		{
+			name: "floor keeps unit",
+			fn:   impl.Floor,
+			want: system.Collection{system.MustParseQuantity("-11", "mg")},
+		},
+		{
+			name: "round keeps unit",
+			fn:   impl.Round,
+			args: []expr.Expression{exprtest.Return(system.Integer(1))},
+			want: system.Collection{system.MustParseQuantity("-10.5", "mg")},
+		},
+		{
+			name: "truncate keeps unit",
+			fn:   impl.Truncate,
+			want: system.Collection{system.MustParseQuantity("-10", "mg")},
+		},
+		{
+			name:    "sqrt errors on quantity",
+			fn:      impl.Sqrt,
+			wantErr: true,
+		},
+		{
+			name:    "power errors on quantity",
+			fn:      impl.Power,
+			args:    []expr.Expression{exprtest.Return(system.Integer(2))},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.fn(&expr.Context{}, quantity, tc.args...)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("returned unexpected diff (-want, +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMathFunctions_PromotesFHIRDecimal(t *testing.T) {
+	input := system.Collection{fhir.Decimal(2.5)}
+
+	got, err := impl.Power(&expr.Context{}, input, exprtest.Return(system.Integer(2)))
+	if err != nil {
+		t.Fatalf("Power() returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(system.Collection{system.MustParseDecimal("6.25")}, got, protocmp.Transform()); diff != "" {
+		t.Errorf("Power() returned unexpected diff (-want, +got)\n%s", diff)
+	}
+}
+
+func TestMathFunctions_NonSingletonInput_ReturnsNotSingleton(t *testing.T) {
+	input := system.Collection{system.Integer(1), system.Integer(2)}
+
+	_, err := impl.Ln(&expr.Context{}, input)
+
+	if !errors.Is(err, expr.ErrNotSingleton) {
+		t.Errorf("Ln() returned error %v, want %v", err, expr.ErrNotSingleton)
+	}
+}
@@ -0,0 +1,44 @@
+package impl
+
+import (
+	"fmt"
+
+	"github.com/fhir-fli/fhirpath-go/fhirpath/internal/expr"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/system"
+)
+
+// Children returns a collection with all immediate child nodes of all items in
+// the input collection. The ordering of the children is the order in which the
+// fields are declared in the FHIR protos.
+// FHIRPath docs here: https://hl7.org/fhirpath/N1/#children-collection
+func Children(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if len(args) != 0 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 0", ErrWrongArity, len(args))
+	}
+	result := system.Collection{}
+	for _, item := range input {
+		result = append(result, children(ctx, item)...)
+	}
+	return result, nil
+}
+
+// Descendants returns a collection with all descendant nodes of all items in the
+// input collection. This is a shorthand for repeat(children()), and so it is
+// subject to the same de-duplication semantics.
+// FHIRPath docs here: https://hl7.org/fhirpath/N1/#descendants-collection
+func Descendants(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if len(args) != 0 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 0", ErrWrongArity, len(args))
+	}
+	return repeat(input, func(item any, _ int) (system.Collection, error) {
+		return children(ctx, item), nil
+	})
+}
+
+// children returns the immediate child nodes of item, recording item as their
+// parent in the containers of ctx.
+func children(ctx *expr.Context, item any) system.Collection {
+	result := expr.Children(system.Collection{item})
+	ctx.Containers.Record(item, result)
+	return result
+}
@@ -0,0 +1,113 @@
+package impl_test
+
+import (
+	"testing"
+
+	"github.com/fhir-fli/fhirpath-go/fhir"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/internal/expr"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/internal/expr/exprtest"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/internal/funcs/impl"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/system"
+	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+func TestChildren(t *testing.T) {
+	line := fhir.String("123 Main St")
+	state := fhir.String("CA")
+	addr := &dtpb.Address{
+		Line:  []*dtpb.String{line},
+		State: state,
+	}
+
+	testCases := []struct {
+		name    string
+		input   system.Collection
+		args    []expr.Expression
+		want    system.Collection
+		wantErr bool
+	}{
+		{
+			name:  "returns empty on empty input",
+			input: system.Collection{},
+			want:  system.Collection{},
+		},
+		{
+			name:  "returns immediate children",
+			input: system.Collection{addr},
+			want:  system.Collection{line, state},
+		},
+		{
+			name:    "raises error with arguments",
+			input:   system.Collection{addr},
+			args:    []expr.Expression{exprtest.Return(system.Integer(1))},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := impl.Children(&expr.Context{}, tc.input, tc.args...)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Children() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("Children() returned unexpected diff (-want, +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDescendants(t *testing.T) {
+	system1 := fhir.URI("http://system")
+	code1 := fhir.Code("a")
+	coding := &dtpb.Coding{System: system1, Code: code1}
+	text := fhir.String("text")
+	concept := &dtpb.CodeableConcept{
+		Coding: []*dtpb.Coding{coding},
+		Text:   text,
+	}
+
+	testCases := []struct {
+		name    string
+		input   system.Collection
+		args    []expr.Expression
+		want    system.Collection
+		wantErr bool
+	}{
+		{
+			name:  "returns empty on empty input",
+			input: system.Collection{},
+			want:  system.Collection{},
+		},
+		{
+			name:  "returns all descendants",
+			input: system.Collection{concept},
+			want:  system.Collection{coding, text, system1, code1},
+		},
+		{
+			name:  "de-duplicates equal descendants",
+			input: system.Collection{&dtpb.HumanName{Given: []*dtpb.String{text, fhir.String("text")}}},
+			want:  system.Collection{text},
+		},
+		{
+			name:    "raises error with arguments",
+			input:   system.Collection{concept},
+			args:    []expr.Expression{exprtest.Return(system.Integer(1))},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := impl.Descendants(&expr.Context{}, tc.input, tc.args...)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Descendants() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("Descendants() returned unexpected diff (-want, +got)\n%s", diff)
+			}
+		})
+	}
+}
@@ -18,8 +18,8 @@ func Select(ctx *expr.Context, input system.Collection, args ...expr.Expression)
 	e := args[0]
 	result := system.Collection{}
 	var fieldErrs []error
-	for _, item := range input {
-		output, err := e.Evaluate(ctx, system.Collection{item})
+	for i, item := range input {
+		output, err := e.Evaluate(ctx.WithIteration(expr.Iteration{This: item, Index: i}), system.Collection{item})
 		// If the error is ErrInvalidField, don't immediately raise it
 		if err != nil {
 			if errors.Is(err, expr.ErrInvalidField) {
@@ -36,3 +36,107 @@ func Select(ctx *expr.Context, input system.Collection, args ...expr.Expression)
 	}
 	return result, nil
 }
+
+// Repeat evaluates the expression args[0] on each input item, and adds the
+// result to the output collection. The expression is then evaluated again on
+// each item that was newly added, and so on until no new items are produced.
+// Items are only added if they are not already in the output, as determined by
+// the equals (=) operator, so this terminates even if the projection is cyclic.
+// FHIRPath docs here: https://hl7.org/fhirpath/N1/#repeatprojection-expression-collection
+func Repeat(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 1", ErrWrongArity, len(args))
+	}
+	e := args[0]
+	return repeat(input, func(item any, index int) (system.Collection, error) {
+		return e.Evaluate(ctx.WithIteration(expr.Iteration{This: item, Index: index}), system.Collection{item})
+	})
+}
+
+// RepeatAll evaluates the expression args[0] on each input item, and adds the
+// result to the output collection. The expression is then evaluated again on
+// each item that was added, and so on until no items are produced. Unlike
+// Repeat, duplicate items are retained, so a cyclic projection such as
+// repeatAll($this) never ends; this is raised as an error once the traversal
+// exceeds a fixed depth or number of items.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/
+func RepeatAll(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 1", ErrWrongArity, len(args))
+	}
+	e := args[0]
+	return repeatAll(input, func(item any, index int) (system.Collection, error) {
+		return e.Evaluate(ctx.WithIteration(expr.Iteration{This: item, Index: index}), system.Collection{item})
+	})
+}
+
+// maxRepeatDepth is the greatest number of rounds of a repeatAll() traversal.
+// Resources are not nested nearly this deeply, so exceeding it indicates a
+// cyclic projection.
+const maxRepeatDepth = 1000
+
+// maxRepeatItems is the greatest number of items collected by a repeatAll()
+// traversal. A projection that duplicates its input grows exponentially, and
+// reaches this long before maxRepeatDepth.
+const maxRepeatItems = 100000
+
+// errRepeatDepth is raised when a repeatAll() traversal doesn't terminate.
+var errRepeatDepth = errors.New("repeatAll() exceeded the maximum traversal depth")
+
+// repeat performs a breadth-first traversal of the graph defined by project,
+// starting from (but not including) the input collection, and returns every
+// distinct node that was reached. The project function receives each item along
+// with its index within the current round of the traversal.
+func repeat(input system.Collection, project func(any, int) (system.Collection, error)) (system.Collection, error) {
+	return traverse(input, project, true)
+}
+
+// repeatAll performs the same traversal as repeat, but returns every node
+// that was reached, including duplicates.
+func repeatAll(input system.Collection, project func(any, int) (system.Collection, error)) (system.Collection, error) {
+	return traverse(input, project, false)
+}
+
+// traverse implements repeat and repeatAll. If distinct is set, nodes that
+// were already reached are neither returned nor traversed again.
+func traverse(input system.Collection, project func(any, int) (system.Collection, error), distinct bool) (system.Collection, error) {
+	result := system.Collection{}
+	var fieldErrs []error
+	queue := input
+	for round := 0; len(queue) > 0; round++ {
+		if !distinct && round == maxRepeatDepth {
+			return nil, fmt.Errorf("%w: %v rounds", errRepeatDepth, maxRepeatDepth)
+		}
+		var next system.Collection
+		for i, item := range queue {
+			output, err := project(item, i)
+			// As with Select, a projection may be invalid for some of the items, since
+			// a traversal can reach nodes of different types.
+			if errors.Is(err, expr.ErrInvalidField) {
+				if round == 0 {
+					fieldErrs = append(fieldErrs, err)
+				}
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			for _, value := range output {
+				if distinct && result.Contains(value) {
+					continue
+				}
+				if !distinct && len(result) == maxRepeatItems {
+					return nil, fmt.Errorf("%w: %v items", errRepeatDepth, maxRepeatItems)
+				}
+				result = append(result, value)
+				next = append(next, value)
+			}
+		}
+		queue = next
+	}
+	// Raise field errors if one was raised for each input.
+	if len(input) > 0 && len(fieldErrs) == len(input) {
+		return nil, errors.Join(fieldErrs...)
+	}
+	return result, nil
+}
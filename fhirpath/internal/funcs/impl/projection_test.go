@@ -11,6 +11,7 @@ import (
 	"github.com/fhir-fli/fhirpath-go/fhirpath/system"
 	"github.com/fhir-fli/fhirpath-go/internal/slices"
 	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	qpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/questionnaire_go_proto"
 	"github.com/google/go-cmp/cmp"
 	"google.golang.org/protobuf/testing/protocmp"
 )
@@ -120,3 +121,186 @@ func TestSelect_RaisesError(t *testing.T) {
 		})
 	}
 }
+
+func TestRepeat_Evaluates(t *testing.T) {
+	leaf := &qpb.Questionnaire_Item{LinkId: fhir.String("1.1.1")}
+	child := &qpb.Questionnaire_Item{
+		LinkId: fhir.String("1.1"),
+		Item:   []*qpb.Questionnaire_Item{leaf},
+	}
+	root := &qpb.Questionnaire_Item{
+		LinkId: fhir.String("1"),
+		Item:   []*qpb.Questionnaire_Item{child},
+	}
+	questionnaire := &qpb.Questionnaire{
+		Item: []*qpb.Questionnaire_Item{root},
+	}
+
+	testCases := []struct {
+		name            string
+		inputCollection system.Collection
+		inputArgs       []expr.Expression
+		wantCollection  system.Collection
+	}{
+		{
+			name:            "repeat on empty collection",
+			inputCollection: system.Collection{},
+			inputArgs:       []expr.Expression{&expr.FieldExpression{FieldName: "item"}},
+			wantCollection:  system.Collection{},
+		},
+		{
+			name:            "projects nested items",
+			inputCollection: system.Collection{questionnaire},
+			inputArgs:       []expr.Expression{&expr.FieldExpression{FieldName: "item"}},
+			wantCollection:  system.Collection{root, child, leaf},
+		},
+		{
+			name:            "does not include input items",
+			inputCollection: system.Collection{root},
+			inputArgs:       []expr.Expression{&expr.FieldExpression{FieldName: "item"}},
+			wantCollection:  system.Collection{child, leaf},
+		},
+		{
+			name:            "terminates on cyclic projection",
+			inputCollection: system.Collection{system.Integer(1)},
+			inputArgs:       []expr.Expression{exprtest.Return(system.Integer(1), system.Integer(2))},
+			wantCollection:  system.Collection{system.Integer(1), system.Integer(2)},
+		},
+		{
+			name:            "de-duplicates equal items",
+			inputCollection: system.Collection{system.Integer(1), system.Integer(2)},
+			inputArgs:       []expr.Expression{exprtest.Return(fhir.String("a"), system.String("a"))},
+			wantCollection:  system.Collection{fhir.String("a")},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := impl.Repeat(&expr.Context{}, tc.inputCollection, tc.inputArgs...)
+			if err != nil {
+				t.Fatalf("Repeat function returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.wantCollection, got, protocmp.Transform()); diff != "" {
+				t.Errorf("Repeat function returned unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRepeat_RaisesError(t *testing.T) {
+	testCases := []struct {
+		name            string
+		inputArgs       []expr.Expression
+		inputCollection system.Collection
+	}{
+		{
+			name:            "no arguments",
+			inputArgs:       []expr.Expression{},
+			inputCollection: slices.MustConvert[any](address),
+		},
+		{
+			name:            "argument expression raises error",
+			inputArgs:       []expr.Expression{exprtest.Error(errors.New("some error"))},
+			inputCollection: slices.MustConvert[any](address),
+		},
+		{
+			name:            "invalid field as argument expression",
+			inputArgs:       []expr.Expression{&expr.FieldExpression{FieldName: "invalid"}},
+			inputCollection: slices.MustConvert[any](address),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := impl.Repeat(&expr.Context{}, tc.inputCollection, tc.inputArgs...); err == nil {
+				t.Fatalf("evaluating Repeat function didn't return error when expected")
+			}
+		})
+	}
+}
+
+func TestRepeatAll_Evaluates(t *testing.T) {
+	leaf := &qpb.Questionnaire_Item{LinkId: fhir.String("1.1")}
+	root := &qpb.Questionnaire_Item{
+		LinkId: fhir.String("1"),
+		Item:   []*qpb.Questionnaire_Item{leaf},
+	}
+	questionnaire := &qpb.Questionnaire{
+		Item: []*qpb.Questionnaire_Item{root},
+	}
+
+	testCases := []struct {
+		name            string
+		inputCollection system.Collection
+		inputArgs       []expr.Expression
+		wantCollection  system.Collection
+	}{
+		{
+			name:            "repeatAll on empty collection",
+			inputCollection: system.Collection{},
+			inputArgs:       []expr.Expression{&expr.FieldExpression{FieldName: "item"}},
+			wantCollection:  system.Collection{},
+		},
+		{
+			name:            "projects nested items",
+			inputCollection: system.Collection{questionnaire},
+			inputArgs:       []expr.Expression{&expr.FieldExpression{FieldName: "item"}},
+			wantCollection:  system.Collection{root, leaf},
+		},
+		{
+			name:            "retains duplicate items",
+			inputCollection: system.Collection{questionnaire, questionnaire},
+			inputArgs:       []expr.Expression{&expr.FieldExpression{FieldName: "item"}},
+			wantCollection:  system.Collection{root, root, leaf, leaf},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := impl.RepeatAll(&expr.Context{}, tc.inputCollection, tc.inputArgs...)
+			if err != nil {
+				t.Fatalf("RepeatAll function returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.wantCollection, got, protocmp.Transform()); diff != "" {
+				t.Errorf("RepeatAll function returned unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRepeatAll_RaisesError(t *testing.T) {
+	testCases := []struct {
+		name            string
+		inputArgs       []expr.Expression
+		inputCollection system.Collection
+	}{
+		{
+			name:            "no arguments",
+			inputArgs:       []expr.Expression{},
+			inputCollection: slices.MustConvert[any](address),
+		},
+		{
+			name:            "argument expression raises error",
+			inputArgs:       []expr.Expression{exprtest.Error(errors.New("some error"))},
+			inputCollection: slices.MustConvert[any](address),
+		},
+		{
+			name:            "cyclic projection",
+			inputArgs:       []expr.Expression{exprtest.Return(system.Integer(1))},
+			inputCollection: system.Collection{system.Integer(1)},
+		},
+		{
+			name:            "duplicating projection",
+			inputArgs:       []expr.Expression{exprtest.Return(system.Integer(1), system.Integer(1))},
+			inputCollection: system.Collection{system.Integer(1), system.Integer(2)},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := impl.RepeatAll(&expr.Context{}, tc.inputCollection, tc.inputArgs...); err == nil {
+				t.Fatalf("evaluating RepeatAll function didn't return error when expected")
+			}
+		})
+	}
+}
@@ -1,11 +1,20 @@
 package impl
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/fhir-fli/fhirpath-go/fhir"
 	"github.com/fhir-fli/fhirpath-go/fhirpath/internal/expr"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/profile"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/resolver"
 	"github.com/fhir-fli/fhirpath-go/fhirpath/system"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/terminology"
+	"github.com/fhir-fli/fhirpath-go/internal/element/coding"
+	"github.com/fhir-fli/fhirpath-go/internal/element/reference"
+	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	"google.golang.org/protobuf/proto"
 )
 
 // Extension is syntactic sugar over `extension.where(url = ...)`, and is
@@ -40,3 +49,316 @@ func Extension(ctx *expr.Context, input system.Collection, args ...expr.Expressi
 	}
 	return result, nil
 }
+
+// Resolve returns the resources that are the targets of the References,
+// canonicals, and uris in the input collection, as located by the Resolver
+// provided for evaluation. Fragment references, such as "#med1", are resolved
+// against the resource that holds them, as recorded during navigation.
+// Items that are not references, or whose target cannot be found, are
+// ignored.
+//
+// For more details, see https://hl7.org/fhir/R4/fhirpath.html#functions
+func Resolve(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if len(args) != 0 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 0", ErrWrongArity, len(args))
+	}
+	if ctx.Resolver == nil {
+		return system.Collection{}, nil
+	}
+
+	result := system.Collection{}
+	for _, item := range input {
+		ref, ok := referenceString(item)
+		if !ok {
+			continue
+		}
+		res, err := resolveReference(ctx, item, ref)
+		if err != nil {
+			return nil, fmt.Errorf("resolving reference '%s': %w", ref, err)
+		}
+		if res != nil {
+			result = append(result, res)
+		}
+	}
+	return result, nil
+}
+
+// resolveReference resolves ref, the string form of item. Fragment references
+// are resolved in the resource that holds item, if the Resolver is a
+// resolver.ContainerResolver and that resource is known.
+func resolveReference(ctx *expr.Context, item any, ref string) (fhir.Resource, error) {
+	containerResolver, ok := ctx.Resolver.(resolver.ContainerResolver)
+	if !ok || !strings.HasPrefix(ref, "#") {
+		return ctx.Resolver.Resolve(ref)
+	}
+	if container := containerOf(ctx, item); container != nil {
+		return containerResolver.ResolveIn(container, ref)
+	}
+	return ctx.Resolver.Resolve(ref)
+}
+
+// containerOf returns the resource that holds item, as recorded when it was
+// reached by navigation. Items that weren't reached by navigation, such as
+// string literals, are held by the input resource if there is only one.
+// Returns nil if the resource isn't known.
+func containerOf(ctx *expr.Context, item any) fhir.Resource {
+	if container, ok := ctx.Containers.Holder(item); ok {
+		return container
+	}
+	if roots, _ := ctx.ExternalConstants["context"].(system.Collection); len(roots) == 1 {
+		res, _ := roots[0].(fhir.Resource)
+		return res
+	}
+	return nil
+}
+
+// referenceString returns the string form of a literal Reference, or the
+// value of a canonical, uri, url, or string.
+func referenceString(item any) (string, bool) {
+	switch v := item.(type) {
+	case *dtpb.Reference:
+		lit, err := reference.LiteralInfoOf(v)
+		if err != nil {
+			return "", false
+		}
+		return lit.URIString(), true
+	case *dtpb.Canonical:
+		return v.GetValue(), true
+	case *dtpb.Uri:
+		return v.GetValue(), true
+	case *dtpb.Url:
+		return v.GetValue(), true
+	case *dtpb.String:
+		return v.GetValue(), true
+	case system.String:
+		return string(v), true
+	}
+	return "", false
+}
+
+// MemberOf returns true if the code, Coding, or CodeableConcept in the input
+// collection is a member of the value set with the given canonical URL. For a
+// CodeableConcept, any one of its codings must be a member. Returns empty if
+// the input is not a singleton, or if membership cannot be determined.
+//
+// For more details, see https://hl7.org/fhir/R4/fhirpath.html#functions
+func MemberOf(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 1", ErrWrongArity, len(args))
+	}
+	if ctx.Terminology == nil || len(input) != 1 {
+		return system.Collection{}, nil
+	}
+	codings, ok := codingsOf(input[0])
+	if !ok {
+		return system.Collection{}, nil
+	}
+	valueSetResult, err := args[0].Evaluate(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	valueSet, err := valueSetResult.ToString()
+	if err != nil {
+		return nil, err
+	}
+
+	result := system.Collection{}
+	for _, coding := range codings {
+		member, err := ctx.Terminology.MemberOf(string(valueSet), coding)
+		if isIndeterminate(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if member {
+			return system.Collection{system.Boolean(true)}, nil
+		}
+		result = system.Collection{system.Boolean(false)}
+	}
+	return result, nil
+}
+
+// Subsumes returns true if the Coding or CodeableConcept in the input
+// collection subsumes the given Coding or CodeableConcept; that is, if it is
+// the same concept or one of its ancestors. For CodeableConcepts, any one of
+// the codings must subsume any one of the others. Returns empty if either
+// operand is not a singleton, or if subsumption cannot be determined.
+//
+// For more details, see https://hl7.org/fhir/R4/fhirpath.html#functions
+func Subsumes(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	return subsumption(ctx, input, args, false)
+}
+
+// SubsumedBy returns true if the Coding or CodeableConcept in the input
+// collection is subsumed by the given Coding or CodeableConcept; that is, if it
+// is the same concept or one of its descendants. This is the inverse of
+// subsumes().
+//
+// For more details, see https://hl7.org/fhir/R4/fhirpath.html#functions
+func SubsumedBy(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	return subsumption(ctx, input, args, true)
+}
+
+// subsumption implements Subsumes and SubsumedBy, swapping the operands if
+// inverse is true.
+func subsumption(ctx *expr.Context, input system.Collection, args []expr.Expression, inverse bool) (system.Collection, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 1", ErrWrongArity, len(args))
+	}
+	if ctx.Terminology == nil || len(input) != 1 {
+		return system.Collection{}, nil
+	}
+	arg, err := args[0].Evaluate(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	if len(arg) != 1 {
+		return system.Collection{}, nil
+	}
+	sources, ok := codingsOf(input[0])
+	if !ok {
+		return system.Collection{}, nil
+	}
+	targets, ok := codingsOf(arg[0])
+	if !ok {
+		return system.Collection{}, nil
+	}
+	if inverse {
+		sources, targets = targets, sources
+	}
+
+	result := system.Collection{}
+	for _, source := range sources {
+		for _, target := range targets {
+			subsumes, err := ctx.Terminology.Subsumes(source, target)
+			if isIndeterminate(err) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			if subsumes {
+				return system.Collection{system.Boolean(true)}, nil
+			}
+			result = system.Collection{system.Boolean(false)}
+		}
+	}
+	return result, nil
+}
+
+// codingsOf returns the codings represented by a code, Coding, or
+// CodeableConcept, or by a System String holding a code.
+func codingsOf(item any) ([]*dtpb.Coding, bool) {
+	switch v := item.(type) {
+	case system.String:
+		return []*dtpb.Coding{{Code: fhir.Code(string(v))}}, true
+	case proto.Message:
+		return coding.FromElement(v)
+	}
+	return nil, false
+}
+
+// isIndeterminate returns true if the terminology error means that the
+// answer to the question cannot be determined, rather than that the question
+// failed to be answered.
+func isIndeterminate(err error) bool {
+	return errors.Is(err, terminology.ErrUnknownValueSet) ||
+		errors.Is(err, terminology.ErrUnknownCodeSystem) ||
+		errors.Is(err, terminology.ErrUnrelatedSystems)
+}
+
+// ConformsTo returns true if the single item in the input collection conforms
+// to the profile with the given canonical URL, as decided by the profile
+// Validator provided for evaluation. Returns an error if the input contains
+// more than one item, or the profile cannot be resolved.
+//
+// For more details, see https://hl7.org/fhir/R4/fhirpath.html#functions
+func ConformsTo(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 1", ErrWrongArity, len(args))
+	}
+	if input.IsEmpty() {
+		return system.Collection{}, nil
+	}
+	if len(input) > 1 {
+		return nil, fmt.Errorf("%w: conformsTo() input contains %v elements", expr.ErrNotSingleton, len(input))
+	}
+	structureResult, err := args[0].Evaluate(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	structure, err := structureResult.ToString()
+	if err != nil {
+		return nil, err
+	}
+
+	element, ok := input[0].(fhir.Base)
+	if !ok {
+		return system.Collection{system.Boolean(false)}, nil
+	}
+	validator := ctx.ProfileValidator
+	if validator == nil {
+		validator = profile.ClaimedProfiles
+	}
+	conforms, err := validator.ConformsTo(element, string(structure))
+	if err != nil {
+		return nil, err
+	}
+	return system.Collection{system.Boolean(conforms)}, nil
+}
+
+// HasValue returns true if the input collection contains a single FHIR
+// primitive that holds a value, as opposed to one that carries only
+// extensions. Returns false otherwise.
+//
+// For more details, see https://hl7.org/fhir/R4/fhirpath.html#functions
+func HasValue(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if len(args) != 0 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 0", ErrWrongArity, len(args))
+	}
+	return system.Collection{system.Boolean(len(input) == 1 && system.HasValue(input[0]))}, nil
+}
+
+// GetValue returns the System value of the FHIR primitive in the input
+// collection. Returns empty if the input is not a single FHIR primitive that
+// holds a value.
+//
+// For more details, see https://hl7.org/fhir/R4/fhirpath.html#functions
+func GetValue(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if len(args) != 0 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 0", ErrWrongArity, len(args))
+	}
+	if len(input) != 1 || !system.HasValue(input[0]) {
+		return system.Collection{}, nil
+	}
+	value, err := system.From(input[0])
+	if err != nil {
+		return nil, err
+	}
+	return system.Collection{value}, nil
+}
+
+// HtmlChecks returns true if the XHTML in the input collection obeys the rules
+// for FHIR narratives: it must be well-formed with a single root div element in
+// the XHTML namespace, use only the permitted elements and attributes, contain
+// no scripts, forms, or event handlers, and have some non-whitespace content.
+// Returns false if the input is not XHTML.
+//
+// For more details, see https://hl7.org/fhir/R4/narrative.html#xhtml
+func HtmlChecks(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if len(args) != 0 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 0", ErrWrongArity, len(args))
+	}
+	if input.IsEmpty() {
+		return system.Collection{}, nil
+	}
+	if len(input) > 1 {
+		return nil, fmt.Errorf("%w: htmlChecks() input contains %v elements", expr.ErrNotSingleton, len(input))
+	}
+	xhtml, ok := input[0].(*dtpb.Xhtml)
+	if !ok {
+		return system.Collection{system.Boolean(false)}, nil
+	}
+	return system.Collection{system.Boolean(checkNarrative(xhtml.GetValue()) == nil)}, nil
+}
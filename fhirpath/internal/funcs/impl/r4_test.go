@@ -8,11 +8,16 @@ import (
 	"github.com/fhir-fli/fhirpath-go/fhirpath/internal/expr"
 	"github.com/fhir-fli/fhirpath-go/fhirpath/internal/expr/exprtest"
 	"github.com/fhir-fli/fhirpath-go/fhirpath/internal/funcs/impl"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/profile"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/resolver"
 	"github.com/fhir-fli/fhirpath-go/fhirpath/system"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/terminology"
 	"github.com/fhir-fli/fhirpath-go/internal/element/extension"
 	"github.com/fhir-fli/fhirpath-go/internal/fhirtest"
 	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	cspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/code_system_go_proto"
 	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+	vspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/value_set_go_proto"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"google.golang.org/protobuf/testing/protocmp"
@@ -118,3 +123,596 @@ func TestExtension_InvalidInput_RaisesError(t *testing.T) {
 		})
 	}
 }
+
+func TestResolve(t *testing.T) {
+	patient := &ppb.Patient{Id: fhir.ID("123")}
+	patientRef := &dtpb.Reference{
+		Reference: &dtpb.Reference_PatientId{PatientId: &dtpb.ReferenceId{Value: "123"}},
+	}
+	errSome := errors.New("some error")
+	mapResolver := resolver.NewMap(patient)
+
+	testCases := []struct {
+		name     string
+		resolver resolver.Resolver
+		input    system.Collection
+		args     []expr.Expression
+		want     system.Collection
+		wantErr  error
+	}{
+		{
+			name:     "resolves Reference",
+			resolver: mapResolver,
+			input:    system.Collection{patientRef},
+			want:     system.Collection{patient},
+		},
+		{
+			name:     "resolves string types",
+			resolver: mapResolver,
+			input: system.Collection{
+				system.String("Patient/123"),
+				fhir.String("Patient/123"),
+				fhir.URI("Patient/123"),
+				&dtpb.Canonical{Value: "Patient/123"},
+			},
+			want: system.Collection{patient, patient, patient, patient},
+		},
+		{
+			name:     "ignores unresolved and non-reference items",
+			resolver: mapResolver,
+			input: system.Collection{
+				system.String("Patient/456"),
+				system.Integer(1),
+				&dtpb.Reference{Display: fhir.String("logical")},
+			},
+			want: system.Collection{},
+		},
+		{
+			name:  "returns empty without resolver",
+			input: system.Collection{patientRef},
+			want:  system.Collection{},
+		},
+		{
+			name: "propagates resolver error",
+			resolver: resolver.Func(func(string) (fhir.Resource, error) {
+				return nil, errSome
+			}),
+			input:   system.Collection{patientRef},
+			wantErr: errSome,
+		},
+		{
+			name:     "raises error with wrong arity",
+			resolver: mapResolver,
+			input:    system.Collection{patientRef},
+			args:     []expr.Expression{exprtest.Return(system.String("Patient/123"))},
+			wantErr:  impl.ErrWrongArity,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := &expr.Context{Resolver: tc.resolver}
+
+			got, err := impl.Resolve(ctx, tc.input, tc.args...)
+
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Resolve() got error %v, want %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("Resolve() returned unexpected diff (-want, +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+const colorSystem = "http://example.com/CodeSystem/colors"
+
+func newTerminology(t *testing.T) terminology.Provider {
+	t.Helper()
+	codeSystem := &cspb.CodeSystem{
+		Url: fhir.URI(colorSystem),
+		Concept: []*cspb.CodeSystem_ConceptDefinition{
+			{
+				Code: fhir.Code("warm"),
+				Concept: []*cspb.CodeSystem_ConceptDefinition{
+					{Code: fhir.Code("red")},
+				},
+			},
+			{Code: fhir.Code("blue")},
+		},
+	}
+	valueSet := &vspb.ValueSet{
+		Url: fhir.URI("http://example.com/ValueSet/warm"),
+		Compose: &vspb.ValueSet_Compose{
+			Include: []*vspb.ValueSet_Compose_ConceptSet{
+				{
+					System: fhir.URI(colorSystem),
+					Concept: []*vspb.ValueSet_Compose_ConceptSet_ConceptReference{
+						{Code: fhir.Code("warm")},
+						{Code: fhir.Code("red")},
+					},
+				},
+			},
+		},
+	}
+	provider, err := terminology.NewMemory(codeSystem, valueSet)
+	if err != nil {
+		t.Fatalf("NewMemory() returned unexpected error: %v", err)
+	}
+	return provider
+}
+
+func TestMemberOf(t *testing.T) {
+	provider := newTerminology(t)
+	warm := exprtest.Return(system.String("http://example.com/ValueSet/warm"))
+	red := fhir.Coding(colorSystem, "red")
+	blue := fhir.Coding(colorSystem, "blue")
+
+	testCases := []struct {
+		name     string
+		provider terminology.Provider
+		input    system.Collection
+		args     []expr.Expression
+		want     system.Collection
+		wantErr  bool
+	}{
+		{
+			name:     "Coding is member",
+			provider: provider,
+			input:    system.Collection{red},
+			args:     []expr.Expression{warm},
+			want:     system.Collection{system.Boolean(true)},
+		},
+		{
+			name:     "Coding is not member",
+			provider: provider,
+			input:    system.Collection{blue},
+			args:     []expr.Expression{warm},
+			want:     system.Collection{system.Boolean(false)},
+		},
+		{
+			name:     "CodeableConcept with member coding",
+			provider: provider,
+			input:    system.Collection{fhir.CodeableConcept("color", blue, red)},
+			args:     []expr.Expression{warm},
+			want:     system.Collection{system.Boolean(true)},
+		},
+		{
+			name:     "code is member",
+			provider: provider,
+			input:    system.Collection{fhir.Code("warm")},
+			args:     []expr.Expression{warm},
+			want:     system.Collection{system.Boolean(true)},
+		},
+		{
+			name:     "string code is member",
+			provider: provider,
+			input:    system.Collection{system.String("red")},
+			args:     []expr.Expression{warm},
+			want:     system.Collection{system.Boolean(true)},
+		},
+		{
+			name:     "unknown value set",
+			provider: provider,
+			input:    system.Collection{red},
+			args:     []expr.Expression{exprtest.Return(system.String("http://example.com/ValueSet/unknown"))},
+			want:     system.Collection{},
+		},
+		{
+			name:     "empty input",
+			provider: provider,
+			args:     []expr.Expression{warm},
+			want:     system.Collection{},
+		},
+		{
+			name:     "multiple inputs",
+			provider: provider,
+			input:    system.Collection{red, red},
+			args:     []expr.Expression{warm},
+			want:     system.Collection{},
+		},
+		{
+			name:  "no terminology provider",
+			input: system.Collection{red},
+			args:  []expr.Expression{warm},
+			want:  system.Collection{},
+		},
+		{
+			name:     "value set is not a string",
+			provider: provider,
+			input:    system.Collection{red},
+			args:     []expr.Expression{exprtest.Return(system.Integer(1))},
+			wantErr:  true,
+		},
+		{
+			name:     "wrong arity",
+			provider: provider,
+			input:    system.Collection{red},
+			wantErr:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := &expr.Context{Terminology: tc.provider}
+
+			got, err := impl.MemberOf(ctx, tc.input, tc.args...)
+
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("MemberOf() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("MemberOf() returned unexpected diff (-want, +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSubsumes(t *testing.T) {
+	provider := newTerminology(t)
+	warm := fhir.Coding(colorSystem, "warm")
+	red := fhir.Coding(colorSystem, "red")
+	blue := fhir.Coding(colorSystem, "blue")
+
+	testCases := []struct {
+		name           string
+		input          system.Collection
+		arg            system.Collection
+		wantSubsumes   system.Collection
+		wantSubsumedBy system.Collection
+	}{
+		{
+			name:           "ancestor",
+			input:          system.Collection{warm},
+			arg:            system.Collection{red},
+			wantSubsumes:   system.Collection{system.Boolean(true)},
+			wantSubsumedBy: system.Collection{system.Boolean(false)},
+		},
+		{
+			name:           "same concept",
+			input:          system.Collection{red},
+			arg:            system.Collection{red},
+			wantSubsumes:   system.Collection{system.Boolean(true)},
+			wantSubsumedBy: system.Collection{system.Boolean(true)},
+		},
+		{
+			name:           "unrelated concepts",
+			input:          system.Collection{warm},
+			arg:            system.Collection{blue},
+			wantSubsumes:   system.Collection{system.Boolean(false)},
+			wantSubsumedBy: system.Collection{system.Boolean(false)},
+		},
+		{
+			name:           "CodeableConcepts",
+			input:          system.Collection{fhir.CodeableConcept("", blue, warm)},
+			arg:            system.Collection{fhir.CodeableConcept("", red)},
+			wantSubsumes:   system.Collection{system.Boolean(true)},
+			wantSubsumedBy: system.Collection{system.Boolean(false)},
+		},
+		{
+			name:           "unrelated code systems",
+			input:          system.Collection{warm},
+			arg:            system.Collection{fhir.Coding("http://example.com/other", "red")},
+			wantSubsumes:   system.Collection{},
+			wantSubsumedBy: system.Collection{},
+		},
+		{
+			name:           "empty argument",
+			input:          system.Collection{warm},
+			wantSubsumes:   system.Collection{},
+			wantSubsumedBy: system.Collection{},
+		},
+		{
+			name:           "multiple inputs",
+			input:          system.Collection{warm, red},
+			arg:            system.Collection{red},
+			wantSubsumes:   system.Collection{},
+			wantSubsumedBy: system.Collection{},
+		},
+		{
+			name:           "non-coding argument",
+			input:          system.Collection{warm},
+			arg:            system.Collection{system.Integer(1)},
+			wantSubsumes:   system.Collection{},
+			wantSubsumedBy: system.Collection{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := &expr.Context{Terminology: provider}
+			arg := exprtest.Return(tc.arg...)
+
+			gotSubsumes, err := impl.Subsumes(ctx, tc.input, arg)
+			if err != nil {
+				t.Fatalf("Subsumes() returned unexpected error: %v", err)
+			}
+			gotSubsumedBy, err := impl.SubsumedBy(ctx, tc.input, arg)
+			if err != nil {
+				t.Fatalf("SubsumedBy() returned unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.wantSubsumes, gotSubsumes, protocmp.Transform()); diff != "" {
+				t.Errorf("Subsumes() returned unexpected diff (-want, +got)\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantSubsumedBy, gotSubsumedBy, protocmp.Transform()); diff != "" {
+				t.Errorf("SubsumedBy() returned unexpected diff (-want, +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSubsumes_WrongArity_ReturnsError(t *testing.T) {
+	ctx := &expr.Context{Terminology: newTerminology(t)}
+	input := system.Collection{fhir.Coding(colorSystem, "warm")}
+
+	if _, err := impl.Subsumes(ctx, input); !errors.Is(err, impl.ErrWrongArity) {
+		t.Errorf("Subsumes() got error %v, want %v", err, impl.ErrWrongArity)
+	}
+	if _, err := impl.SubsumedBy(ctx, input); !errors.Is(err, impl.ErrWrongArity) {
+		t.Errorf("SubsumedBy() got error %v, want %v", err, impl.ErrWrongArity)
+	}
+}
+
+type profileValidatorFunc func(fhir.Base, string) (bool, error)
+
+func (f profileValidatorFunc) ConformsTo(element fhir.Base, structure string) (bool, error) {
+	return f(element, structure)
+}
+
+func TestConformsTo(t *testing.T) {
+	const usCorePatient = "http://hl7.org/fhir/us/core/StructureDefinition/us-core-patient"
+	patient := &ppb.Patient{
+		Meta: &dtpb.Meta{Profile: []*dtpb.Canonical{{Value: usCorePatient}}},
+	}
+	structure := exprtest.Return(system.String(usCorePatient))
+	errSome := errors.New("some error")
+
+	testCases := []struct {
+		name      string
+		validator profile.Validator
+		input     system.Collection
+		args      []expr.Expression
+		want      system.Collection
+		wantErr   error
+	}{
+		{
+			name:  "defaults to claimed profiles",
+			input: system.Collection{patient},
+			args:  []expr.Expression{structure},
+			want:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:  "unclaimed profile",
+			input: system.Collection{&ppb.Patient{}},
+			args:  []expr.Expression{structure},
+			want:  system.Collection{system.Boolean(false)},
+		},
+		{
+			name: "uses provided validator",
+			validator: profileValidatorFunc(func(fhir.Base, string) (bool, error) {
+				return false, nil
+			}),
+			input: system.Collection{patient},
+			args:  []expr.Expression{structure},
+			want:  system.Collection{system.Boolean(false)},
+		},
+		{
+			name: "propagates validator error",
+			validator: profileValidatorFunc(func(fhir.Base, string) (bool, error) {
+				return false, errSome
+			}),
+			input:   system.Collection{patient},
+			args:    []expr.Expression{structure},
+			wantErr: errSome,
+		},
+		{
+			name:  "system type does not conform",
+			input: system.Collection{system.String(usCorePatient)},
+			args:  []expr.Expression{structure},
+			want:  system.Collection{system.Boolean(false)},
+		},
+		{
+			name: "empty input",
+			args: []expr.Expression{structure},
+			want: system.Collection{},
+		},
+		{
+			name:    "multiple inputs",
+			input:   system.Collection{patient, patient},
+			args:    []expr.Expression{structure},
+			wantErr: expr.ErrNotSingleton,
+		},
+		{
+			name:    "wrong arity",
+			input:   system.Collection{patient},
+			wantErr: impl.ErrWrongArity,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := &expr.Context{ProfileValidator: tc.validator}
+
+			got, err := impl.ConformsTo(ctx, tc.input, tc.args...)
+
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("ConformsTo() got error %v, want %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("ConformsTo() returned unexpected diff (-want, +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestHasValue_GetValue(t *testing.T) {
+	absent := extension.New("http://hl7.org/fhir/StructureDefinition/data-absent-reason", fhir.Code("unknown"))
+	valueless := &dtpb.Date{Extension: []*dtpb.Extension{absent}}
+
+	testCases := []struct {
+		name         string
+		input        system.Collection
+		wantHasValue system.Collection
+		wantGetValue system.Collection
+	}{
+		{
+			name:         "primitive with value",
+			input:        system.Collection{fhir.String("abc")},
+			wantHasValue: system.Collection{system.Boolean(true)},
+			wantGetValue: system.Collection{system.String("abc")},
+		},
+		{
+			name:         "primitive with value and extension",
+			input:        system.Collection{&dtpb.String{Value: "abc", Extension: []*dtpb.Extension{absent}}},
+			wantHasValue: system.Collection{system.Boolean(true)},
+			wantGetValue: system.Collection{system.String("abc")},
+		},
+		{
+			name:         "primitive with only extension",
+			input:        system.Collection{valueless},
+			wantHasValue: system.Collection{system.Boolean(false)},
+			wantGetValue: system.Collection{},
+		},
+		{
+			name:         "complex type",
+			input:        system.Collection{&dtpb.HumanName{}},
+			wantHasValue: system.Collection{system.Boolean(false)},
+			wantGetValue: system.Collection{},
+		},
+		{
+			name:         "empty input",
+			wantHasValue: system.Collection{system.Boolean(false)},
+			wantGetValue: system.Collection{},
+		},
+		{
+			name:         "multiple inputs",
+			input:        system.Collection{fhir.String("abc"), fhir.String("def")},
+			wantHasValue: system.Collection{system.Boolean(false)},
+			wantGetValue: system.Collection{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotHasValue, err := impl.HasValue(&expr.Context{}, tc.input)
+			if err != nil {
+				t.Fatalf("HasValue() returned unexpected error: %v", err)
+			}
+			gotGetValue, err := impl.GetValue(&expr.Context{}, tc.input)
+			if err != nil {
+				t.Fatalf("GetValue() returned unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.wantHasValue, gotHasValue); diff != "" {
+				t.Errorf("HasValue() returned unexpected diff (-want, +got)\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantGetValue, gotGetValue); diff != "" {
+				t.Errorf("GetValue() returned unexpected diff (-want, +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestHasValue_GetValue_WrongArity_ReturnsError(t *testing.T) {
+	input := system.Collection{fhir.String("abc")}
+	arg := exprtest.Return(system.String("abc"))
+
+	if _, err := impl.HasValue(&expr.Context{}, input, arg); !errors.Is(err, impl.ErrWrongArity) {
+		t.Errorf("HasValue() got error %v, want %v", err, impl.ErrWrongArity)
+	}
+	if _, err := impl.GetValue(&expr.Context{}, input, arg); !errors.Is(err, impl.ErrWrongArity) {
+		t.Errorf("GetValue() got error %v, want %v", err, impl.ErrWrongArity)
+	}
+}
+
+func TestHtmlChecks(t *testing.T) {
+	testCases := []struct {
+		name  string
+		xhtml string
+		want  bool
+	}{
+		{"simple narrative", `<div xmlns="http://www.w3.org/1999/xhtml">Some text</div>`, true},
+		{"formatted narrative", `<div xmlns="http://www.w3.org/1999/xhtml" xml:lang="en"><p class="name">Peter <b>James</b></p><table border="1"><tr><td colspan="2">1</td></tr></table></div>`, true},
+		{"entities", `<div xmlns="http://www.w3.org/1999/xhtml">A&nbsp;B &amp; C</div>`, true},
+		{"image as only content", `<div xmlns="http://www.w3.org/1999/xhtml"><img src="#photo" alt=""/></div>`, true},
+		{"link", `<div xmlns="http://www.w3.org/1999/xhtml"><a href="http://example.com">link</a></div>`, true},
+		{"missing namespace", `<div>Some text</div>`, false},
+		{"root is not div", `<p xmlns="http://www.w3.org/1999/xhtml">Some text</p>`, false},
+		{"multiple roots", `<div xmlns="http://www.w3.org/1999/xhtml">a</div><div xmlns="http://www.w3.org/1999/xhtml">b</div>`, false},
+		{"text outside root", `<div xmlns="http://www.w3.org/1999/xhtml">a</div>b`, false},
+		{"empty content", `<div xmlns="http://www.w3.org/1999/xhtml"></div>`, false},
+		{"whitespace content", `<div xmlns="http://www.w3.org/1999/xhtml"> <p>  </p> </div>`, false},
+		{"script", `<div xmlns="http://www.w3.org/1999/xhtml">a<script>alert(1)</script></div>`, false},
+		{"form", `<div xmlns="http://www.w3.org/1999/xhtml"><form><input type="text"/></form></div>`, false},
+		{"iframe", `<div xmlns="http://www.w3.org/1999/xhtml"><iframe src="http://example.com"/></div>`, false},
+		{"event handler", `<div xmlns="http://www.w3.org/1999/xhtml"><p onclick="alert(1)">a</p></div>`, false},
+		{"script url", `<div xmlns="http://www.w3.org/1999/xhtml"><a href=" JavaScript:alert(1)">a</a></div>`, false},
+		{"element from other namespace", `<div xmlns="http://www.w3.org/1999/xhtml"><svg xmlns="http://www.w3.org/2000/svg">a</svg></div>`, false},
+		{"malformed", `<div xmlns="http://www.w3.org/1999/xhtml"><p>a</div>`, false},
+		{"doctype", `<!DOCTYPE html><div xmlns="http://www.w3.org/1999/xhtml">a</div>`, false},
+		{"empty", ``, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			input := system.Collection{&dtpb.Xhtml{Value: tc.xhtml}}
+
+			got, err := impl.HtmlChecks(&expr.Context{}, input)
+			if err != nil {
+				t.Fatalf("HtmlChecks() returned unexpected error: %v", err)
+			}
+
+			want := system.Collection{system.Boolean(tc.want)}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("HtmlChecks(%v) returned unexpected diff (-want, +got)\n%s", tc.xhtml, diff)
+			}
+		})
+	}
+}
+
+func TestHtmlChecks_InvalidInput(t *testing.T) {
+	xhtml := &dtpb.Xhtml{Value: `<div xmlns="http://www.w3.org/1999/xhtml">a</div>`}
+
+	testCases := []struct {
+		name    string
+		input   system.Collection
+		args    []expr.Expression
+		want    system.Collection
+		wantErr error
+	}{
+		{
+			name: "empty input",
+			want: system.Collection{},
+		},
+		{
+			name:  "non-XHTML input",
+			input: system.Collection{fhir.String(xhtml.GetValue())},
+			want:  system.Collection{system.Boolean(false)},
+		},
+		{
+			name:    "multiple inputs",
+			input:   system.Collection{xhtml, xhtml},
+			wantErr: expr.ErrNotSingleton,
+		},
+		{
+			name:    "wrong arity",
+			input:   system.Collection{xhtml},
+			args:    []expr.Expression{exprtest.Return(system.Boolean(true))},
+			wantErr: impl.ErrWrongArity,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := impl.HtmlChecks(&expr.Context{}, tc.input, tc.args...)
+
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("HtmlChecks() got error %v, want %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("HtmlChecks() returned unexpected diff (-want, +got)\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package impl
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/fhir-fli/fhirpath-go/fhirpath/internal/expr"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/system"
+)
+
+// sortKey is a key of the sort() function, evaluated against each item of the
+// input collection.
+type sortKey struct {
+	expr       expr.Expression
+	descending bool
+}
+
+// Sort returns the input collection ordered by the sort keys args, each of
+// which is evaluated against every item with $this bound to the item. If no
+// keys are given, the items are ordered by their own values. A key followed by
+// the desc keyword, as in sort($this desc), orders the items in descending
+// order. Items with an empty key are ordered before all others in ascending
+// order, and after all others in descending order. Items with equal keys
+// retain their relative order.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/
+func Sort(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	keys := []sortKey{{expr: &expr.ThisExpression{}}}
+	if len(args) > 0 {
+		keys = make([]sortKey, len(args))
+		for i, arg := range args {
+			if key, ok := arg.(*expr.SortKeyExpression); ok {
+				keys[i] = sortKey{expr: key.Expr, descending: key.Descending}
+				continue
+			}
+			keys[i] = sortKey{expr: arg}
+		}
+	}
+
+	values := make([][]system.Any, len(input))
+	for i, item := range input {
+		values[i] = make([]system.Any, len(keys))
+		for k, key := range keys {
+			value, err := sortValue(ctx.WithIteration(expr.Iteration{This: item, Index: i}), item, key.expr)
+			if err != nil {
+				return nil, err
+			}
+			values[i][k] = value
+		}
+	}
+
+	order := make([]int, len(input))
+	for i := range order {
+		order[i] = i
+	}
+	var sortErr error
+	sort.SliceStable(order, func(a, b int) bool {
+		for k, key := range keys {
+			result, err := compareSortValues(values[order[a]][k], values[order[b]][k])
+			if err != nil {
+				sortErr = err
+				return false
+			}
+			if key.descending {
+				result = -result
+			}
+			if result != 0 {
+				return result < 0
+			}
+		}
+		return false
+	})
+	if sortErr != nil {
+		return nil, sortErr
+	}
+
+	result := make(system.Collection, len(input))
+	for i, index := range order {
+		result[i] = input[index]
+	}
+	return result, nil
+}
+
+// sortValue evaluates the sort key for an item, returning nil if the key is
+// empty.
+func sortValue(ctx *expr.Context, item any, key expr.Expression) (system.Any, error) {
+	output, err := key.Evaluate(ctx, system.Collection{item})
+	if err != nil {
+		return nil, err
+	}
+	if len(output) == 0 {
+		return nil, nil
+	}
+	if len(output) > 1 {
+		return nil, fmt.Errorf("%w: sort key evaluated to %v items", expr.ErrNotSingleton, len(output))
+	}
+	value, err := system.From(output[0])
+	if errors.Is(err, system.ErrNoValue) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("evaluating sort key: %w", err)
+	}
+	return value, nil
+}
+
+// compareSortValues returns a negative number if lhs orders before rhs, a
+// positive number if it orders after rhs, and zero if they are equal or are of
+// different precisions. Empty (nil) values order before all others.
+func compareSortValues(lhs, rhs system.Any) (int, error) {
+	switch {
+	case lhs == nil && rhs == nil:
+		return 0, nil
+	case lhs == nil:
+		return -1, nil
+	case rhs == nil:
+		return 1, nil
+	}
+	lhs, rhs = system.Normalize(lhs, rhs), system.Normalize(rhs, lhs)
+	less, err := lhs.Less(rhs)
+	if errors.Is(err, system.ErrMismatchedPrecision) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("comparing sort keys: %w", err)
+	}
+	if less {
+		return -1, nil
+	}
+	if greater, err := rhs.Less(lhs); err == nil && bool(greater) {
+		return 1, nil
+	}
+	return 0, nil
+}
@@ -0,0 +1,130 @@
+package impl_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/fhir-fli/fhirpath-go/fhir"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/internal/expr"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/internal/expr/exprtest"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/internal/funcs/impl"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/system"
+	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+func TestSort(t *testing.T) {
+	chuSenpai := &dtpb.HumanName{Family: fhir.String("Chu"), Given: []*dtpb.String{fhir.String("Senpai")}}
+	chuKang := &dtpb.HumanName{Family: fhir.String("Chu"), Given: []*dtpb.String{fhir.String("Kang")}}
+	leeMin := &dtpb.HumanName{Family: fhir.String("Lee"), Given: []*dtpb.String{fhir.String("Min")}}
+	noFamily := &dtpb.HumanName{Given: []*dtpb.String{fhir.String("Ana")}}
+	family := &expr.FieldExpression{FieldName: "family"}
+	given := &expr.FieldExpression{FieldName: "given"}
+
+	testCases := []struct {
+		name    string
+		input   system.Collection
+		args    []expr.Expression
+		want    system.Collection
+		wantErr bool
+	}{
+		{
+			name:  "empty input",
+			input: system.Collection{},
+			want:  system.Collection{},
+		},
+		{
+			name:  "sorts by value without keys",
+			input: system.Collection{system.Integer(3), system.Integer(1), system.Integer(2)},
+			want:  system.Collection{system.Integer(1), system.Integer(2), system.Integer(3)},
+		},
+		{
+			name:  "sorts FHIR primitives by value",
+			input: system.Collection{fhir.String("b"), fhir.String("c"), fhir.String("a")},
+			want:  system.Collection{fhir.String("a"), fhir.String("b"), fhir.String("c")},
+		},
+		{
+			name:  "sorts mixed numeric types",
+			input: system.Collection{system.MustParseDecimal("2.5"), system.Integer(1), system.Integer(3)},
+			want:  system.Collection{system.Integer(1), system.MustParseDecimal("2.5"), system.Integer(3)},
+		},
+		{
+			name:  "sorts descending key",
+			input: system.Collection{system.Integer(1), system.Integer(3), system.Integer(2)},
+			args:  []expr.Expression{&expr.SortKeyExpression{Expr: &expr.ThisExpression{}, Descending: true}},
+			want:  system.Collection{system.Integer(3), system.Integer(2), system.Integer(1)},
+		},
+		{
+			name:  "sorts ascending key",
+			input: system.Collection{system.Integer(1), system.Integer(3), system.Integer(2)},
+			args:  []expr.Expression{&expr.SortKeyExpression{Expr: &expr.ThisExpression{}}},
+			want:  system.Collection{system.Integer(1), system.Integer(2), system.Integer(3)},
+		},
+		{
+			name:  "sorts negated key by its value",
+			input: system.Collection{system.Integer(1), system.Integer(3), system.Integer(2)},
+			args:  []expr.Expression{&expr.SortKeyExpression{Expr: &expr.NegationExpression{Expr: &expr.ThisExpression{}}, Descending: true}},
+			want:  system.Collection{system.Integer(1), system.Integer(2), system.Integer(3)},
+		},
+		{
+			name:  "sorts by key expression",
+			input: system.Collection{leeMin, chuSenpai},
+			args:  []expr.Expression{family},
+			want:  system.Collection{chuSenpai, leeMin},
+		},
+		{
+			name:  "retains order of equal keys",
+			input: system.Collection{chuSenpai, leeMin, chuKang},
+			args:  []expr.Expression{family},
+			want:  system.Collection{chuSenpai, chuKang, leeMin},
+		},
+		{
+			name:  "breaks ties with subsequent keys",
+			input: system.Collection{chuSenpai, leeMin, chuKang},
+			args:  []expr.Expression{&expr.SortKeyExpression{Expr: family, Descending: true}, given},
+			want:  system.Collection{leeMin, chuKang, chuSenpai},
+		},
+		{
+			name:  "orders empty keys first when ascending",
+			input: system.Collection{leeMin, noFamily, chuKang},
+			args:  []expr.Expression{family},
+			want:  system.Collection{noFamily, chuKang, leeMin},
+		},
+		{
+			name:  "orders empty keys last when descending",
+			input: system.Collection{noFamily, chuKang, leeMin},
+			args:  []expr.Expression{&expr.SortKeyExpression{Expr: family, Descending: true}},
+			want:  system.Collection{leeMin, chuKang, noFamily},
+		},
+		{
+			name:    "raises error when key has multiple items",
+			input:   system.Collection{system.Integer(1), system.Integer(2)},
+			args:    []expr.Expression{exprtest.Return(system.Integer(1), system.Integer(2))},
+			wantErr: true,
+		},
+		{
+			name:    "raises error on incomparable keys",
+			input:   system.Collection{system.Integer(1), system.String("a")},
+			wantErr: true,
+		},
+		{
+			name:    "propagates key error",
+			input:   system.Collection{system.Integer(1)},
+			args:    []expr.Expression{exprtest.Error(errors.New("some error"))},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := impl.Sort(&expr.Context{}, tc.input, tc.args...)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Sort() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("Sort() returned unexpected diff (-want, +got)\n%s", diff)
+			}
+		})
+	}
+}
@@ -1,8 +1,13 @@
 package impl
 
 import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"html"
 	"regexp"
 	"strings"
 
@@ -10,7 +15,11 @@ import (
 	"github.com/fhir-fli/fhirpath-go/fhirpath/system"
 )
 
-var ErrInvalidRegex = errors.New("invalid regex")
+var (
+	ErrInvalidRegex    = errors.New("invalid regex")
+	ErrInvalidEncoding = errors.New("invalid encoding")
+	ErrInvalidEscaping = errors.New("invalid escaping")
+)
 
 // StartsWith returns true if the input string starts with the given prefix.
 func StartsWith(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
@@ -433,3 +442,308 @@ func ReplaceMatches(ctx *expr.Context, input system.Collection, args ...expr.Exp
 	result := system.String(re.ReplaceAllString(fullString, substitution))
 	return system.Collection{result}, nil
 }
+
+// LastIndexOf returns the 0-based index of the last position in which the
+// substring is found in the input string, or -1 if it is not found.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/#lastindexofsubstring--string--integer
+func LastIndexOf(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	// Validate single string input
+	if length := len(input); length > 1 {
+		return nil, fmt.Errorf("%w: input has length %v, expected 1", ErrWrongArity, length)
+	} else if length == 0 {
+		return system.Collection{}, nil
+	}
+	fullString, err := input.ToString()
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate single string argument
+	if length := len(args); length != 1 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 1", ErrWrongArity, length)
+	}
+	output, err := args[0].Evaluate(ctx, input)
+	if err != nil {
+		return nil, err
+	} else if length := len(output); length == 0 {
+		// Return empty for empty argument
+		return system.Collection{}, nil
+	} else if length > 1 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 1", ErrWrongArity, length)
+	}
+	substring, err := output.ToString()
+	if err != nil {
+		return nil, err
+	}
+
+	result := system.Integer(strings.LastIndex(fullString, substring))
+	return system.Collection{result}, nil
+}
+
+// MatchesFull returns true when the entire value matches the given regular
+// expression, rather than only a part of it.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/#matchesfullregex--string--boolean
+func MatchesFull(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	// Validate single string input
+	if length := len(input); length > 1 {
+		return nil, fmt.Errorf("%w: input has length %v, expected 1", ErrWrongArity, length)
+	} else if length == 0 {
+		return system.Collection{}, nil
+	}
+	fullString, err := input.ToString()
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate single string argument
+	if length := len(args); length != 1 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 1", ErrWrongArity, length)
+	}
+	output, err := args[0].Evaluate(ctx, input)
+	if err != nil {
+		return nil, err
+	} else if length := len(output); length == 0 {
+		return system.Collection{}, nil
+	} else if length != 1 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 1", ErrWrongArity, length)
+	}
+	regexString, err := output.ToString()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := regexp.Compile(regexString); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidRegex, regexString)
+	}
+	// Anchor the expression only once it is known to be valid, so that it can't
+	// close the non-capturing group itself.
+	re := regexp.MustCompile(`\A(?:` + regexString + `)\z`)
+
+	result := system.Boolean(re.MatchString(fullString))
+	return system.Collection{result}, nil
+}
+
+// Trim returns the input string with leading and trailing whitespace removed.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/#trim--string
+func Trim(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	// Validate single string input
+	if length := len(input); length > 1 {
+		return nil, fmt.Errorf("%w: input has length %v, expected 1", ErrWrongArity, length)
+	} else if length == 0 {
+		return system.Collection{}, nil
+	}
+	fullString, err := input.ToString()
+	if err != nil {
+		return nil, err
+	}
+
+	if length := len(args); length != 0 {
+		return nil, fmt.Errorf("%w, received %v arguments, expected 0", ErrWrongArity, length)
+	}
+
+	result := system.String(strings.TrimSpace(fullString))
+	return system.Collection{result}, nil
+}
+
+// Split returns the parts of the input string that are delimited by the
+// separator.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/#splitseparator-string--collection
+func Split(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	// Validate single string input
+	if length := len(input); length > 1 {
+		return nil, fmt.Errorf("%w: input has length %v, expected 1", ErrWrongArity, length)
+	} else if length == 0 {
+		return system.Collection{}, nil
+	}
+	fullString, err := input.ToString()
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate single string argument
+	if length := len(args); length != 1 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 1", ErrWrongArity, length)
+	}
+	output, err := args[0].Evaluate(ctx, input)
+	if err != nil {
+		return nil, err
+	} else if length := len(output); length == 0 {
+		return system.Collection{}, nil
+	} else if length > 1 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 1", ErrWrongArity, length)
+	}
+	separator, err := output.ToString()
+	if err != nil {
+		return nil, err
+	}
+
+	result := system.Collection{}
+	for _, part := range strings.Split(fullString, separator) {
+		result = append(result, system.String(part))
+	}
+	return result, nil
+}
+
+// Join returns the strings of the input collection concatenated with the
+// optional separator between each of them.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/#joinseparator-string--string
+func Join(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if len(input) == 0 {
+		return system.Collection{}, nil
+	}
+	parts := make([]string, 0, len(input))
+	for _, item := range input {
+		value, err := system.From(item)
+		if errors.Is(err, system.ErrNoValue) {
+			// Primitives with only extensions are treated as empty.
+			continue
+		}
+		part, ok := value.(system.String)
+		if err != nil || !ok {
+			return nil, fmt.Errorf("%w: join() input contains %T, expected string", ErrInvalidReturnType, item)
+		}
+		parts = append(parts, string(part))
+	}
+
+	// Validate optional string argument
+	if length := len(args); length > 1 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 0 or 1", ErrWrongArity, length)
+	}
+	var separator string
+	if len(args) == 1 {
+		output, err := args[0].Evaluate(ctx, input)
+		if err != nil {
+			return nil, err
+		} else if length := len(output); length > 1 {
+			return nil, fmt.Errorf("%w: received %v arguments, expected 1", ErrWrongArity, length)
+		} else if length == 1 {
+			if separator, err = output.ToString(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	result := system.String(strings.Join(parts, separator))
+	return system.Collection{result}, nil
+}
+
+// Encode returns the input string encoded in the given format, which is one
+// of hex, base64 or urlbase64.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/#encodeformat--string--string
+func Encode(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	return transcode(ctx, input, args, func(format, value string) (string, error) {
+		switch format {
+		case "hex":
+			return hex.EncodeToString([]byte(value)), nil
+		case "base64":
+			return base64.StdEncoding.EncodeToString([]byte(value)), nil
+		case "urlbase64":
+			return base64.URLEncoding.EncodeToString([]byte(value)), nil
+		}
+		return "", fmt.Errorf("%w: unsupported format '%s'", ErrInvalidEncoding, format)
+	})
+}
+
+// Decode returns the input string decoded from the given format, which is one
+// of hex, base64 or urlbase64.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/#decodeformat--string--string
+func Decode(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	return transcode(ctx, input, args, func(format, value string) (string, error) {
+		var decoded []byte
+		var err error
+		switch format {
+		case "hex":
+			decoded, err = hex.DecodeString(value)
+		case "base64":
+			decoded, err = base64.StdEncoding.DecodeString(value)
+		case "urlbase64":
+			decoded, err = base64.URLEncoding.DecodeString(value)
+		default:
+			return "", fmt.Errorf("%w: unsupported format '%s'", ErrInvalidEncoding, format)
+		}
+		if err != nil {
+			return "", fmt.Errorf("%w: decoding %s: %w", ErrInvalidEncoding, format, err)
+		}
+		return string(decoded), nil
+	})
+}
+
+// Escape returns the input string escaped for the given target, which is
+// either html or json.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/#escapetarget--string--string
+func Escape(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	return transcode(ctx, input, args, func(target, value string) (string, error) {
+		switch target {
+		case "html":
+			return html.EscapeString(value), nil
+		case "json":
+			var buf bytes.Buffer
+			encoder := json.NewEncoder(&buf)
+			encoder.SetEscapeHTML(false)
+			if err := encoder.Encode(value); err != nil {
+				return "", fmt.Errorf("%w: %w", ErrInvalidEscaping, err)
+			}
+			// Strip the enclosing quotes and the trailing newline.
+			quoted := strings.TrimSuffix(buf.String(), "\n")
+			return quoted[1 : len(quoted)-1], nil
+		}
+		return "", fmt.Errorf("%w: unsupported target '%s'", ErrInvalidEscaping, target)
+	})
+}
+
+// Unescape returns the input string with the escaping for the given target,
+// which is either html or json, reversed.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/#unescapetarget--string--string
+func Unescape(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	return transcode(ctx, input, args, func(target, value string) (string, error) {
+		switch target {
+		case "html":
+			return html.UnescapeString(value), nil
+		case "json":
+			var unescaped string
+			if err := json.Unmarshal([]byte(`"`+value+`"`), &unescaped); err != nil {
+				return "", fmt.Errorf("%w: %w", ErrInvalidEscaping, err)
+			}
+			return unescaped, nil
+		}
+		return "", fmt.Errorf("%w: unsupported target '%s'", ErrInvalidEscaping, target)
+	})
+}
+
+// transcode implements the functions that convert the input string to another
+// representation named by their single string argument, such as encode('hex').
+// The result is empty if either the input or the argument is empty.
+func transcode(ctx *expr.Context, input system.Collection, args []expr.Expression, convert func(string, string) (string, error)) (system.Collection, error) {
+	// Validate single string input
+	if length := len(input); length > 1 {
+		return nil, fmt.Errorf("%w: input has length %v, expected 1", ErrWrongArity, length)
+	} else if length == 0 {
+		return system.Collection{}, nil
+	}
+	fullString, err := input.ToString()
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate single string argument
+	if length := len(args); length != 1 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 1", ErrWrongArity, length)
+	}
+	output, err := args[0].Evaluate(ctx, input)
+	if err != nil {
+		return nil, err
+	} else if length := len(output); length == 0 {
+		return system.Collection{}, nil
+	} else if length > 1 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 1", ErrWrongArity, length)
+	}
+	format, err := output.ToString()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := convert(format, fullString)
+	if err != nil {
+		return nil, err
+	}
+	return system.Collection{system.String(result)}, nil
+}
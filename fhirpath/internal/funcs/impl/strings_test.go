@@ -3,9 +3,12 @@ package impl_test
 import (
 	"testing"
 
+	"github.com/fhir-fli/fhirpath-go/fhir"
 	"github.com/fhir-fli/fhirpath-go/fhirpath/internal/expr"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/internal/expr/exprtest"
 	"github.com/fhir-fli/fhirpath-go/fhirpath/internal/funcs/impl"
 	"github.com/fhir-fli/fhirpath-go/fhirpath/system"
+	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
 	"github.com/google/go-cmp/cmp"
 )
 
@@ -1146,3 +1149,466 @@ func TestReplaceMatches(t *testing.T) {
 		})
 	}
 }
+
+func TestLastIndexOf(t *testing.T) {
+	fullString := system.String("banana")
+
+	testCases := []struct {
+		name    string
+		input   system.Collection
+		args    []expr.Expression
+		want    system.Collection
+		wantErr bool
+	}{
+		{
+			name:  "returns empty for empty input",
+			input: system.Collection{},
+			args: []expr.Expression{
+				&expr.LiteralExpression{Literal: system.String("a")},
+			},
+			want: system.Collection{},
+		},
+		{
+			name:  "returns empty for empty arg",
+			input: system.Collection{fullString},
+			args:  []expr.Expression{exprtest.Return()},
+			want:  system.Collection{},
+		},
+		{
+			name:  "returns index of last match",
+			input: system.Collection{fullString},
+			args: []expr.Expression{
+				&expr.LiteralExpression{Literal: system.String("an")},
+			},
+			want: system.Collection{system.Integer(3)},
+		},
+		{
+			name:  "returns length for empty substring",
+			input: system.Collection{fullString},
+			args: []expr.Expression{
+				&expr.LiteralExpression{Literal: system.String("")},
+			},
+			want: system.Collection{system.Integer(6)},
+		},
+		{
+			name:  "returns -1 for no match",
+			input: system.Collection{fullString},
+			args: []expr.Expression{
+				&expr.LiteralExpression{Literal: system.String("x")},
+			},
+			want: system.Collection{system.Integer(-1)},
+		},
+		{
+			name:  "errors if input is not a string",
+			input: system.Collection{system.Integer(516)},
+			args: []expr.Expression{
+				&expr.LiteralExpression{Literal: system.String("a")},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := impl.LastIndexOf(&expr.Context{}, tc.input, tc.args...)
+
+			if gotErr := err != nil; tc.wantErr != gotErr {
+				t.Fatalf("LastIndexOf got unexpected error result: gotErr %v, wantErr %v, err: %v", gotErr, tc.wantErr, err)
+			}
+			if !cmp.Equal(tc.want, got) {
+				t.Errorf("LastIndexOf returned unexpected result: got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMatchesFull(t *testing.T) {
+	fullString := system.String("Lee Jieun")
+
+	testCases := []struct {
+		name    string
+		input   system.Collection
+		args    []expr.Expression
+		want    system.Collection
+		wantErr bool
+	}{
+		{
+			name:  "returns empty for empty input",
+			input: system.Collection{},
+			args: []expr.Expression{
+				&expr.LiteralExpression{Literal: system.String("Lee.*")},
+			},
+			want: system.Collection{},
+		},
+		{
+			name:  "returns true when entire string matches",
+			input: system.Collection{fullString},
+			args: []expr.Expression{
+				&expr.LiteralExpression{Literal: system.String("Lee.*")},
+			},
+			want: system.Collection{system.Boolean(true)},
+		},
+		{
+			name:  "returns false when only part of the string matches",
+			input: system.Collection{fullString},
+			args: []expr.Expression{
+				&expr.LiteralExpression{Literal: system.String("Lee")},
+			},
+			want: system.Collection{system.Boolean(false)},
+		},
+		{
+			name:  "anchors every alternative",
+			input: system.Collection{fullString},
+			args: []expr.Expression{
+				&expr.LiteralExpression{Literal: system.String("Lee|Lee Jieun")},
+			},
+			want: system.Collection{system.Boolean(true)},
+		},
+		{
+			name:  "errors on invalid regex",
+			input: system.Collection{fullString},
+			args: []expr.Expression{
+				&expr.LiteralExpression{Literal: system.String("Lee)|(.*")},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := impl.MatchesFull(&expr.Context{}, tc.input, tc.args...)
+
+			if gotErr := err != nil; tc.wantErr != gotErr {
+				t.Fatalf("MatchesFull got unexpected error result: gotErr %v, wantErr %v, err: %v", gotErr, tc.wantErr, err)
+			}
+			if !cmp.Equal(tc.want, got) {
+				t.Errorf("MatchesFull returned unexpected result: got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestTrim(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   system.Collection
+		want    system.Collection
+		wantErr bool
+	}{
+		{
+			name:  "returns empty for empty input",
+			input: system.Collection{},
+			want:  system.Collection{},
+		},
+		{
+			name:  "removes leading and trailing whitespace",
+			input: system.Collection{system.String(" \tLee Jieun\n")},
+			want:  system.Collection{system.String("Lee Jieun")},
+		},
+		{
+			name:  "trims FHIR code",
+			input: system.Collection{fhir.Code(" final ")},
+			want:  system.Collection{system.String("final")},
+		},
+		{
+			name:    "errors if input is not a string",
+			input:   system.Collection{system.Integer(516)},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := impl.Trim(&expr.Context{}, tc.input)
+
+			if gotErr := err != nil; tc.wantErr != gotErr {
+				t.Fatalf("Trim got unexpected error result: gotErr %v, wantErr %v, err: %v", gotErr, tc.wantErr, err)
+			}
+			if !cmp.Equal(tc.want, got) {
+				t.Errorf("Trim returned unexpected result: got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSplit(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   system.Collection
+		args    []expr.Expression
+		want    system.Collection
+		wantErr bool
+	}{
+		{
+			name:  "returns empty for empty input",
+			input: system.Collection{},
+			args: []expr.Expression{
+				&expr.LiteralExpression{Literal: system.String(",")},
+			},
+			want: system.Collection{},
+		},
+		{
+			name:  "returns empty for empty arg",
+			input: system.Collection{system.String("a,b")},
+			args:  []expr.Expression{exprtest.Return()},
+			want:  system.Collection{},
+		},
+		{
+			name:  "splits on separator",
+			input: system.Collection{system.String("a,b,,c")},
+			args: []expr.Expression{
+				&expr.LiteralExpression{Literal: system.String(",")},
+			},
+			want: system.Collection{system.String("a"), system.String("b"), system.String(""), system.String("c")},
+		},
+		{
+			name:  "returns input without separator",
+			input: system.Collection{fhir.URI("http://example.com")},
+			args: []expr.Expression{
+				&expr.LiteralExpression{Literal: system.String(",")},
+			},
+			want: system.Collection{system.String("http://example.com")},
+		},
+		{
+			name:    "errors if args length is not 1",
+			input:   system.Collection{system.String("a,b")},
+			args:    []expr.Expression{},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := impl.Split(&expr.Context{}, tc.input, tc.args...)
+
+			if gotErr := err != nil; tc.wantErr != gotErr {
+				t.Fatalf("Split got unexpected error result: gotErr %v, wantErr %v, err: %v", gotErr, tc.wantErr, err)
+			}
+			if !cmp.Equal(tc.want, got) {
+				t.Errorf("Split returned unexpected result: got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestJoin(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   system.Collection
+		args    []expr.Expression
+		want    system.Collection
+		wantErr bool
+	}{
+		{
+			name:  "returns empty for empty input",
+			input: system.Collection{},
+			want:  system.Collection{},
+		},
+		{
+			name:  "joins without separator",
+			input: system.Collection{system.String("a"), system.String("b")},
+			want:  system.Collection{system.String("ab")},
+		},
+		{
+			name:  "joins with separator",
+			input: system.Collection{system.String("a"), fhir.Code("b"), fhir.Markdown("c")},
+			args: []expr.Expression{
+				&expr.LiteralExpression{Literal: system.String(", ")},
+			},
+			want: system.Collection{system.String("a, b, c")},
+		},
+		{
+			name:  "joins without separator for empty arg",
+			input: system.Collection{system.String("a"), system.String("b")},
+			args:  []expr.Expression{exprtest.Return()},
+			want:  system.Collection{system.String("ab")},
+		},
+		{
+			name:    "errors if input contains non-string",
+			input:   system.Collection{system.String("a"), system.Integer(516)},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := impl.Join(&expr.Context{}, tc.input, tc.args...)
+
+			if gotErr := err != nil; tc.wantErr != gotErr {
+				t.Fatalf("Join got unexpected error result: gotErr %v, wantErr %v, err: %v", gotErr, tc.wantErr, err)
+			}
+			if !cmp.Equal(tc.want, got) {
+				t.Errorf("Join returned unexpected result: got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	testCases := []struct {
+		name    string
+		fn      func(*expr.Context, system.Collection, ...expr.Expression) (system.Collection, error)
+		input   system.Collection
+		format  system.Collection
+		want    system.Collection
+		wantErr bool
+	}{
+		{
+			name:   "encode returns empty for empty input",
+			fn:     impl.Encode,
+			input:  system.Collection{},
+			format: system.Collection{system.String("hex")},
+			want:   system.Collection{},
+		},
+		{
+			name:   "encode returns empty for empty format",
+			fn:     impl.Encode,
+			input:  system.Collection{system.String("test")},
+			format: system.Collection{},
+			want:   system.Collection{},
+		},
+		{
+			name:   "encodes hex",
+			fn:     impl.Encode,
+			input:  system.Collection{system.String("test")},
+			format: system.Collection{system.String("hex")},
+			want:   system.Collection{system.String("74657374")},
+		},
+		{
+			name:   "encodes base64",
+			fn:     impl.Encode,
+			input:  system.Collection{system.String("subjects?")},
+			format: system.Collection{system.String("base64")},
+			want:   system.Collection{system.String("c3ViamVjdHM/")},
+		},
+		{
+			name:   "encodes urlbase64",
+			fn:     impl.Encode,
+			input:  system.Collection{system.String("subjects?")},
+			format: system.Collection{system.String("urlbase64")},
+			want:   system.Collection{system.String("c3ViamVjdHM_")},
+		},
+		{
+			name:    "encode errors on unsupported format",
+			fn:      impl.Encode,
+			input:   system.Collection{system.String("test")},
+			format:  system.Collection{system.String("rot13")},
+			wantErr: true,
+		},
+		{
+			name:   "decodes hex",
+			fn:     impl.Decode,
+			input:  system.Collection{system.String("74657374")},
+			format: system.Collection{system.String("hex")},
+			want:   system.Collection{system.String("test")},
+		},
+		{
+			name:   "decodes base64Binary",
+			fn:     impl.Decode,
+			input:  system.Collection{&dtpb.Base64Binary{Value: []byte("test")}},
+			format: system.Collection{system.String("base64")},
+			want:   system.Collection{system.String("test")},
+		},
+		{
+			name:   "decodes urlbase64",
+			fn:     impl.Decode,
+			input:  system.Collection{system.String("c3ViamVjdHM_")},
+			format: system.Collection{system.String("urlbase64")},
+			want:   system.Collection{system.String("subjects?")},
+		},
+		{
+			name:    "decode errors on malformed input",
+			fn:      impl.Decode,
+			input:   system.Collection{system.String("zz")},
+			format:  system.Collection{system.String("hex")},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.fn(&expr.Context{}, tc.input, exprtest.Return(tc.format...))
+
+			if gotErr := err != nil; tc.wantErr != gotErr {
+				t.Fatalf("got unexpected error result: gotErr %v, wantErr %v, err: %v", gotErr, tc.wantErr, err)
+			}
+			if !cmp.Equal(tc.want, got) {
+				t.Errorf("returned unexpected result: got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestEscapeUnescape(t *testing.T) {
+	testCases := []struct {
+		name    string
+		fn      func(*expr.Context, system.Collection, ...expr.Expression) (system.Collection, error)
+		input   system.Collection
+		target  system.Collection
+		want    system.Collection
+		wantErr bool
+	}{
+		{
+			name:   "escape returns empty for empty input",
+			fn:     impl.Escape,
+			input:  system.Collection{},
+			target: system.Collection{system.String("html")},
+			want:   system.Collection{},
+		},
+		{
+			name:   "escapes html",
+			fn:     impl.Escape,
+			input:  system.Collection{system.String(`"1 < 5"`)},
+			target: system.Collection{system.String("html")},
+			want:   system.Collection{system.String("&#34;1 &lt; 5&#34;")},
+		},
+		{
+			name:   "escapes json",
+			fn:     impl.Escape,
+			input:  system.Collection{system.String("\"1 < 5\"\n")},
+			target: system.Collection{system.String("json")},
+			want:   system.Collection{system.String(`\"1 < 5\"\n`)},
+		},
+		{
+			name:    "escape errors on unsupported target",
+			fn:      impl.Escape,
+			input:   system.Collection{system.String("test")},
+			target:  system.Collection{system.String("xml")},
+			wantErr: true,
+		},
+		{
+			name:   "unescapes html",
+			fn:     impl.Unescape,
+			input:  system.Collection{system.String("&quot;1 &lt; 5&quot;")},
+			target: system.Collection{system.String("html")},
+			want:   system.Collection{system.String(`"1 < 5"`)},
+		},
+		{
+			name:   "unescapes json",
+			fn:     impl.Unescape,
+			input:  system.Collection{system.String(`\"1 < 5\"`)},
+			target: system.Collection{system.String("json")},
+			want:   system.Collection{system.String(`"1 < 5"`)},
+		},
+		{
+			name:    "unescape errors on malformed json",
+			fn:      impl.Unescape,
+			input:   system.Collection{system.String(`\x`)},
+			target:  system.Collection{system.String("json")},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.fn(&expr.Context{}, tc.input, exprtest.Return(tc.target...))
+
+			if gotErr := err != nil; tc.wantErr != gotErr {
+				t.Fatalf("got unexpected error result: gotErr %v, wantErr %v, err: %v", gotErr, tc.wantErr, err)
+			}
+			if !cmp.Equal(tc.want, got) {
+				t.Errorf("returned unexpected result: got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
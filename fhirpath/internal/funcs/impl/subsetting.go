@@ -19,6 +19,20 @@ func First(ctx *expr.Context, input system.Collection, args ...expr.Expression)
 	return system.Collection{input[0]}, nil
 }
 
+// Single returns the single item in the input if there is just one item.
+// If the input collection is empty, the result is empty. If there are multiple
+// items, an error is raised.
+// FHIRPath docs here: https://hl7.org/fhirpath/N1/#single-collection
+func Single(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if len(input) > 1 {
+		return nil, fmt.Errorf("%w: input contains %v elements", expr.ErrNotSingleton, len(input))
+	}
+	if input.IsEmpty() {
+		return system.Collection{}, nil
+	}
+	return system.Collection{input[0]}, nil
+}
+
 // Last Returns a collection containing only the last item in the input collection.
 // Will return an empty collection if the input collection has no items.
 // FHIRPath docs here: https://hl7.org/fhirpath/N1/#last-collection
@@ -1,6 +1,7 @@
 package impl_test
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/fhir-fli/fhirpath-go/fhir"
@@ -54,6 +55,46 @@ func TestFirst(t *testing.T) {
 	}
 }
 
+func TestSingle(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   system.Collection
+		want    system.Collection
+		wantErr bool
+	}{
+		{
+			name:  "returns an empty collection if input is empty",
+			input: system.Collection{},
+			want:  system.Collection{},
+		},
+		{
+			name:  "returns the single item",
+			input: system.Collection{fhir.String("a")},
+			want:  system.Collection{fhir.String("a")},
+		},
+		{
+			name:    "raises error if input contains multiple items",
+			input:   system.Collection{system.Integer(1), system.Integer(2)},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := impl.Single(&expr.Context{}, tc.input)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Single() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if tc.wantErr && !errors.Is(err, expr.ErrNotSingleton) {
+				t.Errorf("Single() error = %v, want %v", err, expr.ErrNotSingleton)
+			}
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("Single() returned unexpected diff (-want, +got)\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestLast(t *testing.T) {
 	testCases := []struct {
 		name    string
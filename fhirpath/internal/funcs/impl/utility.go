@@ -1,10 +1,88 @@
 package impl
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/fhir-fli/fhirpath-go/fhirpath/internal/expr"
 	"github.com/fhir-fli/fhirpath-go/fhirpath/system"
 )
 
+// Trace sends the input collection to the trace sink of the context, under the
+// name given by args[0], and returns the input unchanged. If the projection
+// args[1] is given, its result is traced instead of the input. The arguments
+// are evaluated and validated whether or not a trace sink is configured.
+// FHIRPath docs here: https://hl7.org/fhirpath/N1/#tracename-string-projection-expression-collection
+func Trace(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if len(args) != 1 && len(args) != 2 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 1 or 2", ErrWrongArity, len(args))
+	}
+	nameResult, err := args[0].Evaluate(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	name, err := nameResult.ToString()
+	if err != nil {
+		return nil, fmt.Errorf("evaluating trace name: %w", err)
+	}
+	traced := input
+	if len(args) == 2 {
+		if traced, err = args[1].Evaluate(ctx, input); err != nil {
+			return nil, err
+		}
+	}
+	if ctx.TraceSink != nil {
+		ctx.TraceSink(name, traced)
+	}
+	return input, nil
+}
+
+// DefineVariable returns the value of the variable named by args[0], which is
+// the result of evaluating args[1] against the input, or the input itself if
+// args[1] is not given. The variable is defined in the scope of the invocation
+// by the enclosing expr.DefineVariableExpression, which returns the input
+// unchanged. The variable is referenced as %name by the invocations that
+// follow the definition; its scope is resolved when the expression is
+// compiled.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/#utility-functions
+func DefineVariable(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if len(args) != 1 && len(args) != 2 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 1 or 2", ErrWrongArity, len(args))
+	}
+	nameResult, err := args[0].Evaluate(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := nameResult.ToString(); err != nil {
+		return nil, fmt.Errorf("evaluating variable name: %w", err)
+	}
+	if len(args) == 2 {
+		return args[1].Evaluate(ctx, input)
+	}
+	return input, nil
+}
+
+// Coalesce returns the result of the first of the expressions args that
+// evaluates to a non-empty collection, or an empty collection if they are all
+// empty. The expressions are evaluated in order, and those following the first
+// non-empty result are not evaluated.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/
+func Coalesce(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected at least 1", ErrWrongArity, len(args))
+	}
+	for _, arg := range args {
+		result, err := arg.Evaluate(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		if !result.IsEmpty() {
+			return result, nil
+		}
+	}
+	return system.Collection{}, nil
+}
+
 // TimeOfDay returns the current time as a system.Time object.
 func TimeOfDay(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
 	timeString := ctx.Now.Format("15:04:05.000")
@@ -22,3 +100,160 @@ func Now(ctx *expr.Context, input system.Collection, args ...expr.Expression) (s
 	dateTimeString := ctx.Now.Format("2006-01-02T15:04:05.000Z07:00")
 	return system.Collection{system.MustParseDateTime(dateTimeString)}, nil
 }
+
+// Default precisions of the boundary functions, when no precision is given.
+const (
+	defaultDecimalPrecision  = 8
+	defaultDatePrecision     = 8
+	defaultDateTimePrecision = 17
+	defaultTimePrecision     = 9
+)
+
+// LowBoundary returns the least possible value of the input to the precision
+// given by args[0], taking into account the precision of the input. If no
+// precision is given, the greatest precision of the input type is used. The
+// result is empty if the precision is not supported for the input type.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/#utility-functions
+func LowBoundary(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	return boundary(ctx, input, args, false)
+}
+
+// HighBoundary returns the greatest possible value of the input to the
+// precision given by args[0], taking into account the precision of the input.
+// If no precision is given, the greatest precision of the input type is used.
+// The result is empty if the precision is not supported for the input type.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/#utility-functions
+func HighBoundary(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	return boundary(ctx, input, args, true)
+}
+
+// boundary implements LowBoundary and HighBoundary, returning the high
+// boundary if high is set.
+func boundary(ctx *expr.Context, input system.Collection, args []expr.Expression, high bool) (system.Collection, error) {
+	if len(args) > 1 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 0 or 1", ErrWrongArity, len(args))
+	}
+	value, err := singletonInput(input)
+	if value == nil || err != nil {
+		return system.Collection{}, err
+	}
+	var precision int
+	hasPrecision := len(args) == 1
+	if hasPrecision {
+		precisionResult, err := args[0].Evaluate(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		if precisionResult.IsEmpty() {
+			return system.Collection{}, nil
+		}
+		p, err := precisionResult.ToInt32()
+		if err != nil {
+			return nil, fmt.Errorf("evaluating boundary precision: %w", err)
+		}
+		if p < 0 {
+			return system.Collection{}, nil
+		}
+		precision = int(p)
+	}
+
+	if integer, ok := value.(system.Integer); ok {
+		value = system.Normalize(integer, system.Decimal{})
+	}
+
+	var result system.Any
+	var ok bool
+	switch v := value.(type) {
+	case system.Decimal:
+		if !hasPrecision {
+			precision = defaultDecimalPrecision
+		}
+		if high {
+			result, ok = v.HighBoundary(precision)
+		} else {
+			result, ok = v.LowBoundary(precision)
+		}
+	case system.Date:
+		if !hasPrecision {
+			precision = defaultDatePrecision
+		}
+		if high {
+			result, ok = v.HighBoundary(precision)
+		} else {
+			result, ok = v.LowBoundary(precision)
+		}
+	case system.DateTime:
+		if !hasPrecision {
+			precision = defaultDateTimePrecision
+		}
+		if high {
+			result, ok = v.HighBoundary(precision)
+		} else {
+			result, ok = v.LowBoundary(precision)
+		}
+	case system.Time:
+		if !hasPrecision {
+			precision = defaultTimePrecision
+		}
+		if high {
+			result, ok = v.HighBoundary(precision)
+		} else {
+			result, ok = v.LowBoundary(precision)
+		}
+	default:
+		return nil, fmt.Errorf("%w: boundaries are not defined for %s", expr.ErrInvalidType, value.Name())
+	}
+	if !ok {
+		return system.Collection{}, nil
+	}
+	return system.Collection{result}, nil
+}
+
+// Precision returns the precision of the input in digits. For Decimals this is
+// the number of decimal places including trailing zeroes, and for Dates,
+// DateTimes and Times it is the number of digits of the most precise
+// component, such that @2014-01-05T10:30:00.000 has a precision of 17.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/#utility-functions
+func Precision(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if len(args) != 0 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 0", ErrWrongArity, len(args))
+	}
+	value, err := singletonInput(input)
+	if value == nil || err != nil {
+		return system.Collection{}, err
+	}
+	switch v := value.(type) {
+	case system.Integer:
+		return system.Collection{system.Integer(0)}, nil
+	case system.Decimal:
+		return system.Collection{system.Integer(v.Precision())}, nil
+	case system.Date:
+		return system.Collection{system.Integer(v.Precision())}, nil
+	case system.DateTime:
+		return system.Collection{system.Integer(v.Precision())}, nil
+	case system.Time:
+		return system.Collection{system.Integer(v.Precision())}, nil
+	default:
+		return nil, fmt.Errorf("%w: precision is not defined for %s", expr.ErrInvalidType, value.Name())
+	}
+}
+
+// singletonInput returns the singleton input of the boundary, precision and
+// date/time component functions as a system type, or nil if the input is empty
+// or has no value.
+func singletonInput(input system.Collection) (system.Any, error) {
+	if input.IsEmpty() {
+		return nil, nil
+	}
+	if !input.IsSingleton() {
+		return nil, fmt.Errorf("%w: received %v items", expr.ErrNotSingleton, len(input))
+	}
+	value, err := system.From(input[0])
+	if errors.Is(err, system.ErrNoValue) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
@@ -1,15 +1,127 @@
 package impl_test
 
 import (
+	"errors"
 	"testing"
 	"time"
 
+	"github.com/fhir-fli/fhirpath-go/fhir"
 	"github.com/fhir-fli/fhirpath-go/fhirpath/internal/expr"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/internal/expr/exprtest"
 	"github.com/fhir-fli/fhirpath-go/fhirpath/internal/funcs/impl"
 	"github.com/fhir-fli/fhirpath-go/fhirpath/system"
+	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
 	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
 )
 
+func TestTrace(t *testing.T) {
+	type trace struct {
+		name       string
+		collection system.Collection
+	}
+	input := system.Collection{system.Integer(1), system.Integer(2)}
+
+	testCases := []struct {
+		name       string
+		args       []expr.Expression
+		wantTraces []trace
+		wantErr    bool
+	}{
+		{
+			name:       "traces input",
+			args:       []expr.Expression{exprtest.Return(system.String("input"))},
+			wantTraces: []trace{{"input", input}},
+		},
+		{
+			name:       "traces projection",
+			args:       []expr.Expression{exprtest.Return(fhir.String("projected")), exprtest.Return(system.Integer(3))},
+			wantTraces: []trace{{"projected", system.Collection{system.Integer(3)}}},
+		},
+		{
+			name:    "raises error if name is not a string",
+			args:    []expr.Expression{exprtest.Return(system.Integer(1))},
+			wantErr: true,
+		},
+		{
+			name:    "propagates name error",
+			args:    []expr.Expression{exprtest.Error(errors.New("some error"))},
+			wantErr: true,
+		},
+		{
+			name:    "propagates projection error",
+			args:    []expr.Expression{exprtest.Return(system.String("name")), exprtest.Error(errors.New("some error"))},
+			wantErr: true,
+		},
+		{
+			name:    "raises error with wrong arity",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var traces []trace
+			ctx := &expr.Context{
+				TraceSink: func(name string, collection system.Collection) {
+					traces = append(traces, trace{name, collection})
+				},
+			}
+
+			got, err := impl.Trace(ctx, input, tc.args...)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Trace() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			if diff := cmp.Diff(input, got); diff != "" {
+				t.Errorf("Trace() returned unexpected diff (-want, +got)\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantTraces, traces, cmp.AllowUnexported(trace{})); diff != "" {
+				t.Errorf("Trace() sent unexpected traces (-want, +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTrace_WithoutSink_ReturnsInput(t *testing.T) {
+	input := system.Collection{system.Integer(1)}
+	args := []expr.Expression{exprtest.Return(system.String("name")), exprtest.Return(system.Integer(2))}
+
+	got, err := impl.Trace(&expr.Context{}, input, args...)
+	if err != nil {
+		t.Fatalf("Trace() returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(input, got); diff != "" {
+		t.Errorf("Trace() returned unexpected diff (-want, +got)\n%s", diff)
+	}
+}
+
+func TestTrace_WithoutSink_RaisesError(t *testing.T) {
+	testCases := []struct {
+		name string
+		args []expr.Expression
+	}{
+		{
+			name: "name is not a string",
+			args: []expr.Expression{exprtest.Return(system.Integer(1))},
+		},
+		{
+			name: "projection raises error",
+			args: []expr.Expression{exprtest.Return(system.String("name")), exprtest.Error(errors.New("some error"))},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := impl.Trace(&expr.Context{}, system.Collection{system.Integer(1)}, tc.args...); err == nil {
+				t.Errorf("Trace() didn't return error when expected")
+			}
+		})
+	}
+}
+
 func TestTimeOfDay(t *testing.T) {
 	ctx := &expr.Context{Now: time.Date(0, time.January, 1, 19, 30, 5, 1000000, time.UTC)}
 	wantCollection := system.Collection{system.MustParseTime("19:30:05.001")}
@@ -48,3 +160,349 @@ func TestNow(t *testing.T) {
 		t.Errorf("impl.Now() returned unexpected result: got %v, want %v", got, wantCollection)
 	}
 }
+
+// decimalDigits compares Decimals by their significant digits, rather than
+// only their values.
+var decimalDigits = cmp.Transformer("digits", func(d system.Decimal) string {
+	return d.ToProtoDecimal().GetValue()
+})
+
+func TestBoundaries(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    system.Collection
+		args     []expr.Expression
+		wantLow  system.Collection
+		wantHigh system.Collection
+	}{
+		{
+			name:     "decimal default precision",
+			input:    system.Collection{system.MustParseDecimal("1.587")},
+			wantLow:  system.Collection{system.MustParseDecimal("1.58650000")},
+			wantHigh: system.Collection{system.MustParseDecimal("1.58750000")},
+		},
+		{
+			name:     "decimal with precision",
+			input:    system.Collection{system.MustParseDecimal("1.587")},
+			args:     []expr.Expression{exprtest.Return(system.Integer(6))},
+			wantLow:  system.Collection{system.MustParseDecimal("1.586500")},
+			wantHigh: system.Collection{system.MustParseDecimal("1.587500")},
+		},
+		{
+			name:     "negative decimal with lesser precision",
+			input:    system.Collection{system.MustParseDecimal("-1.587")},
+			args:     []expr.Expression{exprtest.Return(system.Integer(2))},
+			wantLow:  system.Collection{system.MustParseDecimal("-1.59")},
+			wantHigh: system.Collection{system.MustParseDecimal("-1.58")},
+		},
+		{
+			name:     "integer is promoted to decimal",
+			input:    system.Collection{system.Integer(1)},
+			wantLow:  system.Collection{system.MustParseDecimal("0.50000000")},
+			wantHigh: system.Collection{system.MustParseDecimal("1.50000000")},
+		},
+		{
+			name:     "FHIR decimal",
+			input:    system.Collection{&dtpb.Decimal{Value: "1.50"}},
+			args:     []expr.Expression{exprtest.Return(system.Integer(3))},
+			wantLow:  system.Collection{system.MustParseDecimal("1.495")},
+			wantHigh: system.Collection{system.MustParseDecimal("1.505")},
+		},
+		{
+			name:     "date with precision",
+			input:    system.Collection{system.MustParseDate("2014")},
+			args:     []expr.Expression{exprtest.Return(system.Integer(6))},
+			wantLow:  system.Collection{system.MustParseDate("2014-01")},
+			wantHigh: system.Collection{system.MustParseDate("2014-12")},
+		},
+		{
+			name:     "date default precision",
+			input:    system.Collection{system.MustParseDate("2023-04")},
+			wantLow:  system.Collection{system.MustParseDate("2023-04-01")},
+			wantHigh: system.Collection{system.MustParseDate("2023-04-30")},
+		},
+		{
+			name:     "dateTime with precision",
+			input:    system.Collection{system.MustParseDateTime("2014-01-01T08")},
+			args:     []expr.Expression{exprtest.Return(system.Integer(17))},
+			wantLow:  system.Collection{system.MustParseDateTime("2014-01-01T08:00:00.000")},
+			wantHigh: system.Collection{system.MustParseDateTime("2014-01-01T08:59:59.999")},
+		},
+		{
+			name:     "time with precision",
+			input:    system.Collection{system.MustParseTime("10:30")},
+			args:     []expr.Expression{exprtest.Return(system.Integer(9))},
+			wantLow:  system.Collection{system.MustParseTime("10:30:00.000")},
+			wantHigh: system.Collection{system.MustParseTime("10:30:59.999")},
+		},
+		{
+			name:     "unsupported precision",
+			input:    system.Collection{system.MustParseDate("2014")},
+			args:     []expr.Expression{exprtest.Return(system.Integer(17))},
+			wantLow:  system.Collection{},
+			wantHigh: system.Collection{},
+		},
+		{
+			name:     "negative precision",
+			input:    system.Collection{system.Integer(1)},
+			args:     []expr.Expression{exprtest.Return(system.Integer(-1))},
+			wantLow:  system.Collection{},
+			wantHigh: system.Collection{},
+		},
+		{
+			name:     "negative precision of time",
+			input:    system.Collection{system.MustParseTime("10:30")},
+			args:     []expr.Expression{exprtest.Return(system.Integer(-1))},
+			wantLow:  system.Collection{},
+			wantHigh: system.Collection{},
+		},
+		{
+			name:     "empty precision",
+			input:    system.Collection{system.MustParseDecimal("1.5")},
+			args:     []expr.Expression{exprtest.Return()},
+			wantLow:  system.Collection{},
+			wantHigh: system.Collection{},
+		},
+		{
+			name:     "empty input",
+			input:    system.Collection{},
+			wantLow:  system.Collection{},
+			wantHigh: system.Collection{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotLow, err := impl.LowBoundary(&expr.Context{}, tc.input, tc.args...)
+			if err != nil {
+				t.Fatalf("LowBoundary() returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.wantLow, gotLow, decimalDigits); diff != "" {
+				t.Errorf("LowBoundary() returned unexpected diff (-want, +got)\n%s", diff)
+			}
+			gotHigh, err := impl.HighBoundary(&expr.Context{}, tc.input, tc.args...)
+			if err != nil {
+				t.Fatalf("HighBoundary() returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.wantHigh, gotHigh, decimalDigits); diff != "" {
+				t.Errorf("HighBoundary() returned unexpected diff (-want, +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestBoundaries_ReturnsError(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   system.Collection
+		args    []expr.Expression
+		wantErr error
+	}{
+		{
+			name:    "non-singleton input",
+			input:   system.Collection{system.Integer(1), system.Integer(2)},
+			wantErr: expr.ErrNotSingleton,
+		},
+		{
+			name:    "unsupported type",
+			input:   system.Collection{system.String("1.5")},
+			wantErr: expr.ErrInvalidType,
+		},
+		{
+			name:    "too many arguments",
+			input:   system.Collection{system.Integer(1)},
+			args:    []expr.Expression{exprtest.Return(system.Integer(1)), exprtest.Return(system.Integer(2))},
+			wantErr: impl.ErrWrongArity,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := impl.LowBoundary(&expr.Context{}, tc.input, tc.args...); !errors.Is(err, tc.wantErr) {
+				t.Errorf("LowBoundary() got error %v, want %v", err, tc.wantErr)
+			}
+			if _, err := impl.HighBoundary(&expr.Context{}, tc.input, tc.args...); !errors.Is(err, tc.wantErr) {
+				t.Errorf("HighBoundary() got error %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestPrecision(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input system.Collection
+		want  system.Collection
+	}{
+		{"decimal with trailing zeroes", system.Collection{system.MustParseDecimal("1.58700")}, system.Collection{system.Integer(5)}},
+		{"FHIR decimal", system.Collection{&dtpb.Decimal{Value: "0.10"}}, system.Collection{system.Integer(2)}},
+		{"integer", system.Collection{system.Integer(12)}, system.Collection{system.Integer(0)}},
+		{"date", system.Collection{system.MustParseDate("2014")}, system.Collection{system.Integer(4)}},
+		{"dateTime", system.Collection{system.MustParseDateTime("2014-01-05T10:30:00.000")}, system.Collection{system.Integer(17)}},
+		{"time", system.Collection{system.MustParseTime("10:30")}, system.Collection{system.Integer(4)}},
+		{"time with milliseconds", system.Collection{system.MustParseTime("10:30:00.000")}, system.Collection{system.Integer(9)}},
+		{"empty", system.Collection{}, system.Collection{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := impl.Precision(&expr.Context{}, tc.input)
+			if err != nil {
+				t.Fatalf("Precision() returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Precision() returned unexpected diff (-want, +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPrecision_ReturnsError(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   system.Collection
+		args    []expr.Expression
+		wantErr error
+	}{
+		{"non-singleton input", system.Collection{system.Integer(1), system.Integer(2)}, nil, expr.ErrNotSingleton},
+		{"unsupported type", system.Collection{system.Boolean(true)}, nil, expr.ErrInvalidType},
+		{"wrong arity", system.Collection{system.Integer(1)}, []expr.Expression{exprtest.Return(system.Integer(1))}, impl.ErrWrongArity},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := impl.Precision(&expr.Context{}, tc.input, tc.args...); !errors.Is(err, tc.wantErr) {
+				t.Errorf("Precision() got error %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestDefineVariable(t *testing.T) {
+	input := system.Collection{system.Integer(1), system.Integer(2)}
+
+	testCases := []struct {
+		name string
+		args []expr.Expression
+		want system.Collection
+	}{
+		{
+			name: "defines input",
+			args: []expr.Expression{exprtest.Return(system.String("v"))},
+			want: input,
+		},
+		{
+			name: "defines expression result",
+			args: []expr.Expression{exprtest.Return(system.String("v")), exprtest.Return(system.Boolean(true))},
+			want: system.Collection{system.Boolean(true)},
+		},
+		{
+			name: "defines empty result",
+			args: []expr.Expression{exprtest.Return(system.String("v")), exprtest.Return()},
+			want: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := impl.DefineVariable(&expr.Context{}, input, tc.args...)
+			if err != nil {
+				t.Fatalf("DefineVariable() returned unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("DefineVariable() returned unexpected diff (-want, +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCoalesce(t *testing.T) {
+	errMock := errors.New("some error")
+	testCases := []struct {
+		name    string
+		input   system.Collection
+		args    []expr.Expression
+		want    system.Collection
+		wantErr bool
+	}{
+		{
+			name:  "returns first argument when non-empty",
+			input: system.Collection{},
+			args:  []expr.Expression{exprtest.Return(system.String("a")), exprtest.Return(system.String("b"))},
+			want:  system.Collection{system.String("a")},
+		},
+		{
+			name:  "skips empty arguments",
+			input: system.Collection{},
+			args:  []expr.Expression{exprtest.Return(), exprtest.Return(), exprtest.Return(system.Integer(1), system.Integer(2))},
+			want:  system.Collection{system.Integer(1), system.Integer(2)},
+		},
+		{
+			name:  "returns empty when all arguments are empty",
+			input: system.Collection{},
+			args:  []expr.Expression{exprtest.Return(), exprtest.Return()},
+			want:  system.Collection{},
+		},
+		{
+			name:  "doesn't evaluate arguments after first non-empty result",
+			input: system.Collection{},
+			args:  []expr.Expression{exprtest.Return(system.String("a")), exprtest.Error(errMock)},
+			want:  system.Collection{system.String("a")},
+		},
+		{
+			name:    "propagates error from evaluated argument",
+			input:   system.Collection{},
+			args:    []expr.Expression{exprtest.Return(), exprtest.Error(errMock)},
+			wantErr: true,
+		},
+		{
+			name:    "raises error without arguments",
+			input:   system.Collection{},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := impl.Coalesce(&expr.Context{}, tc.input, tc.args...)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Coalesce() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("Coalesce() returned unexpected diff (-want, +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDefineVariable_ReturnsError(t *testing.T) {
+	testCases := []struct {
+		name    string
+		ctx     *expr.Context
+		args    []expr.Expression
+		wantErr error
+	}{
+		{
+			name:    "no arguments",
+			ctx:     &expr.Context{},
+			wantErr: impl.ErrWrongArity,
+		},
+		{
+			name:    "non-string name",
+			ctx:     &expr.Context{},
+			args:    []expr.Expression{exprtest.Return(system.Integer(1))},
+			wantErr: system.ErrNotConvertible,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := impl.DefineVariable(tc.ctx, system.Collection{}, tc.args...)
+
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("DefineVariable() got error %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
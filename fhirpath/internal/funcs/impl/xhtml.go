@@ -0,0 +1,141 @@
+package impl
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	xhtmlNamespace = "http://www.w3.org/1999/xhtml"
+	xmlNamespace   = "http://www.w3.org/XML/1998/namespace"
+)
+
+// narrativeElements are the XHTML elements permitted in a FHIR narrative.
+// See https://hl7.org/fhir/R4/narrative.html#xhtml
+var narrativeElements = map[string]bool{
+	"a": true, "abbr": true, "acronym": true, "address": true, "area": true,
+	"b": true, "bdo": true, "big": true, "blockquote": true, "br": true,
+	"caption": true, "cite": true, "code": true, "col": true, "colgroup": true,
+	"dd": true, "dfn": true, "div": true, "dl": true, "dt": true, "em": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"hr": true, "i": true, "img": true, "kbd": true, "li": true, "map": true,
+	"ol": true, "p": true, "pre": true, "q": true, "samp": true, "small": true,
+	"span": true, "strong": true, "sub": true, "sup": true, "table": true,
+	"tbody": true, "td": true, "tfoot": true, "th": true, "thead": true,
+	"tr": true, "tt": true, "ul": true, "var": true,
+}
+
+// narrativeAttributes are the attributes permitted on elements in a FHIR
+// narrative. Notably, this excludes all event handler attributes.
+var narrativeAttributes = map[string]bool{
+	"abbr": true, "accesskey": true, "align": true, "alt": true, "axis": true,
+	"border": true, "cellpadding": true, "cellspacing": true, "char": true,
+	"charoff": true, "charset": true, "cite": true, "class": true,
+	"colspan": true, "compact": true, "coords": true, "dir": true,
+	"frame": true, "headers": true, "height": true, "href": true,
+	"hreflang": true, "hspace": true, "id": true, "lang": true,
+	"longdesc": true, "name": true, "nohref": true, "rel": true, "rev": true,
+	"rowspan": true, "rules": true, "scope": true, "shape": true, "span": true,
+	"src": true, "start": true, "style": true, "summary": true, "tabindex": true,
+	"title": true, "type": true, "valign": true, "value": true, "vspace": true,
+	"width": true,
+}
+
+// errNarrativeInvalid is used internally to report that XHTML content breaks
+// the narrative rules.
+var errNarrativeInvalid = errors.New("invalid narrative")
+
+// checkNarrative returns nil if the XHTML content obeys the rules for FHIR
+// narratives: it must be well-formed, have a single root div element in the
+// XHTML namespace, only use the permitted elements and attributes, not link to
+// scripts, and have some non-whitespace content.
+//
+// See https://hl7.org/fhir/R4/narrative.html#xhtml
+func checkNarrative(content string) error {
+	decoder := xml.NewDecoder(strings.NewReader(content))
+	decoder.Entity = xml.HTMLEntity
+
+	depth := 0
+	roots := 0
+	hasContent := false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			if depth == 0 {
+				roots++
+				if roots > 1 || token.Name.Local != "div" {
+					return fmt.Errorf("%w: root must be a single div element", errNarrativeInvalid)
+				}
+			}
+			if err := checkNarrativeElement(token); err != nil {
+				return err
+			}
+			if token.Name.Local == "img" {
+				hasContent = true
+			}
+			depth++
+		case xml.EndElement:
+			depth--
+		case xml.CharData:
+			if strings.TrimSpace(string(token)) == "" {
+				continue
+			}
+			if depth == 0 {
+				return fmt.Errorf("%w: text outside of root div", errNarrativeInvalid)
+			}
+			hasContent = true
+		case xml.Directive:
+			return fmt.Errorf("%w: directives are not permitted", errNarrativeInvalid)
+		}
+	}
+	if roots == 0 {
+		return fmt.Errorf("%w: missing root div element", errNarrativeInvalid)
+	}
+	if !hasContent {
+		return fmt.Errorf("%w: no non-whitespace content", errNarrativeInvalid)
+	}
+	return nil
+}
+
+// checkNarrativeElement checks a single element and its attributes against the
+// narrative rules.
+func checkNarrativeElement(element xml.StartElement) error {
+	if element.Name.Space != xhtmlNamespace {
+		return fmt.Errorf("%w: element '%s' is not in the XHTML namespace", errNarrativeInvalid, element.Name.Local)
+	}
+	if !narrativeElements[element.Name.Local] {
+		return fmt.Errorf("%w: element '%s' is not permitted", errNarrativeInvalid, element.Name.Local)
+	}
+	for _, attr := range element.Attr {
+		if attr.Name.Space == "xmlns" || (attr.Name.Space == "" && attr.Name.Local == "xmlns") {
+			continue
+		}
+		if attr.Name.Space == xmlNamespace && attr.Name.Local == "lang" {
+			continue
+		}
+		if attr.Name.Space != "" || !narrativeAttributes[attr.Name.Local] {
+			return fmt.Errorf("%w: attribute '%s' is not permitted", errNarrativeInvalid, attr.Name.Local)
+		}
+		if isScriptURL(attr.Value) {
+			return fmt.Errorf("%w: script URLs are not permitted", errNarrativeInvalid)
+		}
+	}
+	return nil
+}
+
+// isScriptURL returns true if the attribute value is a URL that executes a
+// script when followed.
+func isScriptURL(value string) bool {
+	scheme, _, found := strings.Cut(strings.ToLower(strings.TrimSpace(value)), ":")
+	return found && (scheme == "javascript" || scheme == "vbscript")
+}
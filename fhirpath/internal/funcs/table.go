@@ -1,6 +1,10 @@
 package funcs
 
-import "github.com/fhir-fli/fhirpath-go/fhirpath/internal/funcs/impl"
+import (
+	"math"
+
+	"github.com/fhir-fli/fhirpath-go/fhirpath/internal/funcs/impl"
+)
 
 // BaseTable holds the default mapping of all
 // FHIRPath functions. Unimplemented functions return an
@@ -24,7 +28,12 @@ var baseTable = FunctionTable{
 		1,
 		false,
 	},
-	"all": notImplemented,
+	"all": Function{
+		impl.All,
+		1,
+		1,
+		false,
+	},
 	"allTrue": Function{
 		impl.AllTrue,
 		0,
@@ -49,8 +58,18 @@ var baseTable = FunctionTable{
 		0,
 		false,
 	},
-	"subsetOf":   notImplemented,
-	"supersetOf": notImplemented,
+	"subsetOf": Function{
+		impl.SubsetOf,
+		1,
+		1,
+		false,
+	},
+	"supersetOf": Function{
+		impl.SupersetOf,
+		1,
+		1,
+		false,
+	},
 	"count": Function{
 		impl.Count,
 		0,
@@ -81,9 +100,36 @@ var baseTable = FunctionTable{
 		1,
 		false,
 	},
-	"repeat": notImplemented,
-	"ofType": notImplemented,
-	"single": notImplemented,
+	"repeat": Function{
+		impl.Repeat,
+		1,
+		1,
+		false,
+	},
+	"repeatAll": Function{
+		impl.RepeatAll,
+		1,
+		1,
+		false,
+	},
+	"ofType": Function{
+		impl.OfType,
+		1,
+		1,
+		true,
+	},
+	"sort": Function{
+		impl.Sort,
+		0,
+		math.MaxInt,
+		false,
+	},
+	"single": Function{
+		impl.Single,
+		0,
+		0,
+		false,
+	},
 	"first": Function{
 		impl.First,
 		0,
@@ -126,9 +172,24 @@ var baseTable = FunctionTable{
 		1,
 		false,
 	},
-	"union":   notImplemented,
-	"combine": notImplemented,
-	"iif":     notImplemented,
+	"union": Function{
+		impl.Union,
+		1,
+		1,
+		false,
+	},
+	"combine": Function{
+		impl.Combine,
+		1,
+		1,
+		false,
+	},
+	"iif": Function{
+		impl.Iif,
+		2,
+		3,
+		false,
+	},
 	"toBoolean": Function{
 		impl.ToBoolean,
 		0,
@@ -190,7 +251,7 @@ var baseTable = FunctionTable{
 		false,
 	},
 	"toQuantity": Function{
-		impl.ToInteger,
+		impl.ToQuantity,
 		0,
 		1,
 		false,
@@ -297,6 +358,60 @@ var baseTable = FunctionTable{
 		0,
 		false,
 	},
+	"lastIndexOf": Function{
+		impl.LastIndexOf,
+		1,
+		1,
+		false,
+	},
+	"matchesFull": Function{
+		impl.MatchesFull,
+		1,
+		1,
+		false,
+	},
+	"trim": Function{
+		impl.Trim,
+		0,
+		0,
+		false,
+	},
+	"split": Function{
+		impl.Split,
+		1,
+		1,
+		false,
+	},
+	"join": Function{
+		impl.Join,
+		0,
+		1,
+		false,
+	},
+	"encode": Function{
+		impl.Encode,
+		1,
+		1,
+		false,
+	},
+	"decode": Function{
+		impl.Decode,
+		1,
+		1,
+		false,
+	},
+	"escape": Function{
+		impl.Escape,
+		1,
+		1,
+		false,
+	},
+	"unescape": Function{
+		impl.Unescape,
+		1,
+		1,
+		false,
+	},
 	"abs": Function{
 		impl.Abs,
 		0,
@@ -329,20 +444,20 @@ var baseTable = FunctionTable{
 	},
 	"log": Function{
 		impl.Log,
-		0,
-		0,
+		1,
+		1,
 		false,
 	},
 	"power": Function{
 		impl.Power,
-		0,
-		0,
+		1,
+		1,
 		false,
 	},
 	"round": Function{
 		impl.Round,
 		0,
-		0,
+		1,
 		false,
 	},
 	"sqrt": Function{
@@ -357,9 +472,174 @@ var baseTable = FunctionTable{
 		0,
 		false,
 	},
-	"children":    notImplemented,
-	"descendants": notImplemented,
-	"trace":       notImplemented,
+	"children": Function{
+		impl.Children,
+		0,
+		0,
+		false,
+	},
+	"descendants": Function{
+		impl.Descendants,
+		0,
+		0,
+		false,
+	},
+	"resolve": Function{
+		impl.Resolve,
+		0,
+		0,
+		false,
+	},
+	"memberOf": Function{
+		impl.MemberOf,
+		1,
+		1,
+		false,
+	},
+	"subsumes": Function{
+		impl.Subsumes,
+		1,
+		1,
+		false,
+	},
+	"subsumedBy": Function{
+		impl.SubsumedBy,
+		1,
+		1,
+		false,
+	},
+	"conformsTo": Function{
+		impl.ConformsTo,
+		1,
+		1,
+		false,
+	},
+	"hasValue": Function{
+		impl.HasValue,
+		0,
+		0,
+		false,
+	},
+	"getValue": Function{
+		impl.GetValue,
+		0,
+		0,
+		false,
+	},
+	"htmlChecks": Function{
+		impl.HtmlChecks,
+		0,
+		0,
+		false,
+	},
+	"trace": Function{
+		impl.Trace,
+		1,
+		2,
+		false,
+	},
+	"lowBoundary": Function{
+		impl.LowBoundary,
+		0,
+		1,
+		false,
+	},
+	"highBoundary": Function{
+		impl.HighBoundary,
+		0,
+		1,
+		false,
+	},
+	"precision": Function{
+		impl.Precision,
+		0,
+		0,
+		false,
+	},
+	"defineVariable": Function{
+		impl.DefineVariable,
+		1,
+		2,
+		false,
+	},
+	"coalesce": Function{
+		impl.Coalesce,
+		1,
+		math.MaxInt,
+		false,
+	},
+	"yearOf": Function{
+		impl.YearOf,
+		0,
+		0,
+		false,
+	},
+	"monthOf": Function{
+		impl.MonthOf,
+		0,
+		0,
+		false,
+	},
+	"dayOf": Function{
+		impl.DayOf,
+		0,
+		0,
+		false,
+	},
+	"hourOf": Function{
+		impl.HourOf,
+		0,
+		0,
+		false,
+	},
+	"minuteOf": Function{
+		impl.MinuteOf,
+		0,
+		0,
+		false,
+	},
+	"secondOf": Function{
+		impl.SecondOf,
+		0,
+		0,
+		false,
+	},
+	"millisecondOf": Function{
+		impl.MillisecondOf,
+		0,
+		0,
+		false,
+	},
+	"timezoneOffsetOf": Function{
+		impl.TimezoneOffsetOf,
+		0,
+		0,
+		false,
+	},
+	"dateOf": Function{
+		impl.DateOf,
+		0,
+		0,
+		false,
+	},
+	"timeOf": Function{
+		impl.TimeOf,
+		0,
+		0,
+		false,
+	},
+	"duration": Function{
+		impl.Duration,
+		2,
+		2,
+		false,
+	},
+	"difference": Function{
+		impl.Difference,
+		2,
+		2,
+		false,
+	},
 	"now": Function{
 		impl.Now,
 		0,
@@ -384,6 +664,12 @@ var baseTable = FunctionTable{
 		0,
 		false,
 	},
+	"aggregate": Function{
+		impl.Aggregate,
+		1,
+		2,
+		false,
+	},
 }
 
 // Clone returns a deep copy of the base
@@ -21,14 +21,59 @@ var (
 	errTooManyQualifiers  = errors.New("too many type qualifiers")
 	errVisitingChildren   = errors.New("error while visiting child expressions")
 	errUnresolvedFunction = errors.New("function identifier can't be resolved")
+	errVariableName       = errors.New("variable name must be a string literal")
+	errVariableDefined    = errors.New("variable is already defined")
 )
 
+// defineVariableFunction is the name of the function that defines variables,
+// which are scoped when the expression is compiled.
+const defineVariableFunction = "defineVariable"
+
+// SortFunction is the name of the function whose keys may be followed by a
+// sort direction.
+const SortFunction = "sort"
+
+// SortDirectionChannel is the token channel of the direction keywords that
+// may follow the keys of a sort() invocation, as in sort($this desc). These
+// keywords are hidden from the parser, since the grammar doesn't allow for
+// them.
+const SortDirectionChannel = 2
+
+// systemVariables are the names of the environment variables defined by
+// FHIRPath and FHIR, which can't be redefined by defineVariable().
+var systemVariables = map[string]bool{
+	"context":      true,
+	"resource":     true,
+	"rootResource": true,
+	"ucum":         true,
+	"sct":          true,
+	"loinc":        true,
+}
+
+// variableScope is a linked list of the variables that are visible at a
+// point in an expression, innermost definition first.
+type variableScope struct {
+	name   string
+	parent *variableScope
+}
+
+// defines returns true if the variable is visible in the scope.
+func (s *variableScope) defines(name string) bool {
+	for ; s != nil; s = s.parent {
+		if s.name == name {
+			return true
+		}
+	}
+	return false
+}
+
 type FHIRPathVisitor struct {
 	*antlr.BaseParseTreeVisitor
 	visitedRoot bool
 	Functions   funcs.FunctionTable
 	Transform   VisitorTransform
 	Permissive  bool
+	scope       *variableScope
 }
 
 type VisitResult struct {
@@ -48,9 +93,20 @@ func (v *FHIRPathVisitor) clone() *FHIRPathVisitor {
 		Transform:   v.Transform,
 		Permissive:  v.Permissive,
 		visitedRoot: false,
+		scope:       v.scope,
 	}
 }
 
+// visitScoped visits a sub-expression within its own variable scope, such
+// that the variables it defines are not visible to sibling expressions, or to
+// invocations that follow the containing expression. This applies to the
+// operands of operators and to function arguments.
+func (v *FHIRPathVisitor) visitScoped(tree antlr.ParseTree) *VisitResult {
+	scope := v.scope
+	defer func() { v.scope = scope }()
+	return v.Visit(tree).(*VisitResult)
+}
+
 func (v *FHIRPathVisitor) transformedVisitResult(resultExpr expr.Expression) *VisitResult {
 	if v.Transform == nil {
 		v.Transform = IdentityTransform
@@ -103,7 +159,7 @@ func (v *FHIRPathVisitor) VisitPolarityExpression(ctx *grammar.PolarityExpressio
 }
 
 func (v *FHIRPathVisitor) VisitAdditiveExpression(ctx *grammar.AdditiveExpressionContext) interface{} {
-	leftResult := v.Visit(ctx.Expression(0)).(*VisitResult)
+	leftResult := v.visitScoped(ctx.Expression(0))
 	if leftResult.Error != nil {
 		return &VisitResult{nil, leftResult.Error}
 	}
@@ -127,7 +183,7 @@ func (v *FHIRPathVisitor) VisitAdditiveExpression(ctx *grammar.AdditiveExpressio
 }
 
 func (v *FHIRPathVisitor) VisitMultiplicativeExpression(ctx *grammar.MultiplicativeExpressionContext) interface{} {
-	leftResult := v.Visit(ctx.Expression(0)).(*VisitResult)
+	leftResult := v.visitScoped(ctx.Expression(0))
 	if leftResult.Error != nil {
 		return &VisitResult{nil, leftResult.Error}
 	}
@@ -156,12 +212,24 @@ func (v *FHIRPathVisitor) VisitMultiplicativeExpression(ctx *grammar.Multiplicat
 	)
 }
 
+// VisitUnionExpression visits both sides of the '|' operator, and constructs
+// a union expression from the results.
 func (v *FHIRPathVisitor) VisitUnionExpression(ctx *grammar.UnionExpressionContext) interface{} {
-	return &VisitResult{nil, errNotSupported}
+	leftResult := v.visitScoped(ctx.Expression(0))
+	if leftResult.Error != nil {
+		return &VisitResult{nil, leftResult.Error}
+	}
+	rightResult := v.clone().Visit(ctx.Expression(1)).(*VisitResult)
+	if rightResult.Error != nil {
+		return &VisitResult{nil, rightResult.Error}
+	}
+
+	expression := &expr.UnionExpression{Left: leftResult.Result, Right: rightResult.Result}
+	return v.transformedVisitResult(expression)
 }
 
 func (v *FHIRPathVisitor) VisitOrExpression(ctx *grammar.OrExpressionContext) interface{} {
-	leftResult := v.Visit(ctx.Expression(0)).(*VisitResult)
+	leftResult := v.visitScoped(ctx.Expression(0))
 	if leftResult.Error != nil {
 		return &VisitResult{nil, leftResult.Error}
 	}
@@ -177,7 +245,7 @@ func (v *FHIRPathVisitor) VisitOrExpression(ctx *grammar.OrExpressionContext) in
 }
 
 func (v *FHIRPathVisitor) VisitAndExpression(ctx *grammar.AndExpressionContext) interface{} {
-	leftResult := v.Visit(ctx.Expression(0)).(*VisitResult)
+	leftResult := v.visitScoped(ctx.Expression(0))
 	if leftResult.Error != nil {
 		return &VisitResult{nil, leftResult.Error}
 	}
@@ -190,12 +258,26 @@ func (v *FHIRPathVisitor) VisitAndExpression(ctx *grammar.AndExpressionContext)
 	return v.transformedVisitResult(expression)
 }
 
+// VisitMembershipExpression visits both sides of the 'in' or 'contains'
+// operator, and constructs a membership expression from the results.
 func (v *FHIRPathVisitor) VisitMembershipExpression(ctx *grammar.MembershipExpressionContext) interface{} {
-	return &VisitResult{nil, errNotSupported}
+	leftResult := v.visitScoped(ctx.Expression(0))
+	if leftResult.Error != nil {
+		return &VisitResult{nil, leftResult.Error}
+	}
+	rightResult := v.clone().Visit(ctx.Expression(1)).(*VisitResult)
+	if rightResult.Error != nil {
+		return &VisitResult{nil, rightResult.Error}
+	}
+
+	operator := expr.Operator(ctx.GetChild(1).(antlr.TerminalNode).GetText())
+
+	expression := &expr.MembershipExpression{Left: leftResult.Result, Right: rightResult.Result, Op: operator}
+	return v.transformedVisitResult(expression)
 }
 
 func (v *FHIRPathVisitor) VisitInequalityExpression(ctx *grammar.InequalityExpressionContext) interface{} {
-	leftResult := v.Visit(ctx.Expression(0)).(*VisitResult)
+	leftResult := v.visitScoped(ctx.Expression(0))
 	if leftResult.Error != nil {
 		return &VisitResult{nil, leftResult.Error}
 	}
@@ -233,7 +315,7 @@ func (v *FHIRPathVisitor) VisitInvocationExpression(ctx *grammar.InvocationExpre
 // VisitEqualityExpression both equality subexpressions and constructs an Equality Expression
 // from the results of each subexpression
 func (v *FHIRPathVisitor) VisitEqualityExpression(ctx *grammar.EqualityExpressionContext) interface{} {
-	leftResult := v.Visit(ctx.Expression(0)).(*VisitResult)
+	leftResult := v.visitScoped(ctx.Expression(0))
 	if leftResult.Error != nil {
 		return &VisitResult{nil, leftResult.Error}
 	}
@@ -250,15 +332,15 @@ func (v *FHIRPathVisitor) VisitEqualityExpression(ctx *grammar.EqualityExpressio
 	case expr.NotEquals:
 		expression = &expr.EqualityExpression{Left: leftResult.Result, Right: rightResult.Result, Not: true}
 	case expr.Equivalence:
-		// TODO (PHP-5889): Implement equivalence expressions
+		expression = &expr.EquivalenceExpression{Left: leftResult.Result, Right: rightResult.Result}
 	case expr.Inequivalence:
-		// TODO (PHP-5889): Implement non-equivalence expressions
+		expression = &expr.EquivalenceExpression{Left: leftResult.Result, Right: rightResult.Result, Not: true}
 	}
 	return v.transformedVisitResult(expression)
 }
 
 func (v *FHIRPathVisitor) VisitImpliesExpression(ctx *grammar.ImpliesExpressionContext) interface{} {
-	leftResult := v.Visit(ctx.Expression(0)).(*VisitResult)
+	leftResult := v.visitScoped(ctx.Expression(0))
 	if leftResult.Error != nil {
 		return &VisitResult{nil, leftResult.Error}
 	}
@@ -306,6 +388,9 @@ func (v *FHIRPathVisitor) VisitLiteralTerm(ctx *grammar.LiteralTermContext) inte
 func (v *FHIRPathVisitor) VisitExternalConstantTerm(ctx *grammar.ExternalConstantTermContext) interface{} {
 	ident := ctx.ExternalConstant().GetText()
 	ident = strings.TrimPrefix(ident, "%")
+	if v.scope.defines(ident) {
+		return v.transformedVisitResult(&expr.VariableExpression{Name: ident})
+	}
 	return v.transformedVisitResult(&expr.ExternalConstantExpression{Identifier: ident})
 }
 
@@ -419,8 +504,12 @@ func (v *FHIRPathVisitor) VisitExternalConstant(ctx *grammar.ExternalConstantCon
 
 // VisitMemberInvocation checks to see if the identifier corresponds to a resource type and is the
 // root of the expression. If so, it will return a TypeExpression. Otherwise, it returns a FieldExpression.
+// Delimited identifiers, such as `div`, have their backticks removed.
 func (v *FHIRPathVisitor) VisitMemberInvocation(ctx *grammar.MemberInvocationContext) interface{} {
 	identifier := ctx.GetText()
+	if len(identifier) >= 2 && strings.HasPrefix(identifier, "`") && strings.HasSuffix(identifier, "`") {
+		identifier = identifier[1 : len(identifier)-1]
+	}
 	var expression expr.Expression
 
 	if resource.IsType(identifier) && !v.visitedRoot {
@@ -438,15 +527,15 @@ func (v *FHIRPathVisitor) VisitFunctionInvocation(ctx *grammar.FunctionInvocatio
 }
 
 func (v *FHIRPathVisitor) VisitThisInvocation(ctx *grammar.ThisInvocationContext) interface{} {
-	return &VisitResult{&expr.IdentityExpression{}, nil}
+	return &VisitResult{&expr.ThisExpression{}, nil}
 }
 
 func (v *FHIRPathVisitor) VisitIndexInvocation(ctx *grammar.IndexInvocationContext) interface{} {
-	return &VisitResult{nil, errNotSupported}
+	return &VisitResult{&expr.IterationIndexExpression{}, nil}
 }
 
 func (v *FHIRPathVisitor) VisitTotalInvocation(ctx *grammar.TotalInvocationContext) interface{} {
-	return &VisitResult{nil, errNotSupported}
+	return &VisitResult{&expr.TotalExpression{}, nil}
 }
 
 func (v *FHIRPathVisitor) VisitFunction(ctx *grammar.FunctionContext) interface{} {
@@ -455,6 +544,9 @@ func (v *FHIRPathVisitor) VisitFunction(ctx *grammar.FunctionContext) interface{
 	if !ok {
 		return &VisitResult{nil, fmt.Errorf("%w: %s", errUnresolvedFunction, ident)}
 	}
+	if fn.IsTypeFunction {
+		return v.visitTypeFunction(ctx, fn)
+	}
 
 	results := []*VisitResult{}
 	if args := ctx.ParamList(); args != nil {
@@ -470,11 +562,102 @@ func (v *FHIRPathVisitor) VisitFunction(ctx *grammar.FunctionContext) interface{
 	if len(expressions) < fn.MinArity || len(expressions) > fn.MaxArity {
 		return &VisitResult{nil, fmt.Errorf("%w: input arity outside of function arity bounds", impl.ErrWrongArity)}
 	}
+	if ident == defineVariableFunction {
+		name, err := v.defineVariable(ctx.ParamList())
+		if err != nil {
+			return &VisitResult{nil, err}
+		}
+		value := v.transformedVisitResult(&expr.FunctionExpression{Fn: fn.Func, Args: expressions})
+		if value.Error != nil {
+			return value
+		}
+		return &VisitResult{&expr.DefineVariableExpression{Name: name, Value: value.Result}, nil}
+	}
+	if ident == SortFunction {
+		expressions = sortKeys(ctx, expressions)
+	}
+	return v.transformedVisitResult(&expr.FunctionExpression{Fn: fn.Func, Args: expressions})
+}
+
+// defineVariable adds the variable named by the first argument of a
+// defineVariable() invocation to the scope of the visitor, making it visible
+// to the invocations that follow, and returns its name. The name must be a
+// string literal, and must not name a system variable or a variable that is
+// already in scope.
+func (v *FHIRPathVisitor) defineVariable(args grammar.IParamListContext) (string, error) {
+	if args == nil {
+		return "", errVariableName
+	}
+	name, ok := stringLiteral(args.Expression(0))
+	if !ok {
+		return "", fmt.Errorf("%w: %s", errVariableName, args.Expression(0).GetText())
+	}
+	if systemVariables[name] || v.scope.defines(name) {
+		return "", fmt.Errorf("%w: %s", errVariableDefined, name)
+	}
+	v.scope = &variableScope{name: name, parent: v.scope}
+	return name, nil
+}
+
+// sortKeys wraps the keys of a sort() invocation that are followed by a
+// direction keyword in a SortKeyExpression, recording their direction.
+func sortKeys(ctx *grammar.FunctionContext, keys []expr.Expression) []expr.Expression {
+	tokens, ok := ctx.GetParser().GetTokenStream().(*antlr.CommonTokenStream)
+	if !ok {
+		return keys
+	}
+	result := make([]expr.Expression, len(keys))
+	for i, key := range keys {
+		result[i] = key
+		stop := ctx.ParamList().Expression(i).GetStop()
+		for _, token := range tokens.GetHiddenTokensToRight(stop.GetTokenIndex(), SortDirectionChannel) {
+			result[i] = &expr.SortKeyExpression{Expr: key, Descending: token.GetText() == "desc"}
+		}
+	}
+	return result
+}
+
+// stringLiteral returns the value of the expression if it is a string
+// literal.
+func stringLiteral(e grammar.IExpressionContext) (string, bool) {
+	term, ok := e.(*grammar.TermExpressionContext)
+	if !ok {
+		return "", false
+	}
+	literalTerm, ok := term.Term().(*grammar.LiteralTermContext)
+	if !ok {
+		return "", false
+	}
+	literal, ok := literalTerm.Literal().(*grammar.StringLiteralContext)
+	if !ok {
+		return "", false
+	}
+	value, err := system.ParseString(literal.STRING().GetText())
+	if err != nil {
+		return "", false
+	}
+	return string(value), true
+}
+
+// visitTypeFunction constructs a function expression for functions that take
+// a type specifier as their argument, such as ofType(). The argument is
+// resolved as a type specifier, rather than visited as an expression.
+func (v *FHIRPathVisitor) visitTypeFunction(ctx *grammar.FunctionContext, fn funcs.Function) *VisitResult {
+	args := ctx.ParamList()
+	if args == nil || len(args.AllExpression()) != 1 {
+		return &VisitResult{nil, fmt.Errorf("%w: expected a single type specifier", impl.ErrWrongArity)}
+	}
+	identifiers := strings.Split(args.Expression(0).GetText(), ".")
+	typeSpecifier := newTypeResult(identifiers)
+	if typeSpecifier.err != nil {
+		return &VisitResult{nil, typeSpecifier.err}
+	}
+	expressions := []expr.Expression{&expr.TypeSpecifierExpression{Type: typeSpecifier.result}}
 	return v.transformedVisitResult(&expr.FunctionExpression{Fn: fn.Func, Args: expressions})
 }
 
 func (v *FHIRPathVisitor) VisitParamList(ctx *grammar.ParamListContext) interface{} {
-	return slices.Map(ctx.AllExpression(), func(e grammar.IExpressionContext) *VisitResult { return v.Visit(e).(*VisitResult) })
+	return slices.Map(ctx.AllExpression(), func(e grammar.IExpressionContext) *VisitResult { return v.visitScoped(e) })
 }
 
 func (v *FHIRPathVisitor) VisitQuantity(ctx *grammar.QuantityContext) interface{} {
@@ -495,6 +678,12 @@ func (v *FHIRPathVisitor) VisitPluralDateTimePrecision(ctx *grammar.PluralDateTi
 
 func (v *FHIRPathVisitor) VisitTypeSpecifier(ctx *grammar.TypeSpecifierContext) interface{} {
 	identifiers := v.Visit(ctx.QualifiedIdentifier()).([]string)
+	return newTypeResult(identifiers)
+}
+
+// newTypeResult resolves a type specifier from its (optionally qualified)
+// identifiers.
+func newTypeResult(identifiers []string) *typeResult {
 	if len(identifiers) == 1 {
 		specifier, err := reflection.NewTypeSpecifier(identifiers[0])
 		return &typeResult{specifier, err}
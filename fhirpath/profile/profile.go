@@ -0,0 +1,84 @@
+/*
+Package profile provides the Validator abstraction used by the FHIRPath
+conformsTo() function, along with a default implementation that trusts the
+profiles claimed by a resource.
+
+A Validator is supplied to evaluation with the evalopts.ProfileValidator
+option. If no Validator is supplied, conformsTo() uses ClaimedProfiles.
+*/
+package profile
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/fhir-fli/fhirpath-go/fhir"
+	"github.com/fhir-fli/fhirpath-go/internal/resource"
+)
+
+var (
+	ErrUnknownProfile = errors.New("unknown profile")
+)
+
+// Validator decides whether FHIR elements conform to profiles.
+type Validator interface {
+	// ConformsTo reports whether the element conforms to the profile with the
+	// given canonical URL. Implementations return an ErrUnknownProfile error
+	// if the profile cannot be resolved.
+	ConformsTo(element fhir.Base, structure string) (bool, error)
+}
+
+// Chain returns a Validator that asks each of the validators in order, and
+// returns the first answer of a validator that resolves the profile.
+// Validators that return an ErrUnknownProfile error are skipped; if none
+// resolves the profile, ErrUnknownProfile is returned. Any other error stops
+// the chain.
+func Chain(validators ...Validator) Validator {
+	return chain(validators)
+}
+
+// chain is a Validator that asks each of its validators in order.
+type chain []Validator
+
+// ConformsTo returns the answer of the first validator of the chain that
+// resolves the profile.
+func (c chain) ConformsTo(element fhir.Base, structure string) (bool, error) {
+	err := ErrUnknownProfile
+	for _, validator := range c {
+		var conforms bool
+		conforms, err = validator.ConformsTo(element, structure)
+		if !errors.Is(err, ErrUnknownProfile) {
+			return conforms, err
+		}
+	}
+	return false, err
+}
+
+// ClaimedProfiles is a Validator that considers a resource to conform to the
+// base StructureDefinition of its resource type, and to each of the profiles
+// claimed in its meta.profile element. Claimed profiles are trusted without
+// validating the content of the resource against them.
+//
+// Profiles that are claimed with a version ("url|version") match the structure
+// URL with or without that version. Elements that are not resources conform to
+// no profiles.
+var ClaimedProfiles Validator = claimedProfiles{}
+
+type claimedProfiles struct{}
+
+func (claimedProfiles) ConformsTo(element fhir.Base, structure string) (bool, error) {
+	res, ok := element.(fhir.Resource)
+	if !ok {
+		return false, nil
+	}
+	if structure == resource.TypeOf(res).StructureDefinitionURI().GetValue() {
+		return true, nil
+	}
+	for _, profile := range resource.ProfileStrings(res) {
+		url, _, _ := strings.Cut(profile, "|")
+		if structure == profile || structure == url {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,74 @@
+package profile_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/fhir-fli/fhirpath-go/fhir"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/profile"
+	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+)
+
+func TestClaimedProfiles(t *testing.T) {
+	const usCorePatient = "http://hl7.org/fhir/us/core/StructureDefinition/us-core-patient"
+	patient := &ppb.Patient{
+		Meta: &dtpb.Meta{
+			Profile: []*dtpb.Canonical{
+				{Value: usCorePatient},
+				{Value: "http://example.com/StructureDefinition/versioned|1.0"},
+			},
+		},
+	}
+
+	testCases := []struct {
+		name      string
+		element   fhir.Base
+		structure string
+		want      bool
+	}{
+		{"claimed profile", patient, usCorePatient, true},
+		{"claimed versioned profile", patient, "http://example.com/StructureDefinition/versioned|1.0", true},
+		{"claimed versioned profile without version", patient, "http://example.com/StructureDefinition/versioned", true},
+		{"claimed versioned profile with other version", patient, "http://example.com/StructureDefinition/versioned|2.0", false},
+		{"base resource type", patient, "http://hl7.org/fhir/StructureDefinition/Patient", true},
+		{"other resource type", patient, "http://hl7.org/fhir/StructureDefinition/Observation", false},
+		{"unclaimed profile", &ppb.Patient{}, usCorePatient, false},
+		{"non-resource element", fhir.String("value"), "http://hl7.org/fhir/StructureDefinition/string", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := profile.ClaimedProfiles.ConformsTo(tc.element, tc.structure)
+			if err != nil {
+				t.Fatalf("ConformsTo() returned unexpected error: %v", err)
+			}
+
+			if got != tc.want {
+				t.Errorf("ConformsTo(%v) = %v, want %v", tc.structure, got, tc.want)
+			}
+		})
+	}
+}
+
+// knownProfile is a Validator that only resolves the profile url, to which
+// every element conforms.
+type knownProfile string
+
+func (p knownProfile) ConformsTo(element fhir.Base, structure string) (bool, error) {
+	if structure != string(p) {
+		return false, profile.ErrUnknownProfile
+	}
+	return true, nil
+}
+
+func TestChain(t *testing.T) {
+	chain := profile.Chain(knownProfile("http://example.com/a"), knownProfile("http://example.com/b"))
+
+	if got, err := chain.ConformsTo(&ppb.Patient{}, "http://example.com/b"); err != nil || !got {
+		t.Errorf("Chain().ConformsTo() = %v, %v, want true, nil", got, err)
+	}
+	if _, err := chain.ConformsTo(&ppb.Patient{}, "http://example.com/c"); !errors.Is(err, profile.ErrUnknownProfile) {
+		t.Errorf("Chain().ConformsTo() returned error %v, want %v", err, profile.ErrUnknownProfile)
+	}
+}
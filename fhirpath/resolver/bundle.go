@@ -0,0 +1,109 @@
+package resolver
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fhir-fli/fhirpath-go/fhir"
+	"github.com/fhir-fli/fhirpath-go/internal/element/reference"
+	"github.com/fhir-fli/fhirpath-go/internal/resource"
+	"github.com/fhir-fli/fhirpath-go/pkg/containedresource"
+	bcrpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+)
+
+// Bundle returns a Resolver for references to the entries of the given Bundle,
+// following the rules in https://hl7.org/fhir/R4/bundle.html#references:
+//
+//   - Absolute references, including URNs such as "urn:uuid:...", resolve to the
+//     entry with a matching fullUrl.
+//   - Relative references, such as "Patient/123", resolve to the entry whose
+//     RESTful fullUrl ends with the reference. Since the referencing entry is not
+//     known, an error is returned if entries from different servers match.
+//   - Version-specific references additionally require the entry's
+//     meta.versionId to match.
+//
+// References that are not matched by fullUrl are treated as canonical
+// references, and resolve to the entry whose resource has a matching url and,
+// if given as "url|version", a matching version.
+//
+// If more than one entry matches, an ErrAmbiguousReference error is returned.
+func Bundle(bundle *bcrpb.Bundle) Resolver {
+	return Func(func(ref string) (fhir.Resource, error) {
+		if ref == "" || strings.HasPrefix(ref, "#") {
+			return nil, nil
+		}
+		if lit, err := reference.LiteralInfoFromURI(ref); err == nil {
+			matches := bundleEntriesMatching(bundle, lit)
+			if len(matches) > 0 {
+				return singleMatch(ref, matches)
+			}
+		}
+		url, version, _ := strings.Cut(ref, "|")
+		var matches []fhir.Resource
+		for _, entry := range bundle.GetEntry() {
+			res := containedresource.Unwrap(entry.GetResource())
+			if canonicalMatches(res, url, version) {
+				matches = append(matches, res)
+			}
+		}
+		return singleMatch(ref, matches)
+	})
+}
+
+// bundleEntriesMatching returns the resources of the bundle entries whose
+// fullUrl is the target of the literal reference.
+func bundleEntriesMatching(bundle *bcrpb.Bundle, lit *reference.LiteralInfo) []fhir.Resource {
+	identity, isREST := lit.Identity()
+	var matches []fhir.Resource
+	for _, entry := range bundle.GetEntry() {
+		res := containedresource.Unwrap(entry.GetResource())
+		fullURL := entry.GetFullUrl().GetValue()
+		if res == nil || fullURL == "" {
+			continue
+		}
+		if !isREST {
+			if fullURL == lit.URIString() {
+				matches = append(matches, res)
+			}
+			continue
+		}
+		entryLit, err := reference.LiteralInfoFromURI(fullURL)
+		if err != nil {
+			continue
+		}
+		entryIdentity, ok := entryLit.Identity()
+		if !ok || !entryIdentity.Unversioned().Equal(identity.Unversioned()) {
+			continue
+		}
+		if base := lit.ServiceBaseURL(); base != "" && base != entryLit.ServiceBaseURL() {
+			continue
+		}
+		if version, ok := identity.VersionID(); ok && version != resource.VersionID(res) {
+			continue
+		}
+		matches = append(matches, res)
+	}
+	return matches
+}
+
+// canonicalMatches returns true if res is a canonical resource with the given
+// url and, if non-empty, the given version.
+func canonicalMatches(res fhir.Resource, url, version string) bool {
+	canonical, ok := res.(fhir.CanonicalResource)
+	if !ok || canonical.GetUrl().GetValue() != url {
+		return false
+	}
+	return version == "" || canonical.GetVersion().GetValue() == version
+}
+
+// singleMatch returns the only resource of matches, or nil if there are none.
+func singleMatch(ref string, matches []fhir.Resource) (fhir.Resource, error) {
+	switch len(matches) {
+	case 0:
+		return nil, nil
+	case 1:
+		return matches[0], nil
+	default:
+		return nil, fmt.Errorf("%w: %s matches %v resources", ErrAmbiguousReference, ref, len(matches))
+	}
+}
@@ -0,0 +1,141 @@
+package resolver_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/fhir-fli/fhirpath-go/fhir"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/resolver"
+	"github.com/fhir-fli/fhirpath-go/pkg/containedresource"
+	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	bcrpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+	vspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/value_set_go_proto"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+func newEntry(fullURL string, res fhir.Resource) *bcrpb.Bundle_Entry {
+	entry := &bcrpb.Bundle_Entry{Resource: containedresource.Wrap(res)}
+	if fullURL != "" {
+		entry.FullUrl = fhir.URI(fullURL)
+	}
+	return entry
+}
+
+func TestBundle(t *testing.T) {
+	patient := &ppb.Patient{
+		Id:   fhir.ID("123"),
+		Meta: &dtpb.Meta{VersionId: fhir.ID("2")},
+	}
+	otherServerPatient := &ppb.Patient{Id: fhir.ID("123")}
+	transient := &ppb.Patient{}
+	noFullURL := &ppb.Patient{Id: fhir.ID("456")}
+	valueSet := &vspb.ValueSet{
+		Url:     fhir.URI("http://example.com/ValueSet/colors"),
+		Version: fhir.String("1.0"),
+	}
+	bundle := &bcrpb.Bundle{
+		Entry: []*bcrpb.Bundle_Entry{
+			newEntry("http://example.com/fhir/Patient/123", patient),
+			newEntry("urn:uuid:04121321-4af5-424c-a0e1-ed3aab1c349d", transient),
+			newEntry("", noFullURL),
+			newEntry("urn:uuid:e2b5c4d4-8d1e-4d6a-9c1e-0f8e3b2b1a7d", valueSet),
+		},
+	}
+	ambiguous := &bcrpb.Bundle{
+		Entry: []*bcrpb.Bundle_Entry{
+			newEntry("http://example.com/fhir/Patient/123", patient),
+			newEntry("http://other.com/fhir/Patient/123", otherServerPatient),
+		},
+	}
+
+	testCases := []struct {
+		name      string
+		bundle    *bcrpb.Bundle
+		reference string
+		want      fhir.Resource
+		wantErr   error
+	}{
+		{
+			name:      "absolute reference matches fullUrl",
+			bundle:    bundle,
+			reference: "http://example.com/fhir/Patient/123",
+			want:      patient,
+		},
+		{
+			name:      "absolute reference from other server is not resolved",
+			bundle:    bundle,
+			reference: "http://other.com/fhir/Patient/123",
+		},
+		{
+			name:      "relative reference matches RESTful fullUrl",
+			bundle:    bundle,
+			reference: "Patient/123",
+			want:      patient,
+		},
+		{
+			name:      "versioned reference matches meta.versionId",
+			bundle:    bundle,
+			reference: "Patient/123/_history/2",
+			want:      patient,
+		},
+		{
+			name:      "versioned reference with other version is not resolved",
+			bundle:    bundle,
+			reference: "http://example.com/fhir/Patient/123/_history/1",
+		},
+		{
+			name:      "urn reference matches fullUrl",
+			bundle:    bundle,
+			reference: "urn:uuid:04121321-4af5-424c-a0e1-ed3aab1c349d",
+			want:      transient,
+		},
+		{
+			name:      "entry without fullUrl is not resolved",
+			bundle:    bundle,
+			reference: "Patient/456",
+		},
+		{
+			name:      "canonical matches url",
+			bundle:    bundle,
+			reference: "http://example.com/ValueSet/colors",
+			want:      valueSet,
+		},
+		{
+			name:      "canonical matches url and version",
+			bundle:    bundle,
+			reference: "http://example.com/ValueSet/colors|1.0",
+			want:      valueSet,
+		},
+		{
+			name:      "canonical with other version is not resolved",
+			bundle:    bundle,
+			reference: "http://example.com/ValueSet/colors|2.0",
+		},
+		{
+			name:      "fragment is not resolved",
+			bundle:    bundle,
+			reference: "#123",
+		},
+		{
+			name:      "relative reference matching multiple servers is ambiguous",
+			bundle:    ambiguous,
+			reference: "Patient/123",
+			wantErr:   resolver.ErrAmbiguousReference,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := resolver.Bundle(tc.bundle).Resolve(tc.reference)
+
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Bundle().Resolve(%v) got error %v, want %v", tc.reference, err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("Bundle().Resolve(%v) returned unexpected diff (-want, +got)\n%s", tc.reference, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package resolver
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fhir-fli/fhirpath-go/fhir"
+	"github.com/fhir-fli/fhirpath-go/internal/resource"
+	"github.com/fhir-fli/fhirpath-go/pkg/containedresource"
+	bcrpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+)
+
+// Contained returns a Resolver for fragment references (e.g. "#med1") to the
+// resources contained within the given resource. The reference "#" resolves to
+// the containing resource itself, per https://hl7.org/fhir/R4/references.html#contained.
+//
+// The Resolver is a ContainerResolver, so when it's used by the resolve()
+// function, fragments are resolved in the resource that holds the reference
+// if that is known, and in the given resource otherwise. The given resource
+// may be nil, in which case fragments are only resolved at evaluation time.
+//
+// References that are not fragments are not resolved.
+func Contained(container fhir.Resource) Resolver {
+	return contained{container}
+}
+
+// contained is the ContainerResolver for fragment references.
+type contained struct {
+	container fhir.Resource
+}
+
+// Resolve resolves the fragment reference in the container given to
+// Contained.
+func (c contained) Resolve(reference string) (fhir.Resource, error) {
+	return c.ResolveIn(c.container, reference)
+}
+
+// ResolveIn resolves the fragment reference to a resource contained in
+// container, or to the container itself.
+func (contained) ResolveIn(container fhir.Resource, reference string) (fhir.Resource, error) {
+	fragment, ok := strings.CutPrefix(reference, "#")
+	if !ok || container == nil {
+		return nil, nil
+	}
+	if fragment == "" {
+		return container, nil
+	}
+	domain, ok := container.(fhir.DomainResource)
+	if !ok {
+		return nil, nil
+	}
+	for _, packed := range domain.GetContained() {
+		message, err := packed.UnmarshalNew()
+		if err != nil {
+			return nil, fmt.Errorf("unpacking contained resource: %w", err)
+		}
+		var res fhir.Resource
+		switch message := message.(type) {
+		case *bcrpb.ContainedResource:
+			res = containedresource.Unwrap(message)
+		case fhir.Resource:
+			res = message
+		}
+		if res != nil && resource.ID(res) == fragment {
+			return res, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,95 @@
+package resolver_test
+
+import (
+	"testing"
+
+	"github.com/fhir-fli/fhirpath-go/fhir"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/resolver"
+	"github.com/fhir-fli/fhirpath-go/pkg/containedresource"
+	mpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/medication_go_proto"
+	mrpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/medication_request_go_proto"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+func TestContained(t *testing.T) {
+	medication := &mpb.Medication{Id: fhir.ID("med1")}
+	packed, err := anypb.New(containedresource.Wrap(medication))
+	if err != nil {
+		t.Fatalf("anypb.New() returned unexpected error: %v", err)
+	}
+	request := &mrpb.MedicationRequest{
+		Id:        fhir.ID("123"),
+		Contained: []*anypb.Any{packed},
+	}
+
+	testCases := []struct {
+		name      string
+		container fhir.Resource
+		reference string
+		want      fhir.Resource
+	}{
+		{
+			name:      "fragment resolves to contained resource",
+			container: request,
+			reference: "#med1",
+			want:      medication,
+		},
+		{
+			name:      "empty fragment resolves to container",
+			container: request,
+			reference: "#",
+			want:      request,
+		},
+		{
+			name:      "unknown fragment is not resolved",
+			container: request,
+			reference: "#med2",
+		},
+		{
+			name:      "non-fragment reference is not resolved",
+			container: request,
+			reference: "Medication/med1",
+		},
+		{
+			name:      "nil container",
+			reference: "#med1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := resolver.Contained(tc.container).Resolve(tc.reference)
+			if err != nil {
+				t.Fatalf("Contained().Resolve(%v) returned unexpected error: %v", tc.reference, err)
+			}
+
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("Contained().Resolve(%v) returned unexpected diff (-want, +got)\n%s", tc.reference, diff)
+			}
+		})
+	}
+}
+
+func TestContained_ResolveIn_UsesGivenContainer(t *testing.T) {
+	medication := &mpb.Medication{Id: fhir.ID("med1")}
+	packed, err := anypb.New(containedresource.Wrap(medication))
+	if err != nil {
+		t.Fatalf("anypb.New() returned unexpected error: %v", err)
+	}
+	request := &mrpb.MedicationRequest{Contained: []*anypb.Any{packed}}
+	contained, ok := resolver.Contained(&mrpb.MedicationRequest{}).(resolver.ContainerResolver)
+	if !ok {
+		t.Fatalf("Contained() is not a ContainerResolver")
+	}
+
+	got, err := contained.ResolveIn(request, "#med1")
+	if err != nil {
+		t.Fatalf("Contained().ResolveIn() returned unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff(medication, got, protocmp.Transform()); diff != "" {
+		t.Errorf("Contained().ResolveIn() returned unexpected diff (-want, +got)\n%s", diff)
+	}
+}
@@ -0,0 +1,50 @@
+package resolver
+
+import (
+	"github.com/fhir-fli/fhirpath-go/fhir"
+	"github.com/fhir-fli/fhirpath-go/internal/element/reference"
+	"github.com/fhir-fli/fhirpath-go/internal/resource"
+)
+
+// Map is a Resolver that resolves relative and absolute RESTful references to
+// the resources held in the map, keyed by their identity. The service base URL
+// of absolute references is ignored.
+//
+// Version-specific references only resolve to a resource stored under the
+// versioned identity, whereas unversioned references resolve to a resource
+// stored under the unversioned identity.
+type Map map[resource.Identity]fhir.Resource
+
+// NewMap returns a Map holding the given resources. Each resource is stored
+// under both its versioned and unversioned identity, with later resources
+// taking precedence for the unversioned identity. Resources without an ID are
+// ignored.
+func NewMap(resources ...fhir.Resource) Map {
+	m := make(Map, len(resources))
+	for _, res := range resources {
+		identity, ok := resource.IdentityOf(res)
+		if !ok {
+			continue
+		}
+		m[*identity] = res
+		m[*identity.Unversioned()] = res
+	}
+	return m
+}
+
+// Resolve returns the resource held in the map for the identity that the
+// reference refers to, or nil if there is none.
+func (m Map) Resolve(ref string) (fhir.Resource, error) {
+	if ref == "" {
+		return nil, nil
+	}
+	lit, err := reference.LiteralInfoFromURI(ref)
+	if err != nil {
+		return nil, nil
+	}
+	identity, ok := lit.Identity()
+	if !ok {
+		return nil, nil
+	}
+	return m[*identity], nil
+}
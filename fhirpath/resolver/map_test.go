@@ -0,0 +1,74 @@
+package resolver_test
+
+import (
+	"testing"
+
+	"github.com/fhir-fli/fhirpath-go/fhir"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/resolver"
+	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+func TestMap(t *testing.T) {
+	patient := &ppb.Patient{
+		Id:   fhir.ID("123"),
+		Meta: &dtpb.Meta{VersionId: fhir.ID("2")},
+	}
+	m := resolver.NewMap(patient, &ppb.Patient{})
+
+	testCases := []struct {
+		name      string
+		reference string
+		want      fhir.Resource
+	}{
+		{
+			name:      "relative reference",
+			reference: "Patient/123",
+			want:      patient,
+		},
+		{
+			name:      "absolute reference",
+			reference: "http://example.com/fhir/Patient/123",
+			want:      patient,
+		},
+		{
+			name:      "versioned reference",
+			reference: "Patient/123/_history/2",
+			want:      patient,
+		},
+		{
+			name:      "other version is not resolved",
+			reference: "Patient/123/_history/1",
+		},
+		{
+			name:      "unknown resource is not resolved",
+			reference: "Patient/456",
+		},
+		{
+			name:      "fragment is not resolved",
+			reference: "#123",
+		},
+		{
+			name:      "canonical is not resolved",
+			reference: "http://example.com/ValueSet/colors|1.0",
+		},
+		{
+			name: "empty reference is not resolved",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := m.Resolve(tc.reference)
+			if err != nil {
+				t.Fatalf("Map.Resolve(%v) returned unexpected error: %v", tc.reference, err)
+			}
+
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("Map.Resolve(%v) returned unexpected diff (-want, +got)\n%s", tc.reference, diff)
+			}
+		})
+	}
+}
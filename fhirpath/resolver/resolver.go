@@ -0,0 +1,89 @@
+/*
+Package resolver provides the Resolver abstraction used by the FHIRPath
+resolve() function, along with built-in implementations for the common ways
+that references are resolved without a FHIR server.
+
+A Resolver is supplied to evaluation with the evalopts.Resolver option. If no
+Resolver is supplied, resolve() never finds a resource and yields an empty
+collection.
+*/
+package resolver
+
+import (
+	"errors"
+
+	"github.com/fhir-fli/fhirpath-go/fhir"
+)
+
+var (
+	ErrAmbiguousReference = errors.New("reference resolves to multiple resources")
+)
+
+// Resolver locates the resource that is the target of a reference.
+//
+// The reference is given in its string form, which is either the literal
+// reference held in a FHIR Reference (e.g. "Patient/123", "#contained-id", or
+// an absolute URL), or the value of a canonical or uri. A canonical may carry
+// a version, in the form "url|version".
+//
+// Implementations return a nil resource and nil error when the reference cannot
+// be resolved, and reserve errors for references that are invalid or that
+// fail to be looked up.
+type Resolver interface {
+	Resolve(reference string) (fhir.Resource, error)
+}
+
+// Func is an adapter to allow the use of an ordinary function as a Resolver.
+type Func func(reference string) (fhir.Resource, error)
+
+// Resolve calls f(reference).
+func (f Func) Resolve(reference string) (fhir.Resource, error) {
+	return f(reference)
+}
+
+// ContainerResolver is a Resolver for references that are relative to the
+// resource that holds them, such as fragment references to contained
+// resources.
+//
+// The resolve() function calls ResolveIn for fragment references, with the
+// innermost resource of the evaluation input that holds the reference, such
+// as a Bundle entry rather than the Bundle. Resolve is called instead if that
+// resource isn't known.
+type ContainerResolver interface {
+	Resolver
+	ResolveIn(container fhir.Resource, reference string) (fhir.Resource, error)
+}
+
+// Chain returns a Resolver that tries each of the resolvers in order, and
+// returns the first resource that is found. An error from any resolver stops
+// the chain. The returned Resolver is a ContainerResolver, which passes the
+// container to those resolvers that are ContainerResolvers.
+func Chain(resolvers ...Resolver) Resolver {
+	return chain(resolvers)
+}
+
+// chain is a Resolver that tries each of its resolvers in order.
+type chain []Resolver
+
+// Resolve returns the first resource that is resolved by the chain.
+func (c chain) Resolve(reference string) (fhir.Resource, error) {
+	return c.ResolveIn(nil, reference)
+}
+
+// ResolveIn returns the first resource that is resolved by the chain, passing
+// container to the resolvers that are ContainerResolvers, unless it is nil.
+func (c chain) ResolveIn(container fhir.Resource, reference string) (fhir.Resource, error) {
+	for _, resolver := range c {
+		var res fhir.Resource
+		var err error
+		if containerResolver, ok := resolver.(ContainerResolver); ok && container != nil {
+			res, err = containerResolver.ResolveIn(container, reference)
+		} else {
+			res, err = resolver.Resolve(reference)
+		}
+		if err != nil || res != nil {
+			return res, err
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,104 @@
+package resolver_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/fhir-fli/fhirpath-go/fhir"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/resolver"
+	"github.com/fhir-fli/fhirpath-go/pkg/containedresource"
+	mpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/medication_go_proto"
+	mrpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/medication_request_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+func TestChain(t *testing.T) {
+	first := &ppb.Patient{Id: fhir.ID("first")}
+	second := &ppb.Patient{Id: fhir.ID("second")}
+	errSome := errors.New("some error")
+	resolveTo := func(want string, res fhir.Resource) resolver.Resolver {
+		return resolver.Func(func(ref string) (fhir.Resource, error) {
+			if ref == want {
+				return res, nil
+			}
+			return nil, nil
+		})
+	}
+	failing := resolver.Func(func(string) (fhir.Resource, error) {
+		return nil, errSome
+	})
+
+	testCases := []struct {
+		name      string
+		resolvers []resolver.Resolver
+		reference string
+		want      fhir.Resource
+		wantErr   error
+	}{
+		{
+			name:      "no resolvers",
+			reference: "Patient/first",
+		},
+		{
+			name:      "first resolver matches",
+			resolvers: []resolver.Resolver{resolveTo("Patient/first", first), resolveTo("Patient/first", second)},
+			reference: "Patient/first",
+			want:      first,
+		},
+		{
+			name:      "later resolver matches",
+			resolvers: []resolver.Resolver{resolveTo("Patient/first", first), resolveTo("Patient/second", second)},
+			reference: "Patient/second",
+			want:      second,
+		},
+		{
+			name:      "no resolver matches",
+			resolvers: []resolver.Resolver{resolveTo("Patient/first", first)},
+			reference: "Patient/other",
+		},
+		{
+			name:      "error stops chain",
+			resolvers: []resolver.Resolver{failing, resolveTo("Patient/first", first)},
+			reference: "Patient/first",
+			wantErr:   errSome,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := resolver.Chain(tc.resolvers...).Resolve(tc.reference)
+
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Chain().Resolve(%v) got error %v, want %v", tc.reference, err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("Chain().Resolve(%v) returned unexpected diff (-want, +got)\n%s", tc.reference, diff)
+			}
+		})
+	}
+}
+
+func TestChain_ResolveIn_PassesContainer(t *testing.T) {
+	medication := &mpb.Medication{Id: fhir.ID("med1")}
+	packed, err := anypb.New(containedresource.Wrap(medication))
+	if err != nil {
+		t.Fatalf("anypb.New() returned unexpected error: %v", err)
+	}
+	request := &mrpb.MedicationRequest{Contained: []*anypb.Any{packed}}
+	chain, ok := resolver.Chain(resolver.NewMap(), resolver.Contained(nil)).(resolver.ContainerResolver)
+	if !ok {
+		t.Fatalf("Chain() is not a ContainerResolver")
+	}
+
+	got, err := chain.ResolveIn(request, "#med1")
+	if err != nil {
+		t.Fatalf("Chain().ResolveIn() returned unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff(medication, got, protocmp.Transform()); diff != "" {
+		t.Errorf("Chain().ResolveIn() returned unexpected diff (-want, +got)\n%s", diff)
+	}
+}
@@ -0,0 +1,158 @@
+package system
+
+import (
+	"time"
+
+	"github.com/fhir-fli/fhirpath-go/internal/units"
+)
+
+// unitDigits maps the units supported by calendar arithmetic to the precision
+// in DateTime digits that a value must have to be measured in them.
+var unitDigits = map[units.Time]int{
+	units.Years:        4,
+	units.Months:       6,
+	units.Weeks:        8,
+	units.Days:         8,
+	units.Hours:        10,
+	units.Minutes:      12,
+	units.Seconds:      14,
+	units.Milliseconds: 17,
+}
+
+// unitMilliseconds maps the units of fixed length to their length in
+// milliseconds.
+var unitMilliseconds = map[units.Time]int64{
+	units.Weeks:        int64(7 * 24 * time.Hour / time.Millisecond),
+	units.Days:         int64(24 * time.Hour / time.Millisecond),
+	units.Hours:        int64(time.Hour / time.Millisecond),
+	units.Minutes:      int64(time.Minute / time.Millisecond),
+	units.Seconds:      int64(time.Second / time.Millisecond),
+	units.Milliseconds: 1,
+}
+
+// Duration returns the number of whole calendar periods of unit from dt to
+// other, which is negative if other is earlier than dt. For example, the
+// duration from 2000-03-22 to 2024-03-21 is 23 years. Returns false if either
+// value is less precise than unit, or unit isn't supported.
+func (dt DateTime) Duration(other DateTime, unit units.Time) (int64, bool) {
+	start, end, ok := calendarOperands(dt.dateTime, dt.Precision(), other.dateTime, other.Precision(), unit)
+	if !ok {
+		return 0, false
+	}
+	return wholePeriods(start, end, unit), true
+}
+
+// Difference returns the number of boundaries of unit that are crossed from
+// dt to other, which is negative if other is earlier than dt. For example,
+// the difference from 2023-12-31 to 2024-01-01 is 1 year. Returns false if
+// either value is less precise than unit, or unit isn't supported.
+func (dt DateTime) Difference(other DateTime, unit units.Time) (int64, bool) {
+	start, end, ok := calendarOperands(dt.dateTime, dt.Precision(), other.dateTime, other.Precision(), unit)
+	if !ok {
+		return 0, false
+	}
+	return wholePeriods(startOfPeriod(start, unit), startOfPeriod(end, unit), unit), true
+}
+
+// Duration returns the number of whole periods of unit from t to other, which
+// is negative if other is earlier than t. Returns false if either value is
+// less precise than unit, or unit is longer than an hour.
+func (t Time) Duration(other Time, unit units.Time) (int64, bool) {
+	if unitDigits[unit] < dateTimeDigits[dtHourLayout] {
+		return 0, false
+	}
+	start, end, ok := calendarOperands(t.time, t.dateTimePrecision(), other.time, other.dateTimePrecision(), unit)
+	if !ok {
+		return 0, false
+	}
+	return wholePeriods(start, end, unit), true
+}
+
+// Difference returns the number of boundaries of unit that are crossed from t
+// to other, which is negative if other is earlier than t. Returns false if
+// either value is less precise than unit, or unit is longer than an hour.
+func (t Time) Difference(other Time, unit units.Time) (int64, bool) {
+	if unitDigits[unit] < dateTimeDigits[dtHourLayout] {
+		return 0, false
+	}
+	start, end, ok := calendarOperands(t.time, t.dateTimePrecision(), other.time, other.dateTimePrecision(), unit)
+	if !ok {
+		return 0, false
+	}
+	return wholePeriods(startOfPeriod(start, unit), startOfPeriod(end, unit), unit), true
+}
+
+// dateTimePrecision returns the precision of t in digits, as if it were the
+// time component of a DateTime.
+func (t Time) dateTimePrecision() int {
+	return t.Precision() + dateDigits[dayLayout]
+}
+
+// calendarOperands returns the times a and b, with precisions in DateTime
+// digits, normalized for calendar arithmetic in unit. Values with a time
+// component are compared in the timezone offset of a, so that their timezones
+// are accounted for and calendar boundaries are those of the first operand,
+// while values without one are compared as calendar dates. Returns false if
+// either value is less precise than unit, or unit isn't supported.
+func calendarOperands(a time.Time, aDigits int, b time.Time, bDigits int, unit units.Time) (time.Time, time.Time, bool) {
+	digits, ok := unitDigits[unit]
+	if !ok || aDigits < digits || bDigits < digits {
+		return time.Time{}, time.Time{}, false
+	}
+	loc := time.UTC
+	if aDigits >= dateTimeDigits[dtHourLayout] {
+		loc = a.Location()
+	}
+	return normalizeCalendar(a, aDigits, loc), normalizeCalendar(b, bDigits, loc), true
+}
+
+// normalizeCalendar returns t in loc if it has a time component according to
+// its precision in digits, and its calendar date at midnight in loc
+// otherwise.
+func normalizeCalendar(t time.Time, digits int, loc *time.Location) time.Time {
+	if digits >= dateTimeDigits[dtHourLayout] {
+		return t.In(loc)
+	}
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, loc)
+}
+
+// wholePeriods returns the number of whole periods of unit from start to end.
+// Months and years are calendar periods, such that a month from 2020-01-31
+// ends on 2020-02-29, as when adding a Quantity to a Date.
+func wholePeriods(start, end time.Time, unit units.Time) int64 {
+	if unit == units.Months || unit == units.Years {
+		months := (end.Year()-start.Year())*12 + int(end.Month()-start.Month())
+		// Step back if the last month hasn't been completed.
+		if months > 0 && addMonth(start, months).After(end) {
+			months--
+		} else if months < 0 && addMonth(start, months).Before(end) {
+			months++
+		}
+		if unit == units.Years {
+			return int64(months / 12)
+		}
+		return int64(months)
+	}
+	return (end.UnixMilli() - start.UnixMilli()) / unitMilliseconds[unit]
+}
+
+// startOfPeriod truncates t to the start of the period of unit that holds it,
+// in the location of t. Weeks are measured in days, so that their boundaries
+// are those of days.
+func startOfPeriod(t time.Time, unit units.Time) time.Time {
+	year, month, day := t.Date()
+	switch unit {
+	case units.Years:
+		return time.Date(year, time.January, 1, 0, 0, 0, 0, t.Location())
+	case units.Months:
+		return time.Date(year, month, 1, 0, 0, 0, 0, t.Location())
+	case units.Weeks, units.Days:
+		return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+	case units.Hours:
+		return time.Date(year, month, day, t.Hour(), 0, 0, 0, t.Location())
+	case units.Minutes:
+		return time.Date(year, month, day, t.Hour(), t.Minute(), 0, 0, t.Location())
+	}
+	return t.Truncate(time.Duration(unitMilliseconds[unit]) * time.Millisecond)
+}
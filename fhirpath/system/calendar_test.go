@@ -0,0 +1,180 @@
+package system_test
+
+import (
+	"testing"
+
+	"github.com/fhir-fli/fhirpath-go/fhirpath/system"
+	"github.com/fhir-fli/fhirpath-go/internal/units"
+)
+
+func TestDateTimeCalendarArithmetic(t *testing.T) {
+	testCases := []struct {
+		name           string
+		start          system.DateTime
+		end            system.DateTime
+		unit           units.Time
+		wantDuration   int64
+		wantDifference int64
+	}{
+		{
+			name:           "years across leap day",
+			start:          system.MustParseDateTime("2020-02-29T"),
+			end:            system.MustParseDateTime("2021-02-28T"),
+			unit:           units.Years,
+			wantDuration:   1,
+			wantDifference: 1,
+		},
+		{
+			name:           "month from end of month",
+			start:          system.MustParseDateTime("2020-01-31T"),
+			end:            system.MustParseDateTime("2020-02-29T"),
+			unit:           units.Months,
+			wantDuration:   1,
+			wantDifference: 1,
+		},
+		{
+			name:           "incomplete month",
+			start:          system.MustParseDateTime("2020-01-15T"),
+			end:            system.MustParseDateTime("2020-02-14T"),
+			unit:           units.Months,
+			wantDuration:   0,
+			wantDifference: 1,
+		},
+		{
+			name:           "years across new year",
+			start:          system.MustParseDateTime("2023-12-31T"),
+			end:            system.MustParseDateTime("2024-01-01T"),
+			unit:           units.Years,
+			wantDuration:   0,
+			wantDifference: 1,
+		},
+		{
+			name:           "negative years",
+			start:          system.MustParseDateTime("2024-03-21T"),
+			end:            system.MustParseDateTime("2000-03-22T"),
+			unit:           units.Years,
+			wantDuration:   -23,
+			wantDifference: -24,
+		},
+		{
+			name:           "weeks",
+			start:          system.MustParseDateTime("2024-01-01T"),
+			end:            system.MustParseDateTime("2024-01-20T"),
+			unit:           units.Weeks,
+			wantDuration:   2,
+			wantDifference: 2,
+		},
+		{
+			name:           "hours across timezones",
+			start:          system.MustParseDateTime("2024-01-01T10:30:00+02:00"),
+			end:            system.MustParseDateTime("2024-01-01T10:00:00Z"),
+			unit:           units.Hours,
+			wantDuration:   1,
+			wantDifference: 2,
+		},
+		{
+			name:           "days across timezones",
+			start:          system.MustParseDateTime("2024-01-01T23:00:00-05:00"),
+			end:            system.MustParseDateTime("2024-01-02T01:00:00Z"),
+			unit:           units.Days,
+			wantDuration:   0,
+			wantDifference: 0,
+		},
+		{
+			name:           "days in timezone of start",
+			start:          system.MustParseDateTime("2020-01-01T23:00:00+10:00"),
+			end:            system.MustParseDateTime("2020-01-02T01:00:00+10:00"),
+			unit:           units.Days,
+			wantDuration:   0,
+			wantDifference: 1,
+		},
+		{
+			name:           "days in timezone of start across timezones",
+			start:          system.MustParseDateTime("2020-01-01T23:00:00+10:00"),
+			end:            system.MustParseDateTime("2020-01-01T15:00:00Z"),
+			unit:           units.Days,
+			wantDuration:   0,
+			wantDifference: 1,
+		},
+		{
+			name:           "hours in half hour timezone",
+			start:          system.MustParseDateTime("2024-01-01T10:15:00+05:30"),
+			end:            system.MustParseDateTime("2024-01-01T10:45:00+05:30"),
+			unit:           units.Hours,
+			wantDuration:   0,
+			wantDifference: 0,
+		},
+		{
+			name:           "milliseconds",
+			start:          system.MustParseDateTime("2024-01-01T10:00:00.250Z"),
+			end:            system.MustParseDateTime("2024-01-01T10:00:01.500Z"),
+			unit:           units.Milliseconds,
+			wantDuration:   1250,
+			wantDifference: 1250,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			duration, ok := tc.start.Duration(tc.end, tc.unit)
+			if !ok {
+				t.Fatalf("Duration(%v, %v) returned false", tc.end, tc.unit)
+			}
+			if duration != tc.wantDuration {
+				t.Errorf("Duration(%v, %v) = %v, want %v", tc.end, tc.unit, duration, tc.wantDuration)
+			}
+			difference, ok := tc.start.Difference(tc.end, tc.unit)
+			if !ok {
+				t.Fatalf("Difference(%v, %v) returned false", tc.end, tc.unit)
+			}
+			if difference != tc.wantDifference {
+				t.Errorf("Difference(%v, %v) = %v, want %v", tc.end, tc.unit, difference, tc.wantDifference)
+			}
+		})
+	}
+}
+
+func TestDateTimeCalendarArithmetic_BelowPrecision(t *testing.T) {
+	testCases := []struct {
+		name  string
+		start system.DateTime
+		end   system.DateTime
+		unit  units.Time
+	}{
+		{"start below precision", system.MustParseDateTime("2020-01T"), system.MustParseDateTime("2020-03-01T"), units.Days},
+		{"end below precision", system.MustParseDateTime("2020-01-01T10:00"), system.MustParseDateTime("2020-01-01T"), units.Hours},
+		{"unsupported unit", system.MustParseDateTime("2020T"), system.MustParseDateTime("2021T"), units.Nanoseconds},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, ok := tc.start.Duration(tc.end, tc.unit); ok {
+				t.Errorf("Duration(%v, %v) returned true, want false", tc.end, tc.unit)
+			}
+			if _, ok := tc.start.Difference(tc.end, tc.unit); ok {
+				t.Errorf("Difference(%v, %v) returned true, want false", tc.end, tc.unit)
+			}
+		})
+	}
+}
+
+func TestTimeCalendarArithmetic(t *testing.T) {
+	start := system.MustParseTime("10:30:00")
+	end := system.MustParseTime("12:15:00")
+
+	if got, ok := start.Duration(end, units.Hours); !ok || got != 1 {
+		t.Errorf("Duration(%v, hours) = (%v, %v), want (1, true)", end, got, ok)
+	}
+	if got, ok := start.Difference(end, units.Hours); !ok || got != 2 {
+		t.Errorf("Difference(%v, hours) = (%v, %v), want (2, true)", end, got, ok)
+	}
+	if got, ok := end.Duration(start, units.Minutes); !ok || got != -105 {
+		t.Errorf("Duration(%v, minutes) = (%v, %v), want (-105, true)", start, got, ok)
+	}
+	if _, ok := start.Duration(end, units.Days); ok {
+		t.Errorf("Duration(%v, days) returned true, want false", end)
+	}
+	if _, ok := system.MustParseTime("10:30").Duration(end, units.Seconds); ok {
+		t.Errorf("Duration(%v, seconds) below precision returned true, want false", end)
+	}
+}
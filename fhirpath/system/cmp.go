@@ -38,6 +38,21 @@ func TryEqual(lhs, rhs Any) (bool, bool) {
 	return lhs == rhs, true
 }
 
+// Equivalent compares two FHIRPath System types for equivalence. Unlike
+// equality, equivalence always yields a value.
+//
+// See https://hl7.org/fhirpath/n1/#equivalence
+//
+// For system types that define a custom "Equivalent" function, this will call
+// the underlying function. Otherwise, this falls back to equality.
+func Equivalent(lhs, rhs Any) bool {
+	lhs, rhs = Normalize(lhs, rhs), Normalize(rhs, lhs)
+	if eq, ok := lhs.(interface{ Equivalent(Any) bool }); ok {
+		return eq.Equivalent(rhs)
+	}
+	return Equal(lhs, rhs)
+}
+
 func callTryEqual(lhs, rhs Any) (bool, bool, bool) {
 	if eq, ok := reflect.TypeOf(lhs).MethodByName("TryEqual"); ok {
 		funcType := eq.Func.Type()
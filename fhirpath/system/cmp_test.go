@@ -0,0 +1,121 @@
+package system_test
+
+import (
+	"testing"
+
+	"github.com/fhir-fli/fhirpath-go/fhirpath/system"
+)
+
+func TestEquivalent(t *testing.T) {
+	testCases := []struct {
+		name string
+		lhs  system.Any
+		rhs  system.Any
+		want bool
+	}{
+		{
+			name: "equal booleans",
+			lhs:  system.Boolean(true),
+			rhs:  system.Boolean(true),
+			want: true,
+		},
+		{
+			name: "strings differing in case",
+			lhs:  system.String("Hello World"),
+			rhs:  system.String("hello world"),
+			want: true,
+		},
+		{
+			name: "strings differing in whitespace",
+			lhs:  system.String("  hello \t\nworld "),
+			rhs:  system.String("hello world"),
+			want: true,
+		},
+		{
+			name: "different strings",
+			lhs:  system.String("hello world"),
+			rhs:  system.String("helloworld"),
+			want: false,
+		},
+		{
+			name: "integer and decimal",
+			lhs:  system.Integer(1),
+			rhs:  system.MustParseDecimal("1.0"),
+			want: true,
+		},
+		{
+			name: "decimals compared at least precision",
+			lhs:  system.MustParseDecimal("0.6666667"),
+			rhs:  system.MustParseDecimal("0.67"),
+			want: true,
+		},
+		{
+			name: "decimal trailing zeroes are ignored in precision",
+			lhs:  system.MustParseDecimal("1.10"),
+			rhs:  system.MustParseDecimal("1.14"),
+			want: true,
+		},
+		{
+			name: "different decimals at least precision",
+			lhs:  system.MustParseDecimal("1.2"),
+			rhs:  system.MustParseDecimal("1.26"),
+			want: false,
+		},
+		{
+			name: "equal dates",
+			lhs:  system.MustParseDate("2012-01-01"),
+			rhs:  system.MustParseDate("2012-01-01"),
+			want: true,
+		},
+		{
+			name: "dates with different precision",
+			lhs:  system.MustParseDate("2012-01"),
+			rhs:  system.MustParseDate("2012-01-01"),
+			want: false,
+		},
+		{
+			name: "date times with different precision",
+			lhs:  system.MustParseDateTime("2012-01-01T10:30"),
+			rhs:  system.MustParseDateTime("2012-01-01T10:30:00"),
+			want: false,
+		},
+		{
+			name: "date and date time",
+			lhs:  system.MustParseDate("2012-01-01"),
+			rhs:  system.MustParseDateTime("2012-01-01T"),
+			want: true,
+		},
+		{
+			name: "times with different precision",
+			lhs:  system.MustParseTime("10:30"),
+			rhs:  system.MustParseTime("10:30:00"),
+			want: false,
+		},
+		{
+			name: "quantities with equivalent values",
+			lhs:  system.MustParseQuantity("4.0", "mg"),
+			rhs:  system.MustParseQuantity("4.04", "mg"),
+			want: true,
+		},
+		{
+			name: "quantities with different units",
+			lhs:  system.MustParseQuantity("4", "mg"),
+			rhs:  system.MustParseQuantity("4", "g"),
+			want: false,
+		},
+		{
+			name: "mismatched types",
+			lhs:  system.String("1"),
+			rhs:  system.Integer(1),
+			want: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := system.Equivalent(tc.lhs, tc.rhs); got != tc.want {
+				t.Errorf("Equivalent(%v, %v) = %v, want %v", tc.lhs, tc.rhs, got, tc.want)
+			}
+		})
+	}
+}
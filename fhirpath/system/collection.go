@@ -9,6 +9,7 @@ import (
 	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
 	"github.com/shopspring/decimal"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
 var (
@@ -54,10 +55,16 @@ func (c Collection) TryEqual(other Collection) (bool, bool) {
 			return true, true
 		}
 		primitiveOne, err := From(c[i])
+		if errors.Is(err, ErrNoValue) {
+			return false, false
+		}
 		if err != nil {
 			return false, true
 		}
 		primitiveTwo, err := From(other[i])
+		if errors.Is(err, ErrNoValue) {
+			return false, false
+		}
 		if err != nil {
 			return false, true
 		}
@@ -74,9 +81,98 @@ func (c Collection) TryEqual(other Collection) (bool, bool) {
 	return true, true
 }
 
+// Equivalent compares this collection to the supplied collection for
+// equivalence. Collections are equivalent if they are the same length, and
+// every item in c is equivalent to a distinct item in other, irrespective of
+// order. Two empty collections are equivalent.
+//
+// Primitive values are compared using System type equivalence, while complex
+// types are equivalent if all of their child elements are equivalent.
+//
+// See https://hl7.org/fhirpath/N1/#equivalence
+func (c Collection) Equivalent(other Collection) bool {
+	if len(c) != len(other) {
+		return false
+	}
+	matched := make([]bool, len(other))
+	for _, lhs := range c {
+		found := false
+		for j, rhs := range other {
+			if matched[j] || !equivalentValues(lhs, rhs) {
+				continue
+			}
+			matched[j], found = true, true
+			break
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// equivalentValues compares two collection items for equivalence.
+func equivalentValues(lhs, rhs any) bool {
+	l, lerr := From(lhs)
+	r, rerr := From(rhs)
+	if lerr == nil && rerr == nil {
+		return Equivalent(l, r)
+	}
+	if lerr == nil || rerr == nil {
+		return false
+	}
+	lmsg, lok := lhs.(proto.Message)
+	rmsg, rok := rhs.(proto.Message)
+	return lok && rok && equivalentMessages(lmsg, rmsg)
+}
+
+// equivalentMessages compares two complex types member-wise, such that
+// the messages are equivalent if they are the same type, and all populated
+// fields are equivalent.
+func equivalentMessages(lhs, rhs proto.Message) bool {
+	l, r := lhs.ProtoReflect(), rhs.ProtoReflect()
+	if l.Descriptor().FullName() != r.Descriptor().FullName() {
+		return false
+	}
+	fields := l.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		if l.Has(field) != r.Has(field) {
+			return false
+		}
+		if !l.Has(field) {
+			continue
+		}
+		lval, rval := l.Get(field), r.Get(field)
+		switch {
+		case field.IsList() && field.Kind() == protoreflect.MessageKind:
+			if !listToCollection(lval.List()).Equivalent(listToCollection(rval.List())) {
+				return false
+			}
+		case field.Kind() == protoreflect.MessageKind:
+			if !equivalentValues(lval.Message().Interface(), rval.Message().Interface()) {
+				return false
+			}
+		default:
+			if !lval.Equal(rval) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func listToCollection(list protoreflect.List) Collection {
+	result := make(Collection, 0, list.Len())
+	for i := 0; i < list.Len(); i++ {
+		result = append(result, list.Get(i).Message().Interface())
+	}
+	return result
+}
+
 // ToSingletonBoolean evaluates a collection as a boolean with singleton evaluation of
 // collection rules. Returns a collection containing a single Boolean, or empty if the
-// input is empty.
+// input is empty or holds a FHIR primitive without a value.
 func (c Collection) ToSingletonBoolean() ([]Boolean, error) {
 	length := len(c)
 	if length == 0 {
@@ -85,7 +181,10 @@ func (c Collection) ToSingletonBoolean() ([]Boolean, error) {
 	if length > 1 {
 		return nil, fmt.Errorf("collection can't evaluate to bool, contains %v elements", length)
 	}
-	val, _ := From(c[0])
+	val, err := From(c[0])
+	if errors.Is(err, ErrNoValue) {
+		return []Boolean{}, nil
+	}
 	if boolean, ok := val.(Boolean); ok {
 		return []Boolean{boolean}, nil
 	}
@@ -208,6 +307,23 @@ func (c Collection) Contains(value any) bool {
 	return false
 }
 
+// Union merges this collection with the other collection, eliminating any
+// duplicate values. Values are compared with the same equality semantics as
+// Contains, so System types and FHIR protos may be mixed freely. The order of
+// the first occurrence of each value is preserved.
+//
+// See https://hl7.org/fhirpath/N1/#unionother-collection
+func (c Collection) Union(other Collection) Collection {
+	result := Collection{}
+	for _, value := range append(c[:len(c):len(c)], other...) {
+		if result.Contains(value) {
+			continue
+		}
+		result = append(result, value)
+	}
+	return result
+}
+
 func (c Collection) containsSystem(value Any) bool {
 	for _, v := range c {
 		sys, err := From(v)
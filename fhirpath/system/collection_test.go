@@ -7,9 +7,11 @@ import (
 	"google.golang.org/protobuf/proto"
 
 	"github.com/fhir-fli/fhirpath-go/fhirpath/system"
+	"github.com/fhir-fli/fhirpath-go/internal/element/extension"
 	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
 	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
 	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
 )
 
 func TestEqual_ReturnsResult(t *testing.T) {
@@ -96,6 +98,96 @@ func TestEqual_ReturnsResult(t *testing.T) {
 	}
 }
 
+func TestEquivalent_ReturnsResult(t *testing.T) {
+	nameOne := &dtpb.HumanName{
+		Family: fhir.String("SMITH"),
+		Given:  []*dtpb.String{fhir.String("John"), fhir.String("Paul")},
+	}
+	nameTwo := &dtpb.HumanName{
+		Family: fhir.String("smith "),
+		Given:  []*dtpb.String{fhir.String("paul"), fhir.String("john")},
+	}
+	nameThree := &dtpb.HumanName{
+		Family: fhir.String("Smith"),
+	}
+
+	testCases := []struct {
+		name            string
+		leftCollection  system.Collection
+		rightCollection system.Collection
+		want            bool
+	}{
+		{
+			name:            "empty collections",
+			leftCollection:  system.Collection{},
+			rightCollection: system.Collection{},
+			want:            true,
+		},
+		{
+			name:            "one empty collection",
+			leftCollection:  system.Collection{},
+			rightCollection: system.Collection{system.String("a")},
+			want:            false,
+		},
+		{
+			name:            "mismatched collection lengths",
+			leftCollection:  system.Collection{system.String("a")},
+			rightCollection: system.Collection{system.String("a"), system.String("a")},
+			want:            false,
+		},
+		{
+			name:            "unordered collections",
+			leftCollection:  system.Collection{system.String("a"), system.String("b")},
+			rightCollection: system.Collection{system.String("B"), system.String("A")},
+			want:            true,
+		},
+		{
+			name:            "collections with duplicates",
+			leftCollection:  system.Collection{system.String("a"), system.String("a")},
+			rightCollection: system.Collection{system.String("a"), system.String("b")},
+			want:            false,
+		},
+		{
+			name:            "proto and system types",
+			leftCollection:  system.Collection{fhir.String("Hello"), fhir.Decimal(1.5)},
+			rightCollection: system.Collection{system.MustParseDecimal("1.50"), system.String("hello")},
+			want:            true,
+		},
+		{
+			name:            "equivalent complex types",
+			leftCollection:  system.Collection{nameOne},
+			rightCollection: system.Collection{nameTwo},
+			want:            true,
+		},
+		{
+			name:            "complex types with missing fields",
+			leftCollection:  system.Collection{nameOne},
+			rightCollection: system.Collection{nameThree},
+			want:            false,
+		},
+		{
+			name:            "complex and primitive types",
+			leftCollection:  system.Collection{nameThree},
+			rightCollection: system.Collection{system.String("Smith")},
+			want:            false,
+		},
+		{
+			name:            "different complex types",
+			leftCollection:  system.Collection{&ppb.Patient{Id: fhir.ID("123")}},
+			rightCollection: system.Collection{&dtpb.Reference{Id: fhir.String("123")}},
+			want:            false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.leftCollection.Equivalent(tc.rightCollection); got != tc.want {
+				t.Errorf("Collection.Equivalent returned incorrect result, got: %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
 func TestToSingletonBoolean_ConvertsToBool(t *testing.T) {
 	testCases := []struct {
 		name            string
@@ -128,6 +220,15 @@ func TestToSingletonBoolean_ConvertsToBool(t *testing.T) {
 			inputCollection: system.Collection{},
 			want:            []system.Boolean{},
 		},
+		{
+			name: "returns empty on primitive without value",
+			inputCollection: system.Collection{&dtpb.Boolean{
+				Extension: []*dtpb.Extension{
+					extension.New("https://g.co/fhir/StructureDefinition/primitiveHasNoValue", fhir.Boolean(true)),
+				},
+			}},
+			want: []system.Boolean{},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -318,3 +419,59 @@ func TestContains(t *testing.T) {
 		})
 	}
 }
+
+func TestCollection_Union(t *testing.T) {
+	patient := &ppb.Patient{
+		Name: []*dtpb.HumanName{
+			{Family: fhir.String("Bazington")},
+		},
+	}
+	testCases := []struct {
+		name  string
+		left  system.Collection
+		right system.Collection
+		want  system.Collection
+	}{
+		{
+			name:  "Both collections empty",
+			left:  system.Collection{},
+			right: system.Collection{},
+			want:  system.Collection{},
+		}, {
+			name:  "Disjoint collections are concatenated",
+			left:  system.Collection{system.Integer(1), system.Integer(2)},
+			right: system.Collection{system.Integer(3)},
+			want:  system.Collection{system.Integer(1), system.Integer(2), system.Integer(3)},
+		}, {
+			name:  "Duplicates within a collection are eliminated",
+			left:  system.Collection{system.Integer(1), system.Integer(1)},
+			right: system.Collection{},
+			want:  system.Collection{system.Integer(1)},
+		}, {
+			name:  "Duplicates across collections are eliminated",
+			left:  system.Collection{system.Integer(1), system.Integer(2)},
+			right: system.Collection{system.Integer(2), system.Integer(3)},
+			want:  system.Collection{system.Integer(1), system.Integer(2), system.Integer(3)},
+		}, {
+			name:  "Implicitly converted values are equal",
+			left:  system.Collection{fhir.String("Hello"), system.Integer(1)},
+			right: system.Collection{system.String("Hello"), system.MustParseDecimal("1.0")},
+			want:  system.Collection{fhir.String("Hello"), system.Integer(1)},
+		}, {
+			name:  "Proto values are compared structurally",
+			left:  system.Collection{patient, system.String("Foo")},
+			right: system.Collection{proto.Clone(patient), &ppb.Patient{}},
+			want:  system.Collection{patient, system.String("Foo"), &ppb.Patient{}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.left.Union(tc.right)
+
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("Collection.Union(%v) returned unexpected diff (-want, +got):\n%s", tc.name, diff)
+			}
+		})
+	}
+}
@@ -10,6 +10,7 @@ var (
 	ErrMismatchedPrecision = errors.New("mismatched precision")
 	ErrMismatchedUnit      = errors.New("mismatched unit")
 	ErrIntOverflow         = errors.New("operation resulted in integer overflow")
+	ErrDivisionByZero      = errors.New("division by zero")
 )
 
 // Type names.
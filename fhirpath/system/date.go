@@ -71,6 +71,7 @@ func DateFromProto(proto *dtpb.Date) (Date, error) {
 // ToProtoDate returns a proto Date based on a system Date.
 func (d Date) ToProtoDate() *dtpb.Date {
 	date := fhir.Date(d.date)
+	date.Timezone = fhirconv.TimeToTimezone(d.date)
 	var p dtpb.Date_Precision
 	switch d.l {
 	case dayLayout:
@@ -116,6 +117,14 @@ func (d Date) TryEqual(input Any) (bool, bool) {
 	return false, false
 }
 
+// Equivalent returns true if input is a Date with the same value and
+// precision as d. Unlike TryEqual, differing precisions result in false rather
+// than no value.
+func (d Date) Equivalent(input Any) bool {
+	result, ok := d.TryEqual(input)
+	return result && ok
+}
+
 // Less returns true if the value of d is less than input.(Date).
 // Compares component by component, and returns an error if there is a
 // precision mismatch. If input is not a Date, returns an error.
@@ -142,7 +151,9 @@ func (d Date) Less(input Any) (Boolean, error) {
 	return false, ErrMismatchedPrecision
 }
 
-// Add returns the result of d + input. Returns an
+// Add returns the result of d + input. Calendar durations follow calendar
+// rules, while definite durations are added as their whole number of days,
+// such that @2020-01-01 + 1 'a' is @2020-12-31. Returns an
 // error if it is not a valid time-valued quantity.
 func (d Date) Add(input Quantity) (Date, error) {
 	var result time.Time
@@ -158,7 +169,11 @@ func (d Date) Add(input Quantity) (Date, error) {
 	case "day", "days":
 		result = d.date.AddDate(0, 0, value)
 	default:
-		return Date{}, fmt.Errorf("%w: can't add to date", ErrMismatchedUnit)
+		days, err := input.definiteIntPart("d")
+		if err != nil {
+			return Date{}, err
+		}
+		result = d.date.AddDate(0, 0, days)
 	}
 
 	// Reformat to truncate date to initial precision. This causes the addition result
@@ -205,7 +220,11 @@ func (d Date) Sub(input Quantity) (Date, error) {
 	case "day", "days":
 		result = d.date.AddDate(0, 0, value)
 	default:
-		return Date{}, fmt.Errorf("%w: can't add to date", ErrMismatchedUnit)
+		days, err := input.definiteIntPart("d")
+		if err != nil {
+			return Date{}, err
+		}
+		result = d.date.AddDate(0, 0, -days)
 	}
 
 	return Date{result, d.l}, nil
@@ -267,3 +286,48 @@ func addYear(t time.Time, y int) time.Time {
 	}
 	return added
 }
+
+// Precision returns the precision of d in digits, being 4, 6 or 8 for year,
+// month and day precision respectively.
+func (d Date) Precision() int {
+	return dateDigits[d.l]
+}
+
+// LowBoundary returns the earliest Date that d could represent, to the given
+// precision in digits. For example, the low boundary of 2014 to a precision of
+// 6 is 2014-01. Returns false if precision is not a valid Date precision.
+func (d Date) LowBoundary(precision int) (Date, bool) {
+	l, ok := layoutWithDigits(dateDigits, precision, false)
+	if !ok {
+		return Date{}, false
+	}
+	return Date{truncateTo(d.date, l), l}, true
+}
+
+// HighBoundary returns the latest Date that d could represent, to the given
+// precision in digits. For example, the high boundary of 2014 to a precision
+// of 6 is 2014-12. Returns false if precision is not a valid Date precision.
+func (d Date) HighBoundary(precision int) (Date, bool) {
+	l, ok := layoutWithDigits(dateDigits, precision, false)
+	if !ok {
+		return Date{}, false
+	}
+	return Date{truncateTo(periodEnd(d.date, d.l), l), l}, true
+}
+
+// Year returns the year component of d.
+func (d Date) Year() (Integer, bool) {
+	return component(d.Precision(), 4, d.date.Year())
+}
+
+// Month returns the month component of d, or false if d is less precise than
+// a month.
+func (d Date) Month() (Integer, bool) {
+	return component(d.Precision(), 6, int(d.date.Month()))
+}
+
+// Day returns the day component of d, or false if d is less precise than a
+// day.
+func (d Date) Day() (Integer, bool) {
+	return component(d.Precision(), 8, d.date.Day())
+}
@@ -310,6 +310,18 @@ func TestDateAdd_ReturnsSum(t *testing.T) {
 			input: system.MustParseQuantity("23", "months"),
 			want:  system.MustParseDate("1998"),
 		},
+		{
+			name:  "adds definite years as whole days",
+			date:  system.MustParseDate("2020-01-01"),
+			input: system.MustParseQuantity("1", "a"),
+			want:  system.MustParseDate("2020-12-31"),
+		},
+		{
+			name:  "adds definite weeks",
+			date:  system.MustParseDate("1974-10-30"),
+			input: system.MustParseQuantity("18", "wk"),
+			want:  system.MustParseDate("1975-03-05"),
+		},
 		{
 			name:    "returns error on unsupported time-valued quantity",
 			date:    system.MustParseDate("2019-03-31"),
@@ -376,6 +388,12 @@ func TestDateSub_ReturnsSum(t *testing.T) {
 			input: system.MustParseQuantity("29", "days"),
 			want:  system.MustParseDate("1997-04"),
 		},
+		{
+			name:  "subtracts year partial by definite years",
+			date:  system.MustParseDate("2014"),
+			input: system.MustParseQuantity("730", "d"),
+			want:  system.MustParseDate("2013"),
+		},
 		{
 			name:  "returns correct result when input month is longer than result month",
 			date:  system.MustParseDate("2019-03-31"),
@@ -436,3 +454,114 @@ func TestDate_ToDateTime(t *testing.T) {
 		})
 	}
 }
+
+func TestDateBoundaries(t *testing.T) {
+	testCases := []struct {
+		name      string
+		input     system.Date
+		precision int
+		wantLow   system.Date
+		wantHigh  system.Date
+	}{
+		{
+			name:      "year to month",
+			input:     system.MustParseDate("2014"),
+			precision: 6,
+			wantLow:   system.MustParseDate("2014-01"),
+			wantHigh:  system.MustParseDate("2014-12"),
+		},
+		{
+			name:      "month to day",
+			input:     system.MustParseDate("2023-04"),
+			precision: 8,
+			wantLow:   system.MustParseDate("2023-04-01"),
+			wantHigh:  system.MustParseDate("2023-04-30"),
+		},
+		{
+			name:      "leap february to day",
+			input:     system.MustParseDate("2024-02"),
+			precision: 8,
+			wantLow:   system.MustParseDate("2024-02-01"),
+			wantHigh:  system.MustParseDate("2024-02-29"),
+		},
+		{
+			name:      "day to year",
+			input:     system.MustParseDate("2014-05-06"),
+			precision: 4,
+			wantLow:   system.MustParseDate("2014"),
+			wantHigh:  system.MustParseDate("2014"),
+		},
+		{
+			name:      "same precision",
+			input:     system.MustParseDate("2014-05-06"),
+			precision: 8,
+			wantLow:   system.MustParseDate("2014-05-06"),
+			wantHigh:  system.MustParseDate("2014-05-06"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			low, ok := tc.input.LowBoundary(tc.precision)
+			if !ok {
+				t.Fatalf("Date(%v).LowBoundary(%v) returned no value", tc.input, tc.precision)
+			}
+			if diff := cmp.Diff(tc.wantLow, low); diff != "" {
+				t.Errorf("Date(%v).LowBoundary(%v) returned unexpected diff (-want, +got)\n%s", tc.input, tc.precision, diff)
+			}
+			high, ok := tc.input.HighBoundary(tc.precision)
+			if !ok {
+				t.Fatalf("Date(%v).HighBoundary(%v) returned no value", tc.input, tc.precision)
+			}
+			if diff := cmp.Diff(tc.wantHigh, high); diff != "" {
+				t.Errorf("Date(%v).HighBoundary(%v) returned unexpected diff (-want, +got)\n%s", tc.input, tc.precision, diff)
+			}
+		})
+	}
+}
+
+func TestDateBoundaries_UnsupportedPrecision(t *testing.T) {
+	input := system.MustParseDate("2014")
+
+	for _, precision := range []int{5, 10, 17} {
+		if _, ok := input.LowBoundary(precision); ok {
+			t.Errorf("Date(%v).LowBoundary(%v) returned a value, want none", input, precision)
+		}
+		if _, ok := input.HighBoundary(precision); ok {
+			t.Errorf("Date(%v).HighBoundary(%v) returned a value, want none", input, precision)
+		}
+	}
+}
+
+func TestDatePrecision(t *testing.T) {
+	testCases := []struct {
+		input string
+		want  int
+	}{
+		{"2014", 4},
+		{"2014-01", 6},
+		{"2014-01-05", 8},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			if got := system.MustParseDate(tc.input).Precision(); got != tc.want {
+				t.Errorf("Date(%v).Precision() = %v, want %v", tc.input, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDateComponents(t *testing.T) {
+	input := system.MustParseDate("2014-02")
+
+	if got, ok := input.Year(); !ok || got != 2014 {
+		t.Errorf("Date(%v).Year() = %v, %v, want 2014, true", input, got, ok)
+	}
+	if got, ok := input.Month(); !ok || got != 2 {
+		t.Errorf("Date(%v).Month() = %v, %v, want 2, true", input, got, ok)
+	}
+	if _, ok := input.Day(); ok {
+		t.Errorf("Date(%v).Day() returned a value, want none", input)
+	}
+}
@@ -22,6 +22,8 @@ type DateTime struct {
 // the string is a valid FHIRPath DateTime string, or an error otherwise.
 func ParseDateTime(value string) (DateTime, error) {
 	dateTimeLayouts := []string{
+		dtMicrosecondLayoutTZ,
+		dtMicrosecondLayout,
 		dtMillisecondLayoutTZ,
 		dtMillisecondLayout,
 		dtSecondLayoutTZ,
@@ -57,7 +59,8 @@ func MustParseDateTime(value string) DateTime {
 }
 
 // DateTimeFromProto takes a proto DateTime as input and returns a System DateTime.
-// Note that the highest precision supported by System.DateTime is Millisecond.
+// The precision and timezone string of the proto are retained, such that they
+// are restored by ToProtoDateTime.
 func DateTimeFromProto(proto *dtpb.DateTime) (DateTime, error) {
 	t, err := fhirconv.DateTimeToTime(proto)
 	if err != nil {
@@ -66,7 +69,7 @@ func DateTimeFromProto(proto *dtpb.DateTime) (DateTime, error) {
 	var l layout
 	switch proto.Precision {
 	case dtpb.DateTime_MICROSECOND:
-		fallthrough
+		l = dtMicrosecondLayoutTZ
 	case dtpb.DateTime_MILLISECOND:
 		l = dtMillisecondLayoutTZ
 	case dtpb.DateTime_SECOND:
@@ -81,12 +84,34 @@ func DateTimeFromProto(proto *dtpb.DateTime) (DateTime, error) {
 	return DateTime{t, l}, nil
 }
 
+// DateTimeFromInstantProto takes a proto Instant as input and returns a
+// System DateTime, retaining its precision and timezone string as for
+// DateTimeFromProto.
+func DateTimeFromInstantProto(proto *dtpb.Instant) (DateTime, error) {
+	t, err := fhirconv.InstantToTime(proto)
+	if err != nil {
+		return DateTime{}, err
+	}
+	var l layout
+	switch proto.Precision {
+	case dtpb.Instant_MICROSECOND:
+		l = dtMicrosecondLayoutTZ
+	case dtpb.Instant_MILLISECOND:
+		l = dtMillisecondLayoutTZ
+	default:
+		l = dtSecondLayoutTZ
+	}
+	return DateTime{t, l}, nil
+}
+
 // ToProtoDateTime returns a proto DateTime based on a system DateTime.
-// Note that the highest precision supported by System.DateTime is Millisecond.
 func (dt DateTime) ToProtoDateTime() *dtpb.DateTime {
 	dateTime := fhir.DateTime(dt.dateTime)
+	dateTime.Timezone = fhirconv.TimeToTimezone(dt.dateTime)
 	var p dtpb.DateTime_Precision
 	switch dt.l {
+	case dtMicrosecondLayoutTZ, dtMicrosecondLayout:
+		p = dtpb.DateTime_MICROSECOND
 	case dtMillisecondLayoutTZ, dtMillisecondLayout:
 		p = dtpb.DateTime_MILLISECOND
 	case dtSecondLayoutTZ, dtSecondLayout:
@@ -133,6 +158,14 @@ func (dt DateTime) TryEqual(input Any) (bool, bool) {
 	return false, false
 }
 
+// Equivalent returns true if input is a DateTime with the same value and
+// precision as dt. Unlike TryEqual, differing precisions result in false
+// rather than no value.
+func (dt DateTime) Equivalent(input Any) bool {
+	result, ok := dt.TryEqual(input)
+	return result && ok
+}
+
 // Less returns true if the value of dt is less than input.(DateTime).
 // Compares component by component, and returns an error if there is a
 // precision mismatch. If input is not a Date, returns an error.
@@ -164,7 +197,9 @@ func (dt DateTime) Less(input Any) (Boolean, error) {
 	return false, ErrMismatchedPrecision
 }
 
-// Add returns the result of dt + input. Returns an
+// Add returns the result of dt + input. Calendar durations follow calendar
+// rules, while definite durations are added as their exact length, such that
+// 1 'a' is 365.25 days. Returns an
 // error if input does not represent a valid time valued quantity.
 func (dt DateTime) Add(input Quantity) (DateTime, error) {
 	var result time.Time
@@ -189,7 +224,7 @@ func (dt DateTime) Add(input Quantity) (DateTime, error) {
 
 	// Reformat to truncate DateTime to initial precision, rounding down to
 	// highest precision value.
-	result, err := time.Parse(string(dt.l), result.Format(string(dt.l)))
+	result, err := time.ParseInLocation(string(dt.l), result.Format(string(dt.l)), result.Location())
 	if err != nil {
 		return DateTime{}, err
 	}
@@ -274,16 +309,117 @@ func (dt DateTime) getComponents() []int {
 func roundToDateTimePrecision(p dateTimePrecision, d time.Duration) time.Duration {
 	switch p {
 	case dtYear:
-		return d / (time.Hour * 24 * 365)
+		return d.Truncate(time.Hour * 24 * 365)
 	case dtMonth:
-		return d / (time.Hour * 24 * 30)
+		return d.Truncate(time.Hour * 24 * 30)
 	case dtDay:
-		return d / (time.Hour * 24)
+		return d.Truncate(time.Hour * 24)
 	case dtHour:
-		return d / time.Hour
+		return d.Truncate(time.Hour)
 	case dtMinute:
-		return d / time.Minute
+		return d.Truncate(time.Minute)
 	default:
 		return d
 	}
 }
+
+// Precision returns the precision of dt in digits, from 4 for year precision
+// to 20 for microsecond precision.
+func (dt DateTime) Precision() int {
+	return dateTimeDigits[dt.l]
+}
+
+// LowBoundary returns the earliest DateTime that dt could represent, to the
+// given precision in digits. For example, the low boundary of 2014-01-01T08 to
+// a precision of 17 is 2014-01-01T08:00:00.000. The timezone of dt is
+// retained. Returns false if precision is not a valid DateTime precision.
+func (dt DateTime) LowBoundary(precision int) (DateTime, bool) {
+	l, ok := layoutWithDigits(dateTimeDigits, precision, hasTimeZone(dt.l))
+	if !ok {
+		return DateTime{}, false
+	}
+	return DateTime{truncateTo(dt.dateTime, l), l}, true
+}
+
+// HighBoundary returns the latest DateTime that dt could represent, to the
+// given precision in digits. For example, the high boundary of 2014-01-01T08
+// to a precision of 17 is 2014-01-01T08:59:59.999. The timezone of dt is
+// retained. Returns false if precision is not a valid DateTime precision.
+func (dt DateTime) HighBoundary(precision int) (DateTime, bool) {
+	l, ok := layoutWithDigits(dateTimeDigits, precision, hasTimeZone(dt.l))
+	if !ok {
+		return DateTime{}, false
+	}
+	return DateTime{truncateTo(periodEnd(dt.dateTime, dt.l), l), l}, true
+}
+
+// Year returns the year component of dt.
+func (dt DateTime) Year() (Integer, bool) {
+	return component(dt.Precision(), 4, dt.dateTime.Year())
+}
+
+// Month returns the month component of dt, or false if dt is less precise
+// than a month.
+func (dt DateTime) Month() (Integer, bool) {
+	return component(dt.Precision(), 6, int(dt.dateTime.Month()))
+}
+
+// Day returns the day component of dt, or false if dt is less precise than a
+// day.
+func (dt DateTime) Day() (Integer, bool) {
+	return component(dt.Precision(), 8, dt.dateTime.Day())
+}
+
+// Hour returns the hour component of dt, or false if dt is less precise than
+// an hour.
+func (dt DateTime) Hour() (Integer, bool) {
+	return component(dt.Precision(), 10, dt.dateTime.Hour())
+}
+
+// Minute returns the minute component of dt, or false if dt is less precise
+// than a minute.
+func (dt DateTime) Minute() (Integer, bool) {
+	return component(dt.Precision(), 12, dt.dateTime.Minute())
+}
+
+// Second returns the second component of dt, or false if dt is less precise
+// than a second.
+func (dt DateTime) Second() (Integer, bool) {
+	return component(dt.Precision(), 14, dt.dateTime.Second())
+}
+
+// Millisecond returns the millisecond component of dt, or false if dt is less
+// precise than a millisecond.
+func (dt DateTime) Millisecond() (Integer, bool) {
+	return component(dt.Precision(), 17, dt.dateTime.Nanosecond()/int(time.Millisecond))
+}
+
+// TimezoneOffset returns the timezone offset of dt in hours, such that -05:30
+// is -5.5. Returns false if dt has no timezone.
+func (dt DateTime) TimezoneOffset() (Decimal, bool) {
+	if !hasTimeZone(dt.l) {
+		return Decimal{}, false
+	}
+	_, offset := dt.dateTime.Zone()
+	hours := decimal.NewFromInt(int64(offset)).Div(decimal.NewFromInt(int64(time.Hour / time.Second)))
+	return Decimal(hours), true
+}
+
+// Date returns the date component of dt, to the precision of dt if it is
+// less precise than a day.
+func (dt DateTime) Date() Date {
+	l, _ := layoutWithDigits(dateDigits, min(dt.Precision(), dateDigits[dayLayout]), false)
+	year, month, day := dt.dateTime.Date()
+	return Date{time.Date(year, month, day, 0, 0, 0, 0, time.UTC), l}
+}
+
+// Time returns the time component of dt, to the precision of dt. Returns false
+// if dt is less precise than an hour.
+func (dt DateTime) Time() (Time, bool) {
+	l, ok := layoutWithDigits(timeDigits, dt.Precision()-dateDigits[dayLayout], false)
+	if !ok {
+		return Time{}, false
+	}
+	hour, minute, second := dt.dateTime.Clock()
+	return Time{time.Date(0, time.January, 1, hour, minute, second, dt.dateTime.Nanosecond(), time.UTC), l}, true
+}
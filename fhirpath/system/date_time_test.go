@@ -120,7 +120,7 @@ func TestDateTime_Equal(t *testing.T) {
 }
 
 func TestDateTimeFromProto_Converts(t *testing.T) {
-	almostY2K, _ := system.ParseDateTime("1999-12-31T23:59:59.999Z")
+	almostY2K, _ := system.ParseDateTime("1999-12-31T23:59:59.999999Z")
 	dec2004, _ := system.ParseDateTime("2004-12T")
 	pandemic, _ := system.ParseDateTime("2020-03-15T08:30:05Z")
 
@@ -209,6 +209,72 @@ func TestDateTimeToProtoDateTime_Converts(t *testing.T) {
 	}
 }
 
+func TestDateTimeProto_RoundTrip(t *testing.T) {
+	testCases := []struct {
+		name    string
+		dtProto *dtpb.DateTime
+	}{
+		{
+			name:    "microsecond precision",
+			dtProto: &dtpb.DateTime{ValueUs: 946684799999999, Timezone: "Z", Precision: dtpb.DateTime_MICROSECOND},
+		},
+		{
+			name:    "millisecond precision with offset",
+			dtProto: &dtpb.DateTime{ValueUs: 946684799999000, Timezone: "+05:30", Precision: dtpb.DateTime_MILLISECOND},
+		},
+		{
+			name:    "second precision with location name",
+			dtProto: &dtpb.DateTime{ValueUs: 946684799000000, Timezone: "America/New_York", Precision: dtpb.DateTime_SECOND},
+		},
+		{
+			name:    "day precision",
+			dtProto: &dtpb.DateTime{ValueUs: 946598400000000, Timezone: "UTC", Precision: dtpb.DateTime_DAY},
+		},
+		{
+			name:    "month precision",
+			dtProto: &dtpb.DateTime{ValueUs: 944006400000000, Timezone: "-08:00", Precision: dtpb.DateTime_MONTH},
+		},
+		{
+			name:    "year precision",
+			dtProto: &dtpb.DateTime{ValueUs: 915148800000000, Timezone: "+00:00", Precision: dtpb.DateTime_YEAR},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dt, err := system.DateTimeFromProto(tc.dtProto)
+			if err != nil {
+				t.Fatalf("DateTimeFromProto(%v) returned unexpected error: %v", tc.dtProto, err)
+			}
+
+			got := dt.ToProtoDateTime()
+
+			if diff := cmp.Diff(tc.dtProto, got, protocmp.Transform()); diff != "" {
+				t.Errorf("DateTime proto round trip returned unexpected result: (-want, +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDateTimeProto_RoundTripThroughAdd(t *testing.T) {
+	dtProto := &dtpb.DateTime{ValueUs: 946684799999999, Timezone: "+05:30", Precision: dtpb.DateTime_MICROSECOND}
+	dt, err := system.DateTimeFromProto(dtProto)
+	if err != nil {
+		t.Fatalf("DateTimeFromProto(%v) returned unexpected error: %v", dtProto, err)
+	}
+
+	sum, err := dt.Add(system.MustParseQuantity("1", "day"))
+	if err != nil {
+		t.Fatalf("DateTime.Add returned unexpected error: %v", err)
+	}
+	got := sum.ToProtoDateTime()
+
+	want := &dtpb.DateTime{ValueUs: 946771199999999, Timezone: "+05:30", Precision: dtpb.DateTime_MICROSECOND}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("DateTime.Add returned unexpected result: (-want, +got)\n%s", diff)
+	}
+}
+
 func TestDateTimeLess_ReturnsBoolean(t *testing.T) {
 	testCases := []struct {
 		name        string
@@ -325,6 +391,12 @@ func TestDateTimeAdd_ReturnsSum(t *testing.T) {
 			input:       system.MustParseQuantity("1", "year"),
 			want:        system.MustParseDateTime("2021-02-28T08:12:12"),
 		},
+		{
+			name:        "adds definite years as their exact length",
+			dateTimeOne: system.MustParseDateTime("2020-01-01T00:00:00"),
+			input:       system.MustParseQuantity("1", "a"),
+			want:        system.MustParseDateTime("2020-12-31T06:00:00"),
+		},
 		{
 			name:        "adds decimal of seconds",
 			dateTimeOne: system.MustParseDateTime("2013-11-15T00:30:00.000Z"),
@@ -397,6 +469,18 @@ func TestDateTimeSub_ReturnsResults(t *testing.T) {
 			input:       system.MustParseQuantity("24", "hours"),
 			want:        system.MustParseDateTime("2012-11-14T08:12:12"),
 		},
+		{
+			name:        "subtracts minutes from minute precision",
+			dateTimeOne: system.MustParseDateTime("2012-11-15T10:00"),
+			input:       system.MustParseQuantity("30", "minutes"),
+			want:        system.MustParseDateTime("2012-11-15T09:30"),
+		},
+		{
+			name:        "subtracts definite minutes from hour precision",
+			dateTimeOne: system.MustParseDateTime("2012-11-15T10"),
+			input:       system.MustParseQuantity("150", "min"),
+			want:        system.MustParseDateTime("2012-11-15T08"),
+		},
 		{
 			name:        "subtracts years correctly when start date is a leap year",
 			dateTimeOne: system.MustParseDateTime("2020-02-29T08:12:12"),
@@ -472,3 +556,198 @@ func TestDateTimeSub_ReturnsResults(t *testing.T) {
 		})
 	}
 }
+
+func TestDateTimeBoundaries(t *testing.T) {
+	testCases := []struct {
+		name      string
+		input     system.DateTime
+		precision int
+		wantLow   system.DateTime
+		wantHigh  system.DateTime
+	}{
+		{
+			name:      "hour to millisecond",
+			input:     system.MustParseDateTime("2014-01-01T08"),
+			precision: 17,
+			wantLow:   system.MustParseDateTime("2014-01-01T08:00:00.000"),
+			wantHigh:  system.MustParseDateTime("2014-01-01T08:59:59.999"),
+		},
+		{
+			name:      "month to millisecond",
+			input:     system.MustParseDateTime("2023-02T"),
+			precision: 17,
+			wantLow:   system.MustParseDateTime("2023-02-01T00:00:00.000"),
+			wantHigh:  system.MustParseDateTime("2023-02-28T23:59:59.999"),
+		},
+		{
+			name:      "retains timezone",
+			input:     system.MustParseDateTime("2014-01-01T08:30+05:00"),
+			precision: 14,
+			wantLow:   system.MustParseDateTime("2014-01-01T08:30:00+05:00"),
+			wantHigh:  system.MustParseDateTime("2014-01-01T08:30:59+05:00"),
+		},
+		{
+			name:      "second to day",
+			input:     system.MustParseDateTime("2014-01-01T08:30:05Z"),
+			precision: 8,
+			wantLow:   system.MustParseDateTime("2014-01-01T"),
+			wantHigh:  system.MustParseDateTime("2014-01-01T"),
+		},
+		{
+			name:      "year to month",
+			input:     system.MustParseDateTime("2014T"),
+			precision: 6,
+			wantLow:   system.MustParseDateTime("2014-01T"),
+			wantHigh:  system.MustParseDateTime("2014-12T"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			low, ok := tc.input.LowBoundary(tc.precision)
+			if !ok {
+				t.Fatalf("DateTime(%v).LowBoundary(%v) returned no value", tc.input, tc.precision)
+			}
+			if diff := cmp.Diff(tc.wantLow, low); diff != "" {
+				t.Errorf("DateTime(%v).LowBoundary(%v) returned unexpected diff (-want, +got)\n%s", tc.input, tc.precision, diff)
+			}
+			high, ok := tc.input.HighBoundary(tc.precision)
+			if !ok {
+				t.Fatalf("DateTime(%v).HighBoundary(%v) returned no value", tc.input, tc.precision)
+			}
+			if diff := cmp.Diff(tc.wantHigh, high); diff != "" {
+				t.Errorf("DateTime(%v).HighBoundary(%v) returned unexpected diff (-want, +got)\n%s", tc.input, tc.precision, diff)
+			}
+		})
+	}
+}
+
+func TestDateTimeBoundaries_UnsupportedPrecision(t *testing.T) {
+	input := system.MustParseDateTime("2014-01-01T08")
+
+	for _, precision := range []int{3, 15, 18} {
+		if _, ok := input.LowBoundary(precision); ok {
+			t.Errorf("DateTime(%v).LowBoundary(%v) returned a value, want none", input, precision)
+		}
+		if _, ok := input.HighBoundary(precision); ok {
+			t.Errorf("DateTime(%v).HighBoundary(%v) returned a value, want none", input, precision)
+		}
+	}
+}
+
+func TestDateTimePrecision(t *testing.T) {
+	testCases := []struct {
+		input string
+		want  int
+	}{
+		{"2014T", 4},
+		{"2014-01-05T", 8},
+		{"2014-01-05T10", 10},
+		{"2014-01-05T10:30Z", 12},
+		{"2014-01-05T10:30:00", 14},
+		{"2014-01-05T10:30:00.000+01:00", 17},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			if got := system.MustParseDateTime(tc.input).Precision(); got != tc.want {
+				t.Errorf("DateTime(%v).Precision() = %v, want %v", tc.input, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDateTimeComponents(t *testing.T) {
+	type component func(system.DateTime) (system.Integer, bool)
+	components := []struct {
+		name string
+		get  component
+	}{
+		{"Year", system.DateTime.Year},
+		{"Month", system.DateTime.Month},
+		{"Day", system.DateTime.Day},
+		{"Hour", system.DateTime.Hour},
+		{"Minute", system.DateTime.Minute},
+		{"Second", system.DateTime.Second},
+		{"Millisecond", system.DateTime.Millisecond},
+	}
+	testCases := []struct {
+		input string
+		want  []int
+	}{
+		{"2014T", []int{2014}},
+		{"2014-01-05T", []int{2014, 1, 5}},
+		{"2014-01-05T10:30Z", []int{2014, 1, 5, 10, 30}},
+		{"2014-01-05T10:30:15.123+02:00", []int{2014, 1, 5, 10, 30, 15, 123}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			input := system.MustParseDateTime(tc.input)
+			for i, c := range components {
+				got, ok := c.get(input)
+				if wantOK := i < len(tc.want); ok != wantOK {
+					t.Fatalf("DateTime(%v).%v() returned ok %v, want %v", tc.input, c.name, ok, wantOK)
+				}
+				if ok && int(got) != tc.want[i] {
+					t.Errorf("DateTime(%v).%v() = %v, want %v", tc.input, c.name, got, tc.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDateTimeTimezoneOffset(t *testing.T) {
+	testCases := []struct {
+		input  string
+		want   system.Decimal
+		wantOK bool
+	}{
+		{"2014-01-05T10:30-05:30", system.MustParseDecimal("-5.5"), true},
+		{"2014-01-05T10:30Z", system.MustParseDecimal("0"), true},
+		{"2014-01-05T10:30", system.Decimal{}, false},
+		{"2014-01-05T", system.Decimal{}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			got, ok := system.MustParseDateTime(tc.input).TimezoneOffset()
+			if ok != tc.wantOK {
+				t.Fatalf("DateTime(%v).TimezoneOffset() returned ok %v, want %v", tc.input, ok, tc.wantOK)
+			}
+			if ok && !got.Equal(tc.want) {
+				t.Errorf("DateTime(%v).TimezoneOffset() = %v, want %v", tc.input, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDateTimeDateAndTime(t *testing.T) {
+	testCases := []struct {
+		input    string
+		wantDate system.Date
+		wantTime system.Time
+		wantOK   bool
+	}{
+		{"2014-01T", system.MustParseDate("2014-01"), system.Time{}, false},
+		{"2014-01-05T", system.MustParseDate("2014-01-05"), system.Time{}, false},
+		{"2014-01-05T10", system.MustParseDate("2014-01-05"), system.MustParseTime("10"), true},
+		{"2014-01-05T10:30:15.123+02:00", system.MustParseDate("2014-01-05"), system.MustParseTime("10:30:15.123"), true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			input := system.MustParseDateTime(tc.input)
+			if diff := cmp.Diff(tc.wantDate, input.Date()); diff != "" {
+				t.Errorf("DateTime(%v).Date() returned unexpected diff (-want, +got)\n%s", tc.input, diff)
+			}
+			got, ok := input.Time()
+			if ok != tc.wantOK {
+				t.Fatalf("DateTime(%v).Time() returned ok %v, want %v", tc.input, ok, tc.wantOK)
+			}
+			if diff := cmp.Diff(tc.wantTime, got); ok && diff != "" {
+				t.Errorf("DateTime(%v).Time() returned unexpected diff (-want, +got)\n%s", tc.input, diff)
+			}
+		})
+	}
+}
@@ -1,5 +1,10 @@
 package system
 
+import (
+	"strings"
+	"time"
+)
+
 // datePrecision enumerates date precision constants.
 type datePrecision int
 
@@ -45,6 +50,9 @@ const (
 	minuteLayout          = "15:04"
 	secondLayout          = "15:04:05"
 	millisecondLayout     = "15:04:05.000"
+	microsecondLayout     = "15:04:05.000000"
+	dtMicrosecondLayoutTZ = "2006-01-02T15:04:05.000000Z07:00"
+	dtMicrosecondLayout   = "2006-01-02T15:04:05.000000"
 	dtMillisecondLayoutTZ = "2006-01-02T15:04:05.000Z07:00"
 	dtMillisecondLayout   = "2006-01-02T15:04:05.000"
 	dtSecondLayoutTZ      = "2006-01-02T15:04:05Z07:00"
@@ -65,6 +73,7 @@ var dateMap = map[layout]datePrecision{
 }
 
 var timeMap = map[layout]timePrecision{
+	microsecondLayout: second,
 	millisecondLayout: second,
 	secondLayout:      second,
 	minuteLayout:      minute,
@@ -72,6 +81,8 @@ var timeMap = map[layout]timePrecision{
 }
 
 var dateTimeMap = map[layout]dateTimePrecision{
+	dtMicrosecondLayoutTZ: dtSecond,
+	dtMicrosecondLayout:   dtSecond,
 	dtMillisecondLayoutTZ: dtSecond,
 	dtMillisecondLayout:   dtSecond,
 	dtSecondLayoutTZ:      dtSecond,
@@ -84,3 +95,105 @@ var dateTimeMap = map[layout]dateTimePrecision{
 	dtMonthLayout:         dtMonth,
 	dtYearLayout:          dtYear,
 }
+
+// dateDigits maps date layouts to their precision in digits, as reported by
+// the precision() function.
+var dateDigits = map[layout]int{
+	yearLayout:  4,
+	monthLayout: 6,
+	dayLayout:   8,
+}
+
+// timeDigits maps time layouts to their precision in digits.
+var timeDigits = map[layout]int{
+	hourLayout:        2,
+	minuteLayout:      4,
+	secondLayout:      6,
+	millisecondLayout: 9,
+	microsecondLayout: 12,
+}
+
+// dateTimeDigits maps dateTime layouts to their precision in digits.
+var dateTimeDigits = map[layout]int{
+	dtYearLayout:          4,
+	dtMonthLayout:         6,
+	dtDayLayout:           8,
+	dtHourLayout:          10,
+	dtHourLayoutTZ:        10,
+	dtMinuteLayout:        12,
+	dtMinuteLayoutTZ:      12,
+	dtSecondLayout:        14,
+	dtSecondLayoutTZ:      14,
+	dtMillisecondLayout:   17,
+	dtMillisecondLayoutTZ: 17,
+	dtMicrosecondLayout:   20,
+	dtMicrosecondLayoutTZ: 20,
+}
+
+// layoutWithDigits returns the layout in the given map with the given
+// precision in digits. When two layouts share a precision, the one with a
+// timezone is returned if withTZ is set.
+func layoutWithDigits(digits map[layout]int, precision int, withTZ bool) (layout, bool) {
+	var found layout
+	ok := false
+	for l, d := range digits {
+		if d != precision {
+			continue
+		}
+		if !ok || hasTimeZone(l) == withTZ {
+			found, ok = l, true
+		}
+	}
+	return found, ok
+}
+
+// hasTimeZone returns true if the layout includes a timezone offset.
+func hasTimeZone(l layout) bool {
+	return strings.HasSuffix(string(l), "Z07:00")
+}
+
+// truncateTo truncates t to the precision of the layout l, retaining its
+// location.
+func truncateTo(t time.Time, l layout) time.Time {
+	truncated, err := time.ParseInLocation(string(l), t.Format(string(l)), t.Location())
+	if err != nil {
+		return t
+	}
+	return truncated
+}
+
+// periodEnd returns the last instant of the period starting at t that is
+// represented by a value of the layout l, such that 2014-01 ends at
+// 2014-01-31T23:59:59.999999999.
+func periodEnd(t time.Time, l layout) time.Time {
+	switch l {
+	case yearLayout, dtYearLayout:
+		t = t.AddDate(1, 0, 0)
+	case monthLayout, dtMonthLayout:
+		t = t.AddDate(0, 1, 0)
+	case dayLayout, dtDayLayout:
+		t = t.AddDate(0, 0, 1)
+	case hourLayout, dtHourLayout, dtHourLayoutTZ:
+		t = t.Add(time.Hour)
+	case minuteLayout, dtMinuteLayout, dtMinuteLayoutTZ:
+		t = t.Add(time.Minute)
+	case secondLayout, dtSecondLayout, dtSecondLayoutTZ:
+		t = t.Add(time.Second)
+	case millisecondLayout, dtMillisecondLayout, dtMillisecondLayoutTZ:
+		t = t.Add(time.Millisecond)
+	default:
+		t = t.Add(time.Microsecond)
+	}
+	return t.Add(-time.Nanosecond)
+}
+
+// component returns value as the Integer value of a date or time component,
+// provided that a value with the given precision in digits includes the
+// component, which first appears at the given digits. For example, the month
+// first appears at 6 digits in a Date.
+func component(precision, digits, value int) (Integer, bool) {
+	if precision < digits {
+		return 0, false
+	}
+	return Integer(value), true
+}
@@ -37,6 +37,12 @@ func (b Boolean) Equal(input Any) bool {
 	return b == val
 }
 
+// Equivalent returns true if the input value is a System Boolean, and
+// contains the same value.
+func (b Boolean) Equivalent(input Any) bool {
+	return b.Equal(input)
+}
+
 // Less returns error for Boolean comparison.
 func (b Boolean) Less(input Any) (Boolean, error) {
 	return false, fmt.Errorf("%w: %T, %T", ErrTypeMismatch, b, input)
@@ -82,6 +88,21 @@ func (s String) Equal(input Any) bool {
 	return s == val
 }
 
+// Equivalent returns true if the input value is a System String that
+// matches s when ignoring case and normalizing whitespace, such that any
+// run of whitespace characters is treated as a single space and leading or
+// trailing whitespace is ignored.
+func (s String) Equivalent(input Any) bool {
+	val, ok := input.(String)
+	if !ok {
+		return false
+	}
+	normalize := func(str String) string {
+		return strings.Join(strings.Fields(string(str)), " ")
+	}
+	return strings.EqualFold(normalize(s), normalize(val))
+}
+
 // Name returns the type name.
 func (s String) Name() string {
 	return stringType
@@ -126,6 +147,12 @@ func (i Integer) Equal(input Any) bool {
 	return i == val
 }
 
+// Equivalent returns true if the input value is a System Integer, and
+// contains the same int32 value.
+func (i Integer) Equivalent(input Any) bool {
+	return i.Equal(input)
+}
+
 // Name returns the type name.
 func (i Integer) Name() string {
 	return integerType
@@ -238,6 +265,33 @@ func (d Decimal) Equal(input Any) bool {
 	return decimal.Decimal(d).Equal(decimal.Decimal(val))
 }
 
+// Equivalent returns true if the input value is a System Decimal that is
+// equal to d when both values are rounded to the precision of the least
+// precise operand. Trailing zeroes after the decimal point are not counted
+// towards precision.
+func (d Decimal) Equivalent(input Any) bool {
+	val, ok := input.(Decimal)
+	if !ok {
+		return false
+	}
+	places := d.decimalPlaces()
+	if valPlaces := val.decimalPlaces(); valPlaces < places {
+		places = valPlaces
+	}
+	lhs, rhs := decimal.Decimal(d).Round(places), decimal.Decimal(val).Round(places)
+	return lhs.Equal(rhs)
+}
+
+// decimalPlaces returns the number of significant digits after the decimal
+// point, ignoring any trailing zeroes.
+func (d Decimal) decimalPlaces() int32 {
+	str := decimal.Decimal(d).String()
+	if i := strings.IndexByte(str, '.'); i >= 0 {
+		return int32(len(str) - i - 1)
+	}
+	return 0
+}
+
 // Name returns the type name.
 func (d Decimal) Name() string {
 	return decimalType
@@ -292,12 +346,62 @@ func (d Decimal) Mod(input Decimal) Decimal {
 	return Decimal(decimal.Decimal(d).Mod(decimal.Decimal(input)))
 }
 
-// ToProtoDecimal returns the proto Decimal representation of decimal.
+// ToProtoDecimal returns the proto Decimal representation of decimal. The
+// value is formatted to the precision of the decimal, so trailing zeroes are
+// retained (1.50 is "1.50", not "1.5") and exponents are never used.
 func (d Decimal) ToProtoDecimal() *dtpb.Decimal {
-	return fhir.Decimal(decimal.Decimal(d).InexactFloat64())
+	return &dtpb.Decimal{Value: decimal.Decimal(d).StringFixed(int32(d.Precision()))}
 }
 
 // Round rounds a Decimal at the provided precision.
 func (d Decimal) Round(precision int32) Decimal {
 	return Decimal(decimal.Decimal(d).Round(precision))
 }
+
+// maxDecimalPrecision is the greatest number of decimal places supported by
+// the boundary functions of Decimal.
+const maxDecimalPrecision = 28
+
+// Precision returns the number of decimal places of d, including trailing
+// zeroes, such that 1.58700 has a precision of 5.
+func (d Decimal) Precision() int {
+	if exp := decimal.Decimal(d).Exponent(); exp < 0 {
+		return int(-exp)
+	}
+	return 0
+}
+
+// LowBoundary returns the least possible value that d could represent given
+// its precision, to the given number of decimal places. For example, 1.587
+// represents values from 1.5865, so its low boundary to 8 places is
+// 1.58650000. Returns false if precision is outside of the supported range.
+func (d Decimal) LowBoundary(precision int) (Decimal, bool) {
+	if precision < 0 || precision > maxDecimalPrecision {
+		return Decimal{}, false
+	}
+	low := decimal.Decimal(d).Sub(d.halfUnit())
+	return withPrecision(low.RoundFloor(int32(precision)), precision), true
+}
+
+// HighBoundary returns the greatest possible value that d could represent
+// given its precision, to the given number of decimal places. For example,
+// 1.587 represents values up to 1.5875, so its high boundary to 8 places is
+// 1.58750000. Returns false if precision is outside of the supported range.
+func (d Decimal) HighBoundary(precision int) (Decimal, bool) {
+	if precision < 0 || precision > maxDecimalPrecision {
+		return Decimal{}, false
+	}
+	high := decimal.Decimal(d).Add(d.halfUnit())
+	return withPrecision(high.RoundCeil(int32(precision)), precision), true
+}
+
+// halfUnit returns half of the unit in the last decimal place of d.
+func (d Decimal) halfUnit() decimal.Decimal {
+	return decimal.New(5, -int32(d.Precision())-1)
+}
+
+// withPrecision returns d with exactly the given number of decimal places,
+// padding it with trailing zeroes if needed.
+func withPrecision(d decimal.Decimal, precision int) Decimal {
+	return Decimal(decimal.New(0, -int32(precision)).Add(d))
+}
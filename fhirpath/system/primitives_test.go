@@ -400,3 +400,100 @@ func TestIntegerMul_ReturnsResult(t *testing.T) {
 		})
 	}
 }
+
+func TestDecimalPrecision(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input system.Decimal
+		want  int
+	}{
+		{"integral decimal", system.MustParseDecimal("1"), 0},
+		{"decimal places", system.MustParseDecimal("1.587"), 3},
+		{"trailing zeroes", system.MustParseDecimal("1.58700"), 5},
+		{"negative", system.MustParseDecimal("-0.50"), 2},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.input.Precision(); got != tc.want {
+				t.Errorf("Decimal(%v).Precision() = %v, want %v", tc.input, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDecimalToProtoDecimal_RetainsPrecision(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"integral", "5", "5"},
+		{"decimal places", "1.587", "1.587"},
+		{"trailing zeroes", "1.50", "1.50"},
+		{"negative", "-0.050", "-0.050"},
+		{"small magnitude", "0.00000001", "0.00000001"},
+		{"large magnitude", "123456789012345678", "123456789012345678"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := system.MustParseDecimal(tc.input).ToProtoDecimal().GetValue(); got != tc.want {
+				t.Errorf("Decimal(%v).ToProtoDecimal() = %v, want %v", tc.input, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDecimalBoundaries(t *testing.T) {
+	testCases := []struct {
+		name      string
+		input     string
+		precision int
+		wantLow   string
+		wantHigh  string
+	}{
+		{"default precision", "1.587", 8, "1.58650000", "1.58750000"},
+		{"greater precision", "1.587", 6, "1.586500", "1.587500"},
+		{"lesser precision", "1.587", 2, "1.58", "1.59"},
+		{"negative default precision", "-1.587", 8, "-1.58750000", "-1.58650000"},
+		{"negative lesser precision", "-1.587", 2, "-1.59", "-1.58"},
+		{"integral", "1", 8, "0.50000000", "1.50000000"},
+		{"trailing zeroes", "1.50", 4, "1.4950", "1.5050"},
+		{"zero precision", "12.5", 0, "12", "13"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			input := system.MustParseDecimal(tc.input)
+
+			low, ok := input.LowBoundary(tc.precision)
+			if !ok {
+				t.Fatalf("Decimal(%v).LowBoundary(%v) returned no value", tc.input, tc.precision)
+			}
+			if got := low.ToProtoDecimal().GetValue(); got != tc.wantLow {
+				t.Errorf("Decimal(%v).LowBoundary(%v) = %v, want %v", tc.input, tc.precision, got, tc.wantLow)
+			}
+			high, ok := input.HighBoundary(tc.precision)
+			if !ok {
+				t.Fatalf("Decimal(%v).HighBoundary(%v) returned no value", tc.input, tc.precision)
+			}
+			if got := high.ToProtoDecimal().GetValue(); got != tc.wantHigh {
+				t.Errorf("Decimal(%v).HighBoundary(%v) = %v, want %v", tc.input, tc.precision, got, tc.wantHigh)
+			}
+		})
+	}
+}
+
+func TestDecimalBoundaries_UnsupportedPrecision(t *testing.T) {
+	input := system.MustParseDecimal("1.587")
+
+	for _, precision := range []int{-1, 29} {
+		if _, ok := input.LowBoundary(precision); ok {
+			t.Errorf("Decimal(%v).LowBoundary(%v) returned a value, want none", input, precision)
+		}
+		if _, ok := input.HighBoundary(precision); ok {
+			t.Errorf("Decimal(%v).HighBoundary(%v) returned a value, want none", input, precision)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/fhir-fli/fhirpath-go/fhir"
+	"github.com/fhir-fli/fhirpath-go/internal/units"
 	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
 	"github.com/shopspring/decimal"
 )
@@ -16,6 +17,48 @@ type Quantity struct {
 	unit  string
 }
 
+// ucumSystem is the FHIR code system of UCUM units.
+const ucumSystem = "http://unitsofmeasure.org"
+
+// definiteDurations maps the calendar duration keywords of a fixed length to
+// the equivalent UCUM units.
+var definiteDurations = map[string]string{
+	"week":         "wk",
+	"weeks":        "wk",
+	"day":          "d",
+	"days":         "d",
+	"hour":         "h",
+	"hours":        "h",
+	"minute":       "min",
+	"minutes":      "min",
+	"second":       "s",
+	"seconds":      "s",
+	"millisecond":  "ms",
+	"milliseconds": "ms",
+}
+
+// definiteKeywords are the calendar duration keywords that are equal to
+// their definite duration, such that 1 second = 1 's'. Above seconds,
+// calendar durations can't be compared with definite durations, such that
+// 1 day = 1 'd' is empty.
+var definiteKeywords = map[string]bool{
+	"second":       true,
+	"seconds":      true,
+	"millisecond":  true,
+	"milliseconds": true,
+}
+
+// calendarMonths maps the calendar duration keywords for years and months to
+// their length in months. These don't have a fixed length, so they can only be
+// compared with each other, and are only equivalent to the UCUM units 'a' and
+// 'mo'.
+var calendarMonths = map[string]int64{
+	"year":   12,
+	"years":  12,
+	"month":  1,
+	"months": 1,
+}
+
 // ParseQuantity takes as input a number string and a unit string and constructs
 // a Quantity object. Returns error if the input does not fit into a valid Quantity
 func ParseQuantity(number string, unit string) (Quantity, error) {
@@ -41,14 +84,16 @@ func MustParseQuantity(number string, unit string) Quantity {
 }
 
 // newQuantity constructs a system Quantity type, given a decimal
-// value and a UCUM unit identifier.
+// value and a UCUM unit identifier. Units that are neither UCUM units nor
+// calendar duration keywords are accepted, but can only be compared with
+// Quantities of the exact same unit.
 func newQuantity(value Decimal, unit string) (Quantity, error) {
-	// check isValidUnit(unit) *To be implemented
 	return Quantity{value, unit}, nil
 }
 
 // TryEqual returns a bool representing whether or not the
-// value represented by q is equal to the value of q2.
+// value represented by q is equal to the value of q2, converting q2
+// to the unit of q if the units differ.
 // The comparison is not symmetric and may not return a value, represented by
 // the second boolean being set to false.
 func (q Quantity) TryEqual(input Any) (bool, bool) {
@@ -56,42 +101,251 @@ func (q Quantity) TryEqual(input Any) (bool, bool) {
 	if !ok {
 		return false, true
 	}
-	if q.unit != val.unit {
+	value, ok := val.valueIn(q.unit)
+	if !ok {
 		return false, false
 	}
-	return q.value.Equal(val.value), true
+	return q.value.Equal(value), true
+}
+
+// Equivalent returns true if input is a Quantity with a unit commensurable
+// with that of q, and a value that is equivalent as defined by Decimal
+// equivalence once converted to the unit of q. Unlike equality, calendar
+// durations are equivalent to their definite durations, such that
+// 1 year ~ 1 'a'.
+func (q Quantity) Equivalent(input Any) bool {
+	val, ok := input.(Quantity)
+	if !ok {
+		return false
+	}
+	value, ok := val.definiteValueIn(q.unit)
+	return ok && q.value.Equivalent(value)
 }
 
 // Less returns true if q is less than input.(Quantity). If the units
-// are mismatched, returns an error. If input is not a Quantity, returns
+// can't be converted, returns an error. If input is not a Quantity, returns
 // an error.
 func (q Quantity) Less(input Any) (Boolean, error) {
 	val, ok := input.(Quantity)
 	if !ok {
 		return false, fmt.Errorf("%w: %T, %T", ErrTypeMismatch, q, input)
 	}
-	if q.unit != val.unit {
+	value, ok := val.valueIn(q.unit)
+	if !ok {
 		return false, ErrMismatchedUnit
 	}
-	return q.value.Less(val.value)
+	return q.value.Less(value)
 }
 
-// Add returns q + input. Returns an error if the units are mismatched.
+// Add returns q + input, in the unit of q. Returns an error if the units
+// can't be converted.
 func (q Quantity) Add(input Quantity) (Quantity, error) {
-	if q.unit != input.unit {
+	value, ok := input.valueIn(q.unit)
+	if !ok {
 		return Quantity{}, ErrMismatchedUnit
 	}
-	value := Decimal(decimal.Decimal(q.value).Add(decimal.Decimal(input.value)))
-	return Quantity{value, q.unit}, nil
+	sum := Decimal(decimal.Decimal(q.value).Add(decimal.Decimal(value)))
+	return Quantity{sum, q.unit}, nil
 }
 
-// Sub returns q - input. Returns an error if the units are mismatched.
+// Sub returns q - input, in the unit of q. Returns an error if the units
+// can't be converted.
 func (q Quantity) Sub(input Quantity) (Quantity, error) {
-	if q.unit != input.unit {
+	value, ok := input.valueIn(q.unit)
+	if !ok {
 		return Quantity{}, ErrMismatchedUnit
 	}
-	value := Decimal(decimal.Decimal(q.value).Sub(decimal.Decimal(input.value)))
-	return Quantity{value, q.unit}, nil
+	difference := Decimal(decimal.Decimal(q.value).Sub(decimal.Decimal(value)))
+	return Quantity{difference, q.unit}, nil
+}
+
+// Mul returns q * input, in the product of their units. Returns a Decimal if
+// the product is dimensionless. Returns an error if the units can't be
+// composed, such as when either isn't a UCUM unit.
+func (q Quantity) Mul(input Quantity) (Any, error) {
+	value := q.value.Mul(input.value)
+	switch {
+	case isUnitless(input.unit):
+		return Quantity{value, q.unit}, nil
+	case isUnitless(q.unit):
+		return Quantity{value, input.unit}, nil
+	}
+	unit, err := composeUnits(q.unit, input.unit, units.UCUM.Mul)
+	if err != nil {
+		return nil, err
+	}
+	return quantityOrDecimal(value, unit), nil
+}
+
+// Div returns q / input, in the quotient of their units. Returns a Decimal if
+// the quotient is dimensionless. Returns an error if input is zero, or the
+// units can't be composed, such as when either isn't a UCUM unit.
+func (q Quantity) Div(input Quantity) (Any, error) {
+	if decimal.Decimal(input.value).IsZero() {
+		return nil, ErrDivisionByZero
+	}
+	value := q.value.Div(input.value)
+	if isUnitless(input.unit) {
+		return Quantity{value, q.unit}, nil
+	}
+	unit, err := composeUnits(q.unit, input.unit, units.UCUM.Div)
+	if err != nil {
+		return nil, err
+	}
+	return quantityOrDecimal(value, unit), nil
+}
+
+// composeUnits returns the UCUM unit composed from the units lhs and rhs.
+func composeUnits(lhs, rhs string, compose func(units.UCUM, units.UCUM) (units.UCUM, error)) (units.UCUM, error) {
+	left, err := units.ParseUCUM(ucumCode(lhs))
+	if err != nil {
+		return units.UCUM{}, fmt.Errorf("%w: %w", ErrMismatchedUnit, err)
+	}
+	right, err := units.ParseUCUM(ucumCode(rhs))
+	if err != nil {
+		return units.UCUM{}, fmt.Errorf("%w: %w", ErrMismatchedUnit, err)
+	}
+	unit, err := compose(left, right)
+	if err != nil {
+		return units.UCUM{}, fmt.Errorf("%w: %w", ErrMismatchedUnit, err)
+	}
+	return unit, nil
+}
+
+// quantityOrDecimal returns value as a Quantity of unit, or as a Decimal if
+// unit is dimensionless, such that 100 'cm' / 1 'm' is 1.
+func quantityOrDecimal(value Decimal, unit units.UCUM) Any {
+	if unit.IsUnity() {
+		return value
+	}
+	if unit.IsDimensionless() {
+		if scaled, ok := convertUCUM(value, unit.Code(), "1"); ok {
+			return scaled
+		}
+	}
+	return Quantity{value, unit.Code()}
+}
+
+// isUnitless returns true if unit is empty or the unity.
+func isUnitless(unit string) bool {
+	return unit == "" || unit == "1"
+}
+
+// ConvertTo returns q converted to unit. Calendar durations of weeks and
+// shorter are converted to and from UCUM units as their definite durations,
+// such that 4 days is 96 'h', while years and months are only converted to each
+// other. Returns false if the units aren't commensurable, or either of them is
+// neither a UCUM unit nor a calendar duration keyword.
+func (q Quantity) ConvertTo(unit string) (Quantity, bool) {
+	_, fromCalendar := calendarMonths[q.unit]
+	_, toCalendar := calendarMonths[unit]
+	var value Decimal
+	var ok bool
+	if fromCalendar || toCalendar || q.unit == unit {
+		value, ok = q.valueIn(unit)
+	} else {
+		value, ok = convertUCUM(q.value, ucumCode(q.unit), ucumCode(unit))
+	}
+	if !ok {
+		return Quantity{}, false
+	}
+	return Quantity{value, unit}, true
+}
+
+// IsDurationKeyword returns true if unit is a calendar duration keyword, such
+// as 'days' or 'year'.
+func IsDurationKeyword(unit string) bool {
+	_, definite := definiteDurations[unit]
+	_, calendar := calendarMonths[unit]
+	return definite || calendar
+}
+
+// valueIn returns the value of q converted to unit. Returns false if the
+// units aren't commensurable, either of them is neither a UCUM unit nor a
+// calendar duration keyword, or a calendar duration above seconds would be
+// compared with a definite duration.
+func (q Quantity) valueIn(unit string) (Decimal, bool) {
+	if q.unit == unit {
+		return q.value, true
+	}
+	from, fromCalendar := calendarMonths[q.unit]
+	to, toCalendar := calendarMonths[unit]
+	if fromCalendar || toCalendar {
+		if !fromCalendar || !toCalendar {
+			return Decimal{}, false
+		}
+		months := decimal.Decimal(q.value).Mul(decimal.NewFromInt(from))
+		return Decimal(months.Div(decimal.NewFromInt(to))), true
+	}
+	_, fromKeyword := definiteDurations[q.unit]
+	_, toKeyword := definiteDurations[unit]
+	if fromKeyword != toKeyword && !definiteKeywords[q.unit] && !definiteKeywords[unit] {
+		return Decimal{}, false
+	}
+	return convertUCUM(q.value, ucumCode(q.unit), ucumCode(unit))
+}
+
+// definiteValueIn returns the value of q converted to unit, treating calendar
+// durations as their definite durations, such that 1 year is 1 'a'. Returns
+// false if the units aren't commensurable, or either of them is neither a UCUM
+// unit nor a calendar duration keyword.
+func (q Quantity) definiteValueIn(unit string) (Decimal, bool) {
+	if q.unit == unit {
+		return q.value, true
+	}
+	return convertUCUM(q.value, definiteCode(q.unit), definiteCode(unit))
+}
+
+// convertUCUM converts value from the UCUM unit from to the UCUM unit to.
+// Returns false if either isn't a UCUM unit, or they aren't commensurable.
+func convertUCUM(value Decimal, from, to string) (Decimal, bool) {
+	fromUnit, err := units.ParseUCUM(from)
+	if err != nil {
+		return Decimal{}, false
+	}
+	toUnit, err := units.ParseUCUM(to)
+	if err != nil {
+		return Decimal{}, false
+	}
+	converted, err := fromUnit.Convert(decimal.Decimal(value), toUnit)
+	if err != nil {
+		return Decimal{}, false
+	}
+	return Decimal(converted), true
+}
+
+// definiteCode returns the UCUM code for unit, as for ucumCode, with calendar
+// years and months mapped to the UCUM units 'a' and 'mo'.
+func definiteCode(unit string) string {
+	switch unit {
+	case "year", "years":
+		return "a"
+	case "month", "months":
+		return "mo"
+	}
+	return ucumCode(unit)
+}
+
+// ucumCode returns the UCUM code for unit, which is the equivalent UCUM unit
+// for calendar durations of a fixed length, and the unity for an empty unit.
+func ucumCode(unit string) string {
+	if code, ok := definiteDurations[unit]; ok {
+		return code
+	}
+	if unit == "" {
+		return "1"
+	}
+	return unit
+}
+
+// Value returns the numeric value of q, without its unit.
+func (q Quantity) Value() Decimal {
+	return q.value
+}
+
+// WithValue returns a Quantity with the given value and the unit of q.
+func (q Quantity) WithValue(value Decimal) Quantity {
+	return Quantity{value, q.unit}
 }
 
 // Name returns the type name.
@@ -115,6 +369,11 @@ func (q Quantity) ToProtoQuantity() *dtpb.Quantity {
 
 	if q.unit != "" {
 		res.Unit = fhir.String(q.unit)
+		code := ucumCode(q.unit)
+		if _, err := units.ParseUCUM(code); err == nil {
+			res.System = fhir.URI(ucumSystem)
+			res.Code = fhir.Code(code)
+		}
 	}
 
 	return res
@@ -132,7 +391,8 @@ func (q Quantity) Negate() Quantity {
 }
 
 // timeDuration returns the time.Duration represented by
-// a time-valued Quantity. Returns an error if the Quantity
+// a time-valued Quantity, which is either a calendar duration of hours or
+// below, or a definite duration. Returns an error if the Quantity
 // doesn't represent a valid time duration.
 func (q Quantity) timeDuration() (time.Duration, error) {
 	value := decimal.Decimal(q.value).IntPart()
@@ -146,16 +406,39 @@ func (q Quantity) timeDuration() (time.Duration, error) {
 	case "second", "seconds":
 		milliseconds := decimal.Decimal(q.value).Round(3).Shift(3).IntPart() // Keep decimal precision below seconds
 		duration = time.Millisecond * time.Duration(milliseconds)
-	case "millisecond":
+	case "millisecond", "milliseconds":
 		duration = time.Millisecond * time.Duration(value)
 	default:
-		return time.Duration(0), fmt.Errorf("%w: not a time-valued unit", ErrMismatchedUnit)
+		milliseconds, err := q.definiteIntPart("ms")
+		if err != nil {
+			return time.Duration(0), err
+		}
+		duration = time.Millisecond * time.Duration(milliseconds)
 	}
 	return duration, nil
 }
 
+// definiteIntPart returns the whole number of the UCUM time unit in q, if q
+// is a definite duration such as 1 'a', which is 365.25 days rather than a
+// calendar year. Returns an error for calendar durations, and for units that
+// aren't time-valued.
+func (q Quantity) definiteIntPart(unit string) (int, error) {
+	_, keyword := definiteDurations[q.unit]
+	_, calendar := calendarMonths[q.unit]
+	if keyword || calendar {
+		return 0, fmt.Errorf("%w: not a definite duration", ErrMismatchedUnit)
+	}
+	value, ok := convertUCUM(q.value, ucumCode(q.unit), unit)
+	if !ok {
+		return 0, fmt.Errorf("%w: not a time-valued unit", ErrMismatchedUnit)
+	}
+	return int(decimal.Decimal(value).IntPart()), nil
+}
+
 // Converts valid time based quantities to a number of years,
-// by rounding down.
+// by rounding down. Calendar durations are converted with the calendar
+// relationships, such as 365 days to a year, and definite durations with
+// their UCUM definitions.
 func (q Quantity) toYears() (int, error) {
 	value := int(decimal.Decimal(q.value).IntPart())
 
@@ -178,12 +461,14 @@ func (q Quantity) toYears() (int, error) {
 	case "millisecond", "milliseconds":
 		return value / (365 * 24 * 60 * 60) / 1000, nil
 	default:
-		return 0, fmt.Errorf("%w: not a time-valued unit", ErrMismatchedUnit)
+		return q.definiteIntPart("a")
 	}
 }
 
 // Converts a valid time based quantity to a number of months,
-// by rounding down.
+// by rounding down. Calendar durations are converted with the calendar
+// relationships, such as 30 days to a month, and definite durations with
+// their UCUM definitions.
 func (q Quantity) toMonths() (int, error) {
 	value := int(decimal.Decimal(q.value).IntPart())
 
@@ -206,6 +491,6 @@ func (q Quantity) toMonths() (int, error) {
 	case "millisecond", "milliseconds":
 		return value / (30 * 24 * 60 * 60) / 1000, nil
 	default:
-		return 0, fmt.Errorf("%w: not a time-valued unit", ErrMismatchedUnit)
+		return q.definiteIntPart("mo")
 	}
 }
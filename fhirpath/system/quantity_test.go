@@ -1,9 +1,14 @@
 package system_test
 
 import (
+	"errors"
 	"testing"
 
+	"github.com/fhir-fli/fhirpath-go/fhir"
 	"github.com/fhir-fli/fhirpath-go/fhirpath/system"
+	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
 )
 
 func TestParseQuantity_ErrorsOnInvalidString(t *testing.T) {
@@ -93,6 +98,52 @@ func TestQuantity_Equal(t *testing.T) {
 			shouldEqual: false,
 			wantOk:      true,
 		},
+		{
+			name:        "commensurable units",
+			quantityOne: oneKg,
+			quantityTwo: system.MustParseQuantity("1000", "g"),
+			shouldEqual: true,
+			wantOk:      true,
+		},
+		{
+			name:        "calendar durations",
+			quantityOne: system.MustParseQuantity("2", "hours"),
+			quantityTwo: system.MustParseQuantity("120", "minutes"),
+			shouldEqual: true,
+			wantOk:      true,
+		},
+		{
+			name:        "calendar seconds and UCUM unit",
+			quantityOne: system.MustParseQuantity("2", "seconds"),
+			quantityTwo: system.MustParseQuantity("2000", "ms"),
+			shouldEqual: true,
+			wantOk:      true,
+		},
+		{
+			name:        "calendar hours and UCUM unit",
+			quantityOne: system.MustParseQuantity("2", "hours"),
+			quantityTwo: system.MustParseQuantity("120", "min"),
+			wantOk:      false,
+		},
+		{
+			name:        "calendar years and months",
+			quantityOne: system.MustParseQuantity("1", "year"),
+			quantityTwo: system.MustParseQuantity("12", "months"),
+			shouldEqual: true,
+			wantOk:      true,
+		},
+		{
+			name:        "calendar year and UCUM year",
+			quantityOne: system.MustParseQuantity("1", "year"),
+			quantityTwo: system.MustParseQuantity("1", "a"),
+			wantOk:      false,
+		},
+		{
+			name:        "incommensurable units",
+			quantityOne: oneKg,
+			quantityTwo: system.MustParseQuantity("1", "m"),
+			wantOk:      false,
+		},
 		{
 			name:        "different type",
 			quantityOne: oneLb,
@@ -115,3 +166,321 @@ func TestQuantity_Equal(t *testing.T) {
 		})
 	}
 }
+
+func TestQuantity_WithValue(t *testing.T) {
+	quantity := system.MustParseQuantity("-1.5", "kg")
+
+	got := quantity.WithValue(quantity.Value().Mul(system.MustParseDecimal("2")))
+
+	if want := system.MustParseQuantity("-3.0", "kg"); !got.Equal(want) {
+		t.Errorf("WithValue returned %v, want %v", got, want)
+	}
+}
+
+func TestQuantity_Less(t *testing.T) {
+	testCases := []struct {
+		name    string
+		lhs     system.Quantity
+		rhs     system.Quantity
+		want    system.Boolean
+		wantErr error
+	}{
+		{
+			name: "same unit",
+			lhs:  system.MustParseQuantity("1", "kg"),
+			rhs:  system.MustParseQuantity("2", "kg"),
+			want: true,
+		},
+		{
+			name: "commensurable units",
+			lhs:  system.MustParseQuantity("1", "kg"),
+			rhs:  system.MustParseQuantity("900", "g"),
+			want: false,
+		},
+		{
+			name: "special units",
+			lhs:  system.MustParseQuantity("37", "Cel"),
+			rhs:  system.MustParseQuantity("100", "[degF]"),
+			want: true,
+		},
+		{
+			name:    "incommensurable units",
+			lhs:     system.MustParseQuantity("1", "kg"),
+			rhs:     system.MustParseQuantity("1", "L"),
+			wantErr: system.ErrMismatchedUnit,
+		},
+		{
+			name:    "non-UCUM units",
+			lhs:     system.MustParseQuantity("1", "lbs"),
+			rhs:     system.MustParseQuantity("1", "kg"),
+			wantErr: system.ErrMismatchedUnit,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.lhs.Less(tc.rhs)
+
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Less(%v, %v) returned error %v, want %v", tc.lhs, tc.rhs, err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("Less(%v, %v) = %v, want %v", tc.lhs, tc.rhs, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestQuantity_AddAndSub(t *testing.T) {
+	lhs := system.MustParseQuantity("1", "L")
+	rhs := system.MustParseQuantity("250", "mL")
+
+	sum, err := lhs.Add(rhs)
+	if err != nil {
+		t.Fatalf("Add(%v, %v) returned unexpected error: %v", lhs, rhs, err)
+	}
+	if want := system.MustParseQuantity("1.25", "L"); !sum.Equal(want) {
+		t.Errorf("Add(%v, %v) = %v, want %v", lhs, rhs, sum, want)
+	}
+
+	difference, err := lhs.Sub(rhs)
+	if err != nil {
+		t.Fatalf("Sub(%v, %v) returned unexpected error: %v", lhs, rhs, err)
+	}
+	if want := system.MustParseQuantity("0.75", "L"); !difference.Equal(want) {
+		t.Errorf("Sub(%v, %v) = %v, want %v", lhs, rhs, difference, want)
+	}
+
+	if _, err := lhs.Add(system.MustParseQuantity("1", "kg")); !errors.Is(err, system.ErrMismatchedUnit) {
+		t.Errorf("Add with incommensurable units returned error %v, want %v", err, system.ErrMismatchedUnit)
+	}
+}
+
+func TestQuantity_ConvertTo(t *testing.T) {
+	testCases := []struct {
+		name   string
+		q      system.Quantity
+		unit   string
+		want   system.Quantity
+		wantOK bool
+	}{
+		{
+			name:   "commensurable units",
+			q:      system.MustParseQuantity("1.5", "kg"),
+			unit:   "g",
+			want:   system.MustParseQuantity("1500", "g"),
+			wantOK: true,
+		},
+		{
+			name:   "calendar duration keywords",
+			q:      system.MustParseQuantity("2", "years"),
+			unit:   "months",
+			want:   system.MustParseQuantity("24", "months"),
+			wantOK: true,
+		},
+		{
+			name:   "calendar duration to UCUM unit",
+			q:      system.MustParseQuantity("4", "days"),
+			unit:   "h",
+			want:   system.MustParseQuantity("96", "h"),
+			wantOK: true,
+		},
+		{
+			name:   "UCUM unit to calendar duration",
+			q:      system.MustParseQuantity("1", "wk"),
+			unit:   "days",
+			want:   system.MustParseQuantity("7", "days"),
+			wantOK: true,
+		},
+		{
+			name: "calendar years to UCUM unit",
+			q:    system.MustParseQuantity("1", "year"),
+			unit: "a",
+		},
+		{
+			name: "incommensurable units",
+			q:    system.MustParseQuantity("1", "kg"),
+			unit: "m",
+		},
+		{
+			name: "calendar and definite durations",
+			q:    system.MustParseQuantity("1", "month"),
+			unit: "days",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := tc.q.ConvertTo(tc.unit)
+
+			if ok != tc.wantOK {
+				t.Fatalf("ConvertTo(%v, %v) returned ok = %v, want %v", tc.q, tc.unit, ok, tc.wantOK)
+			}
+			if !got.Equal(tc.want) {
+				t.Errorf("ConvertTo(%v, %v) = %v, want %v", tc.q, tc.unit, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestQuantity_ToProtoQuantity(t *testing.T) {
+	testCases := []struct {
+		name     string
+		quantity system.Quantity
+		want     *dtpb.Quantity
+	}{
+		{
+			name:     "UCUM unit",
+			quantity: system.MustParseQuantity("5", "mg/dL"),
+			want: &dtpb.Quantity{
+				Value:  fhir.Decimal(5),
+				Unit:   fhir.String("mg/dL"),
+				System: fhir.URI("http://unitsofmeasure.org"),
+				Code:   fhir.Code("mg/dL"),
+			},
+		},
+		{
+			name:     "calendar duration of fixed length",
+			quantity: system.MustParseQuantity("2", "weeks"),
+			want: &dtpb.Quantity{
+				Value:  fhir.Decimal(2),
+				Unit:   fhir.String("weeks"),
+				System: fhir.URI("http://unitsofmeasure.org"),
+				Code:   fhir.Code("wk"),
+			},
+		},
+		{
+			name:     "calendar year",
+			quantity: system.MustParseQuantity("2", "years"),
+			want: &dtpb.Quantity{
+				Value: fhir.Decimal(2),
+				Unit:  fhir.String("years"),
+			},
+		},
+		{
+			name:     "non-UCUM unit",
+			quantity: system.MustParseQuantity("2", "lbs"),
+			want: &dtpb.Quantity{
+				Value: fhir.Decimal(2),
+				Unit:  fhir.String("lbs"),
+			},
+		},
+		{
+			name:     "no unit",
+			quantity: system.MustParseQuantity("2", ""),
+			want: &dtpb.Quantity{
+				Value: fhir.Decimal(2),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.quantity.ToProtoQuantity()
+
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("ToProtoQuantity returned unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestQuantity_MulAndDiv(t *testing.T) {
+	testCases := []struct {
+		name string
+		lhs  system.Quantity
+		rhs  system.Quantity
+		div  bool
+		want system.Any
+	}{
+		{
+			name: "product of units",
+			lhs:  system.MustParseQuantity("1.5", "m"),
+			rhs:  system.MustParseQuantity("2", "m"),
+			want: system.MustParseQuantity("3", "m2"),
+		},
+		{
+			name: "quotient of units",
+			lhs:  system.MustParseQuantity("70", "kg"),
+			rhs:  system.MustParseQuantity("3.5", "m2"),
+			div:  true,
+			want: system.MustParseQuantity("20", "kg/m2"),
+		},
+		{
+			name: "calendar duration of fixed length",
+			lhs:  system.MustParseQuantity("60", "mg"),
+			rhs:  system.MustParseQuantity("2", "hours"),
+			div:  true,
+			want: system.MustParseQuantity("30", "mg/h"),
+		},
+		{
+			name: "cancelled units",
+			lhs:  system.MustParseQuantity("5", "mg"),
+			rhs:  system.MustParseQuantity("2", "mg"),
+			div:  true,
+			want: system.MustParseDecimal("2.5"),
+		},
+		{
+			name: "dimensionless quotient of prefixed units",
+			lhs:  system.MustParseQuantity("100", "cm"),
+			rhs:  system.MustParseQuantity("1", "m"),
+			div:  true,
+			want: system.MustParseDecimal("1"),
+		},
+		{
+			name: "dimensionless quotient of mass units",
+			lhs:  system.MustParseQuantity("5", "mg"),
+			rhs:  system.MustParseQuantity("1", "g"),
+			div:  true,
+			want: system.MustParseDecimal("0.005"),
+		},
+		{
+			name: "dimensionless product",
+			lhs:  system.MustParseQuantity("2", "[in_i]"),
+			rhs:  system.MustParseQuantity("5", "/cm"),
+			want: system.MustParseDecimal("25.4"),
+		},
+		{
+			name: "annotated units are kept",
+			lhs:  system.MustParseQuantity("3", "{cells}/uL"),
+			rhs:  system.MustParseQuantity("2", "uL"),
+			want: system.MustParseQuantity("6", "{cells}"),
+		},
+		{
+			name: "unitless operand",
+			lhs:  system.MustParseQuantity("5", "lbs"),
+			rhs:  system.MustParseQuantity("2", "1"),
+			want: system.MustParseQuantity("10", "lbs"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.lhs.Mul(tc.rhs)
+			if tc.div {
+				got, err = tc.lhs.Div(tc.rhs)
+			}
+
+			if err != nil {
+				t.Fatalf("returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("returned unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestQuantity_MulAndDiv_ReturnsError(t *testing.T) {
+	kg := system.MustParseQuantity("1", "kg")
+
+	if _, err := kg.Mul(system.MustParseQuantity("1", "year")); !errors.Is(err, system.ErrMismatchedUnit) {
+		t.Errorf("Mul with calendar year returned error %v, want %v", err, system.ErrMismatchedUnit)
+	}
+	if _, err := kg.Div(system.MustParseQuantity("1", "lbs")); !errors.Is(err, system.ErrMismatchedUnit) {
+		t.Errorf("Div with non-UCUM unit returned error %v, want %v", err, system.ErrMismatchedUnit)
+	}
+	if _, err := kg.Div(system.MustParseQuantity("0", "m")); !errors.Is(err, system.ErrDivisionByZero) {
+		t.Errorf("Div by zero returned error %v, want %v", err, system.ErrDivisionByZero)
+	}
+}
@@ -10,9 +10,9 @@ import (
 	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
 )
 
-// Time represents a time of day in the range 00:00:00.000
-// to 23:59:59.999 with a step size of 1ms. It uses the format
-// hh:mm:ss.fff format to parse times.
+// Time represents a time of day in the range 00:00:00.000000
+// to 23:59:59.999999 with a step size of 1us. It uses the format
+// hh:mm:ss.fff format to parse times, with up to microsecond precision.
 type Time struct {
 	time time.Time
 	l    layout
@@ -23,6 +23,7 @@ type Time struct {
 // an error.
 func ParseTime(value string) (Time, error) {
 	timeLayouts := []string{
+		microsecondLayout,
 		millisecondLayout,
 		secondLayout,
 		minuteLayout,
@@ -57,7 +58,7 @@ func TimeFromProto(proto *dtpb.Time) Time {
 	var l layout
 	switch proto.Precision {
 	case dtpb.Time_MICROSECOND:
-		fallthrough
+		l = microsecondLayout
 	case dtpb.Time_MILLISECOND:
 		l = millisecondLayout
 	case dtpb.Time_SECOND:
@@ -70,6 +71,8 @@ func TimeFromProto(proto *dtpb.Time) Time {
 func (t Time) ToProtoTime() *dtpb.Time {
 	tp := fhir.Time(t.time)
 	switch t.l {
+	case microsecondLayout:
+		tp.Precision = dtpb.Time_MICROSECOND
 	case millisecondLayout:
 		tp.Precision = dtpb.Time_MILLISECOND
 	default:
@@ -105,6 +108,14 @@ func (t Time) TryEqual(input Any) (bool, bool) {
 	return false, false
 }
 
+// Equivalent returns true if input is a Time with the same value and
+// precision as t. Unlike TryEqual, differing precisions result in false rather
+// than no value.
+func (t Time) Equivalent(input Any) bool {
+	result, ok := t.TryEqual(input)
+	return result && ok
+}
+
 // Name returns the type name.
 func (t Time) Name() string {
 	return timeType
@@ -147,7 +158,8 @@ func (t Time) Less(input Any) (Boolean, error) {
 	return false, ErrMismatchedPrecision
 }
 
-// Add returns the result of t with the time-valued quantity added to it.
+// Add returns the result of t with the time-valued quantity added to it, which
+// is either a calendar duration of hours or below, or a definite duration.
 // Returns an error if the Quantity does not represent a valid duration.
 func (t Time) Add(input Quantity) (Time, error) {
 	duration, err := input.timeDuration()
@@ -175,9 +187,9 @@ func (t Time) Sub(input Quantity) (Time, error) {
 func roundToTimePrecision(p timePrecision, d time.Duration) time.Duration {
 	switch p {
 	case hour:
-		return d / time.Hour
+		return d.Truncate(time.Hour)
 	case minute:
-		return d / time.Minute
+		return d.Truncate(time.Minute)
 	default:
 		return d
 	}
@@ -190,3 +202,56 @@ func (t Time) getComponents() []int {
 		t.time.Second()*1000000000 + t.time.Nanosecond(),
 	}
 }
+
+// Precision returns the precision of t in digits, being 2, 4, 6, 9 or 12 for
+// hour, minute, second, millisecond and microsecond precision respectively.
+func (t Time) Precision() int {
+	return timeDigits[t.l]
+}
+
+// LowBoundary returns the earliest Time that t could represent, to the given
+// precision in digits. For example, the low boundary of 10:30 to a precision
+// of 9 is 10:30:00.000. Returns false if precision is not a valid Time
+// precision.
+func (t Time) LowBoundary(precision int) (Time, bool) {
+	l, ok := layoutWithDigits(timeDigits, precision, false)
+	if !ok {
+		return Time{}, false
+	}
+	return Time{truncateTo(t.time, l), l}, true
+}
+
+// HighBoundary returns the latest Time that t could represent, to the given
+// precision in digits. For example, the high boundary of 10:30 to a precision
+// of 9 is 10:30:59.999. Returns false if precision is not a valid Time
+// precision.
+func (t Time) HighBoundary(precision int) (Time, bool) {
+	l, ok := layoutWithDigits(timeDigits, precision, false)
+	if !ok {
+		return Time{}, false
+	}
+	return Time{truncateTo(periodEnd(t.time, t.l), l), l}, true
+}
+
+// Hour returns the hour component of t.
+func (t Time) Hour() (Integer, bool) {
+	return component(t.Precision(), 2, t.time.Hour())
+}
+
+// Minute returns the minute component of t, or false if t is less precise
+// than a minute.
+func (t Time) Minute() (Integer, bool) {
+	return component(t.Precision(), 4, t.time.Minute())
+}
+
+// Second returns the second component of t, or false if t is less precise
+// than a second.
+func (t Time) Second() (Integer, bool) {
+	return component(t.Precision(), 6, t.time.Second())
+}
+
+// Millisecond returns the millisecond component of t, or false if t is less
+// precise than a millisecond.
+func (t Time) Millisecond() (Integer, bool) {
+	return component(t.Precision(), 9, t.time.Nanosecond()/int(time.Millisecond))
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/fhir-fli/fhirpath-go/fhirpath/system"
 	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
 	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
 )
 
 func TestParseTime_ReturnsTime(t *testing.T) {
@@ -117,7 +118,7 @@ func TestTimeFromProto_Converts(t *testing.T) {
 		{
 			name:      "converts microsecond precision",
 			timeProto: fhir.MustParseTime("08:30:00.212123"),
-			wantTime:  system.MustParseTime("08:30:00.212"),
+			wantTime:  system.MustParseTime("08:30:00.212123"),
 		},
 		{
 			name:      "converts second precision",
@@ -138,6 +139,36 @@ func TestTimeFromProto_Converts(t *testing.T) {
 	}
 }
 
+func TestTimeProto_RoundTrip(t *testing.T) {
+	testCases := []struct {
+		name      string
+		timeProto *dtpb.Time
+	}{
+		{
+			name:      "microsecond precision",
+			timeProto: fhir.MustParseTime("08:30:00.212123"),
+		},
+		{
+			name:      "millisecond precision",
+			timeProto: fhir.MustParseTime("08:30:00.212"),
+		},
+		{
+			name:      "second precision",
+			timeProto: fhir.MustParseTime("16:45:00"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := system.TimeFromProto(tc.timeProto).ToProtoTime()
+
+			if diff := cmp.Diff(tc.timeProto, got, protocmp.Transform()); diff != "" {
+				t.Errorf("Time proto round trip returned unexpected result: (-want, +got)\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestTimeLess_ReturnsBoolean(t *testing.T) {
 	testCases := []struct {
 		name    string
@@ -273,6 +304,54 @@ func TestTimeAdd_ReturnsSum(t *testing.T) {
 			input: system.MustParseQuantity("59", "minutes"),
 			want:  system.MustParseTime("08"),
 		},
+		{
+			name:  "adds definite durations",
+			time:  system.MustParseTime("08:00:00.000"),
+			input: system.MustParseQuantity("90", "min"),
+			want:  system.MustParseTime("09:30:00.000"),
+		},
+		{
+			name:  "adds plural milliseconds",
+			time:  system.MustParseTime("08:00:00.000"),
+			input: system.MustParseQuantity("250", "milliseconds"),
+			want:  system.MustParseTime("08:00:00.250"),
+		},
+		{
+			name:  "adds calendar minutes to minute precision",
+			time:  system.MustParseTime("10:00"),
+			input: system.MustParseQuantity("90", "minutes"),
+			want:  system.MustParseTime("11:30"),
+		},
+		{
+			name:  "adds definite minutes to minute precision",
+			time:  system.MustParseTime("10:00"),
+			input: system.MustParseQuantity("90", "min"),
+			want:  system.MustParseTime("11:30"),
+		},
+		{
+			name:  "adds calendar hours to hour precision",
+			time:  system.MustParseTime("10"),
+			input: system.MustParseQuantity("1", "hour"),
+			want:  system.MustParseTime("11"),
+		},
+		{
+			name:  "adds definite hours to hour precision",
+			time:  system.MustParseTime("10"),
+			input: system.MustParseQuantity("2", "h"),
+			want:  system.MustParseTime("12"),
+		},
+		{
+			name:  "adds calendar hours to minute precision",
+			time:  system.MustParseTime("10:00"),
+			input: system.MustParseQuantity("1", "hour"),
+			want:  system.MustParseTime("11:00"),
+		},
+		{
+			name:  "adds definite minutes to hour precision",
+			time:  system.MustParseTime("10"),
+			input: system.MustParseQuantity("150", "min"),
+			want:  system.MustParseTime("12"),
+		},
 		{
 			name:    "returns error on non time-valued quantity",
 			time:    system.MustParseTime("08:30:00"),
@@ -339,6 +418,36 @@ func TestTimeSub_ReturnsResult(t *testing.T) {
 			input: system.MustParseQuantity("119", "seconds"),
 			want:  system.MustParseTime("07:59"),
 		},
+		{
+			name:  "subtracts calendar minutes from minute precision",
+			time:  system.MustParseTime("10:00"),
+			input: system.MustParseQuantity("30", "minutes"),
+			want:  system.MustParseTime("09:30"),
+		},
+		{
+			name:  "subtracts definite minutes from minute precision",
+			time:  system.MustParseTime("10:00"),
+			input: system.MustParseQuantity("30", "min"),
+			want:  system.MustParseTime("09:30"),
+		},
+		{
+			name:  "subtracts calendar hours from hour precision",
+			time:  system.MustParseTime("10"),
+			input: system.MustParseQuantity("3", "hours"),
+			want:  system.MustParseTime("07"),
+		},
+		{
+			name:  "subtracts definite hours from hour precision",
+			time:  system.MustParseTime("10"),
+			input: system.MustParseQuantity("1", "h"),
+			want:  system.MustParseTime("09"),
+		},
+		{
+			name:  "subtracts definite minutes from hour precision",
+			time:  system.MustParseTime("10"),
+			input: system.MustParseQuantity("150", "min"),
+			want:  system.MustParseTime("08"),
+		},
 		{
 			name:    "returns error on non time-valued quantity",
 			time:    system.MustParseTime("08:30:00"),
@@ -381,3 +490,104 @@ func changeLocale(t *testing.T) {
 		}
 	})
 }
+
+func TestTimeBoundaries(t *testing.T) {
+	testCases := []struct {
+		name      string
+		input     system.Time
+		precision int
+		wantLow   system.Time
+		wantHigh  system.Time
+	}{
+		{
+			name:      "minute to millisecond",
+			input:     system.MustParseTime("10:30"),
+			precision: 9,
+			wantLow:   system.MustParseTime("10:30:00.000"),
+			wantHigh:  system.MustParseTime("10:30:59.999"),
+		},
+		{
+			name:      "end of day",
+			input:     system.MustParseTime("23"),
+			precision: 6,
+			wantLow:   system.MustParseTime("23:00:00"),
+			wantHigh:  system.MustParseTime("23:59:59"),
+		},
+		{
+			name:      "second to hour",
+			input:     system.MustParseTime("10:30:15"),
+			precision: 2,
+			wantLow:   system.MustParseTime("10"),
+			wantHigh:  system.MustParseTime("10"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			low, ok := tc.input.LowBoundary(tc.precision)
+			if !ok {
+				t.Fatalf("Time(%v).LowBoundary(%v) returned no value", tc.input, tc.precision)
+			}
+			if diff := cmp.Diff(tc.wantLow, low); diff != "" {
+				t.Errorf("Time(%v).LowBoundary(%v) returned unexpected diff (-want, +got)\n%s", tc.input, tc.precision, diff)
+			}
+			high, ok := tc.input.HighBoundary(tc.precision)
+			if !ok {
+				t.Fatalf("Time(%v).HighBoundary(%v) returned no value", tc.input, tc.precision)
+			}
+			if diff := cmp.Diff(tc.wantHigh, high); diff != "" {
+				t.Errorf("Time(%v).HighBoundary(%v) returned unexpected diff (-want, +got)\n%s", tc.input, tc.precision, diff)
+			}
+		})
+	}
+}
+
+func TestTimeBoundaries_UnsupportedPrecision(t *testing.T) {
+	input := system.MustParseTime("10:30")
+
+	for _, precision := range []int{3, 8, 15} {
+		if _, ok := input.LowBoundary(precision); ok {
+			t.Errorf("Time(%v).LowBoundary(%v) returned a value, want none", input, precision)
+		}
+		if _, ok := input.HighBoundary(precision); ok {
+			t.Errorf("Time(%v).HighBoundary(%v) returned a value, want none", input, precision)
+		}
+	}
+}
+
+func TestTimePrecision(t *testing.T) {
+	testCases := []struct {
+		input string
+		want  int
+	}{
+		{"10", 2},
+		{"10:30", 4},
+		{"10:30:00", 6},
+		{"10:30:00.000", 9},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			if got := system.MustParseTime(tc.input).Precision(); got != tc.want {
+				t.Errorf("Time(%v).Precision() = %v, want %v", tc.input, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestTimeComponents(t *testing.T) {
+	input := system.MustParseTime("10:30:15")
+
+	if got, ok := input.Hour(); !ok || got != 10 {
+		t.Errorf("Time(%v).Hour() = %v, %v, want 10, true", input, got, ok)
+	}
+	if got, ok := input.Minute(); !ok || got != 30 {
+		t.Errorf("Time(%v).Minute() = %v, %v, want 30, true", input, got, ok)
+	}
+	if got, ok := input.Second(); !ok || got != 15 {
+		t.Errorf("Time(%v).Second() = %v, %v, want 15, true", input, got, ok)
+	}
+	if _, ok := input.Millisecond(); ok {
+		t.Errorf("Time(%v).Millisecond() returned a value, want none", input)
+	}
+}
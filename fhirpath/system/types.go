@@ -6,13 +6,23 @@ import (
 	"fmt"
 
 	"github.com/fhir-fli/fhirpath-go/fhir"
-	"github.com/fhir-fli/fhirpath-go/internal/fhirconv"
 	"github.com/fhir-fli/fhirpath-go/internal/protofields"
 	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
 	"github.com/shopspring/decimal"
+	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
-var ErrCantBeCast = errors.New("value can't be cast to system type")
+var (
+	ErrCantBeCast = errors.New("value can't be cast to system type")
+	// ErrNoValue is raised when converting a FHIR primitive that carries only
+	// extensions, and no value. Operations on such primitives treat them as
+	// empty.
+	ErrNoValue = errors.New("primitive has no value")
+)
+
+// primitiveHasNoValueURL is the URL of the extension that the google/fhir
+// JSON unmarshaller adds to primitives that carry extensions but no value.
+const primitiveHasNoValueURL = "https://g.co/fhir/StructureDefinition/primitiveHasNoValue"
 
 // Any is the root abstraction for all FHIRPath system types.
 type Any interface {
@@ -59,10 +69,48 @@ func IsPrimitive(input any) bool {
 	}
 }
 
+// HasValue returns true if the input is a System type, or a FHIR primitive
+// that holds a value. Returns false for complex types, and for FHIR primitives
+// that carry only extensions, such as a birthDate with just a
+// data-absent-reason.
+//
+// A primitive holds no value if it is marked with the google/fhir
+// primitiveHasNoValue extension, or if it has extensions and its value is
+// unset; that is, a date or time without a precision, or an empty string.
+func HasValue(input any) bool {
+	if !IsPrimitive(input) {
+		return false
+	}
+	element, ok := input.(fhir.Extendable)
+	if !ok || len(element.GetExtension()) == 0 {
+		return true
+	}
+	for _, ext := range element.GetExtension() {
+		if ext.GetUrl().GetValue() == primitiveHasNoValueURL && ext.GetValue().GetBoolean().GetValue() {
+			return false
+		}
+	}
+	message := element.ProtoReflect()
+	fields := message.Descriptor().Fields()
+	if precision := fields.ByName("precision"); precision != nil {
+		return message.Has(precision)
+	}
+	if value := fields.ByName("value"); value != nil {
+		if kind := value.Kind(); kind == protoreflect.StringKind || kind == protoreflect.BytesKind {
+			return message.Has(value)
+		}
+	}
+	return true
+}
+
 // From converts primitive FHIR types to System types.
 // Returns the input if already a System type, and an error
-// if the input is not convertible.
+// if the input is not convertible. Returns an ErrNoValue error if
+// the input is a FHIR primitive that holds no value.
 func From(input any) (Any, error) {
+	if !HasValue(input) && IsPrimitive(input) {
+		return nil, fmt.Errorf("%w: %T", ErrNoValue, input)
+	}
 	switch v := input.(type) {
 	case *dtpb.Boolean:
 		return Boolean(v.Value), nil
@@ -113,7 +161,7 @@ func From(input any) (Any, error) {
 		}
 		return value, nil
 	case *dtpb.Instant:
-		value, err := ParseDateTime(fhirconv.InstantToString(v))
+		value, err := DateTimeFromInstantProto(v)
 		if err != nil {
 			return nil, err
 		}
@@ -1,11 +1,13 @@
 package system_test
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/fhir-fli/fhirpath-go/fhir"
 	"github.com/fhir-fli/fhirpath-go/fhirpath/system"
 	"github.com/fhir-fli/fhirpath-go/internal/element/canonical"
+	"github.com/fhir-fli/fhirpath-go/internal/element/extension"
 	cpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
 	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
 	mrpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/medication_request_go_proto"
@@ -13,6 +15,7 @@ import (
 	qpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/questionnaire_go_proto"
 	"github.com/google/go-cmp/cmp"
 	"github.com/shopspring/decimal"
+	"google.golang.org/protobuf/testing/protocmp"
 )
 
 var date, _ = system.ParseDate("2012-12-31")
@@ -230,6 +233,62 @@ func TestFrom_CastsCorrectly(t *testing.T) {
 	}
 }
 
+func TestFrom_Instant_RetainsPrecisionAndTimezone(t *testing.T) {
+	instant := &dtpb.Instant{ValueUs: 946684799999999, Timezone: "Z", Precision: dtpb.Instant_MICROSECOND}
+
+	got, err := system.From(instant)
+	if err != nil {
+		t.Fatalf("From(%v) returned unexpected error: %v", instant, err)
+	}
+	dt, ok := got.(system.DateTime)
+	if !ok {
+		t.Fatalf("From(%v) returned %T, want system.DateTime", instant, got)
+	}
+
+	want := &dtpb.DateTime{ValueUs: 946684799999999, Timezone: "Z", Precision: dtpb.DateTime_MICROSECOND}
+	if diff := cmp.Diff(want, dt.ToProtoDateTime(), protocmp.Transform()); diff != "" {
+		t.Errorf("From(%v) returned unexpected result: (-want, +got)\n%s", instant, diff)
+	}
+}
+
+func TestHasValue(t *testing.T) {
+	absent := extension.New("http://hl7.org/fhir/StructureDefinition/data-absent-reason", fhir.Code("unknown"))
+	noValue := extension.New("https://g.co/fhir/StructureDefinition/primitiveHasNoValue", fhir.Boolean(true))
+
+	testCases := []struct {
+		name  string
+		input any
+		want  bool
+	}{
+		{"system type", system.String("abc"), true},
+		{"primitive", fhir.String("abc"), true},
+		{"primitive with extension and value", &dtpb.String{Value: "abc", Extension: []*dtpb.Extension{absent}}, true},
+		{"primitive marked without value", &dtpb.Boolean{Extension: []*dtpb.Extension{absent, noValue}}, false},
+		{"string with only extension", &dtpb.String{Extension: []*dtpb.Extension{absent}}, false},
+		{"date with only extension", &dtpb.Date{Extension: []*dtpb.Extension{absent}}, false},
+		{"date with extension and value", &dtpb.Date{Precision: dtpb.Date_DAY, Extension: []*dtpb.Extension{absent}}, true},
+		{"boolean with extension is not marked", &dtpb.Boolean{Extension: []*dtpb.Extension{absent}}, true},
+		{"complex type", &dtpb.HumanName{}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := system.HasValue(tc.input); got != tc.want {
+				t.Errorf("system.HasValue(%v) = %v, want %v", tc.input, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFrom_PrimitiveWithoutValue_ReturnsError(t *testing.T) {
+	absent := extension.New("http://hl7.org/fhir/StructureDefinition/data-absent-reason", fhir.Code("unknown"))
+	input := &dtpb.Date{Extension: []*dtpb.Extension{absent}}
+
+	if _, err := system.From(input); !errors.Is(err, system.ErrNoValue) {
+		t.Errorf("system.From(%v) got error %v, want %v", input, err, system.ErrNoValue)
+	}
+}
+
 func TestNormalize(t *testing.T) {
 	wantQuantity, _ := system.ParseQuantity("4", "m")
 	wantDateTime, _ := system.ParseDateTime("2012-12-31T")
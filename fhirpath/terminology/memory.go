@@ -0,0 +1,255 @@
+package terminology
+
+import (
+	"fmt"
+
+	"github.com/fhir-fli/fhirpath-go/fhir"
+	"github.com/fhir-fli/fhirpath-go/internal/element/coding"
+	cpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	cspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/code_system_go_proto"
+	vspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/value_set_go_proto"
+)
+
+// Memory is a Provider that answers from the ValueSet and CodeSystem resources
+// loaded into it, without consulting a terminology server.
+//
+// Value set membership is decided by the expansion of a value set when it has
+// one, and by its compose element otherwise. Composed value sets may include
+// enumerated concepts, whole code systems, other value sets, and filters on
+// the concept hierarchy using the "=", "is-a", "descendent-of", "is-not-a", and
+// "generalizes" operators. Whole code systems and filters require the
+// CodeSystem to be loaded.
+//
+// Subsumption is decided by the concept hierarchy of a loaded CodeSystem, given
+// either by nesting concepts or by the "parent" property.
+type Memory struct {
+	valueSets   map[string]*vspb.ValueSet
+	codeSystems map[string]*codeSystem
+}
+
+// codeSystem holds the concept hierarchy of a CodeSystem.
+type codeSystem struct {
+	// parents maps each code in the code system to the codes of its parents.
+	parents map[string][]string
+}
+
+// NewMemory returns a Memory provider loaded with the given ValueSet and
+// CodeSystem resources. Returns an error if any other resource is given.
+func NewMemory(resources ...fhir.Resource) (*Memory, error) {
+	m := &Memory{
+		valueSets:   map[string]*vspb.ValueSet{},
+		codeSystems: map[string]*codeSystem{},
+	}
+	if err := m.Add(resources...); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Add loads the given ValueSet and CodeSystem resources into the provider,
+// replacing any previously loaded resource with the same url and version.
+// Returns an error if any other resource is given.
+func (m *Memory) Add(resources ...fhir.Resource) error {
+	for _, res := range resources {
+		switch res := res.(type) {
+		case *vspb.ValueSet:
+			url := res.GetUrl().GetValue()
+			m.valueSets[url] = res
+			if version := res.GetVersion().GetValue(); version != "" {
+				m.valueSets[url+"|"+version] = res
+			}
+		case *cspb.CodeSystem:
+			cs := &codeSystem{parents: map[string][]string{}}
+			cs.addConcepts(res.GetConcept(), "")
+			m.codeSystems[res.GetUrl().GetValue()] = cs
+		default:
+			return fmt.Errorf("unsupported terminology resource: %T", res)
+		}
+	}
+	return nil
+}
+
+// MemberOf reports whether the coding is a member of the given value set.
+func (m *Memory) MemberOf(valueSet string, c *dtpb.Coding) (bool, error) {
+	return m.memberOf(valueSet, c, map[string]bool{})
+}
+
+// Subsumes reports whether coding a subsumes coding b.
+func (m *Memory) Subsumes(a, b *dtpb.Coding) (bool, error) {
+	systemA, systemB := a.GetSystem().GetValue(), b.GetSystem().GetValue()
+	if systemA != "" && systemB != "" && systemA != systemB {
+		return false, fmt.Errorf("%w: %s and %s", ErrUnrelatedSystems, systemA, systemB)
+	}
+	codeA, codeB := a.GetCode().GetValue(), b.GetCode().GetValue()
+	if codeA == codeB {
+		return true, nil
+	}
+	system := systemA
+	if system == "" {
+		system = systemB
+	}
+	cs, ok := m.codeSystems[system]
+	if !ok {
+		return false, fmt.Errorf("%w: '%s'", ErrUnknownCodeSystem, system)
+	}
+	return cs.subsumes(codeA, codeB), nil
+}
+
+// memberOf implements MemberOf, tracking the value sets being visited so that
+// cyclic imports of value sets are not followed indefinitely.
+func (m *Memory) memberOf(url string, c *dtpb.Coding, visiting map[string]bool) (bool, error) {
+	vs, ok := m.valueSets[url]
+	if !ok {
+		return false, fmt.Errorf("%w: '%s'", ErrUnknownValueSet, url)
+	}
+	if visiting[url] {
+		return false, nil
+	}
+	visiting[url] = true
+	defer delete(visiting, url)
+
+	if expansion := vs.GetExpansion(); expansion != nil {
+		return expansionContains(expansion.GetContains(), c), nil
+	}
+	for _, exclude := range vs.GetCompose().GetExclude() {
+		excluded, err := m.conceptSetContains(exclude, c, visiting)
+		if err != nil || excluded {
+			return false, err
+		}
+	}
+	for _, include := range vs.GetCompose().GetInclude() {
+		included, err := m.conceptSetContains(include, c, visiting)
+		if err != nil || included {
+			return included, err
+		}
+	}
+	return false, nil
+}
+
+// conceptSetContains reports whether the coding is selected by the include or
+// exclude concept set of a value set compose.
+func (m *Memory) conceptSetContains(set *vspb.ValueSet_Compose_ConceptSet, c *dtpb.Coding, visiting map[string]bool) (bool, error) {
+	system := set.GetSystem().GetValue()
+	code := c.GetCode().GetValue()
+	if codingSystem := c.GetSystem().GetValue(); system != "" && codingSystem != "" && codingSystem != system {
+		return false, nil
+	}
+
+	// Concept sets that import value sets select the intersection of the
+	// imported value sets with the rest of the concept set.
+	for _, canonical := range set.GetValueSet() {
+		member, err := m.memberOf(canonical.GetValue(), c, visiting)
+		if err != nil || !member {
+			return false, err
+		}
+	}
+	if system == "" {
+		return len(set.GetValueSet()) > 0, nil
+	}
+
+	if len(set.GetConcept()) > 0 {
+		for _, concept := range set.GetConcept() {
+			if coding.Matches(c, system, concept.GetCode().GetValue()) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	cs, ok := m.codeSystems[system]
+	if !ok {
+		return false, fmt.Errorf("%w: '%s'", ErrUnknownCodeSystem, system)
+	}
+	if _, ok := cs.parents[code]; !ok {
+		return false, nil
+	}
+	for _, filter := range set.GetFilter() {
+		selected, err := cs.filter(filter, code)
+		if err != nil || !selected {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// expansionContains reports whether the coding is listed in the contains
+// element of a value set expansion, or any of its nested contains elements.
+func expansionContains(contains []*vspb.ValueSet_Expansion_Contains, c *dtpb.Coding) bool {
+	for _, entry := range contains {
+		if entry.GetCode() != nil && coding.Matches(c, entry.GetSystem().GetValue(), entry.GetCode().GetValue()) {
+			return true
+		}
+		if expansionContains(entry.GetContains(), c) {
+			return true
+		}
+	}
+	return false
+}
+
+// addConcepts adds the concepts, and the concepts nested within them, to the
+// hierarchy of the code system. The parent of top-level concepts is empty.
+func (cs *codeSystem) addConcepts(concepts []*cspb.CodeSystem_ConceptDefinition, parent string) {
+	for _, concept := range concepts {
+		code := concept.GetCode().GetValue()
+		if _, ok := cs.parents[code]; !ok {
+			cs.parents[code] = nil
+		}
+		if parent != "" {
+			cs.parents[code] = append(cs.parents[code], parent)
+		}
+		for _, property := range concept.GetProperty() {
+			if property.GetCode().GetValue() != "parent" {
+				continue
+			}
+			if value := property.GetValue().GetCode().GetValue(); value != "" {
+				cs.parents[code] = append(cs.parents[code], value)
+			}
+		}
+		cs.addConcepts(concept.GetConcept(), code)
+	}
+}
+
+// subsumes reports whether ancestor is the same concept as code, or one of its
+// ancestors.
+func (cs *codeSystem) subsumes(ancestor, code string) bool {
+	visited := map[string]bool{}
+	queue := []string{code}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current == ancestor {
+			return true
+		}
+		if visited[current] {
+			continue
+		}
+		visited[current] = true
+		queue = append(queue, cs.parents[current]...)
+	}
+	return false
+}
+
+// filter reports whether the code is selected by a value set compose filter
+// on the concept hierarchy of the code system.
+func (cs *codeSystem) filter(filter *vspb.ValueSet_Compose_ConceptSet_Filter, code string) (bool, error) {
+	op := filter.GetOp().GetValue()
+	if property := filter.GetProperty().GetValue(); property != "concept" {
+		return false, fmt.Errorf("%w: property '%s'", ErrUnsupportedFilter, property)
+	}
+	value := filter.GetValue().GetValue()
+	switch op {
+	case cpb.FilterOperatorCode_EQUALS:
+		return code == value, nil
+	case cpb.FilterOperatorCode_IS_A:
+		return cs.subsumes(value, code), nil
+	case cpb.FilterOperatorCode_DESCENDENT_OF:
+		return code != value && cs.subsumes(value, code), nil
+	case cpb.FilterOperatorCode_IS_NOT_A:
+		return !cs.subsumes(value, code), nil
+	case cpb.FilterOperatorCode_GENERALIZES:
+		return cs.subsumes(code, value), nil
+	default:
+		return false, fmt.Errorf("%w: operator %v", ErrUnsupportedFilter, op)
+	}
+}
@@ -0,0 +1,215 @@
+package terminology_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/fhir-fli/fhirpath-go/fhir"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/terminology"
+	cpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	cspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/code_system_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+	vspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/value_set_go_proto"
+)
+
+const (
+	colorSystem = "http://example.com/CodeSystem/colors"
+	shapeSystem = "http://example.com/CodeSystem/shapes"
+)
+
+func concept(code string, children ...*cspb.CodeSystem_ConceptDefinition) *cspb.CodeSystem_ConceptDefinition {
+	return &cspb.CodeSystem_ConceptDefinition{Code: fhir.Code(code), Concept: children}
+}
+
+func conceptWithParent(code, parent string) *cspb.CodeSystem_ConceptDefinition {
+	c := concept(code)
+	c.Property = []*cspb.CodeSystem_ConceptDefinition_ConceptProperty{
+		{
+			Code: fhir.Code("parent"),
+			Value: &cspb.CodeSystem_ConceptDefinition_ConceptProperty_ValueX{
+				Choice: &cspb.CodeSystem_ConceptDefinition_ConceptProperty_ValueX_Code{
+					Code: fhir.Code(parent),
+				},
+			},
+		},
+	}
+	return c
+}
+
+func filter(op cpb.FilterOperatorCode_Value, value string) *vspb.ValueSet_Compose_ConceptSet_Filter {
+	return &vspb.ValueSet_Compose_ConceptSet_Filter{
+		Property: fhir.Code("concept"),
+		Op:       &vspb.ValueSet_Compose_ConceptSet_Filter_OpCode{Value: op},
+		Value:    fhir.String(value),
+	}
+}
+
+func valueSet(url string, include []*vspb.ValueSet_Compose_ConceptSet, exclude ...*vspb.ValueSet_Compose_ConceptSet) *vspb.ValueSet {
+	return &vspb.ValueSet{
+		Url:     fhir.URI(url),
+		Compose: &vspb.ValueSet_Compose{Include: include, Exclude: exclude},
+	}
+}
+
+var colors = &cspb.CodeSystem{
+	Url: fhir.URI(colorSystem),
+	Concept: []*cspb.CodeSystem_ConceptDefinition{
+		concept("color",
+			concept("warm", concept("red"), concept("orange")),
+			concept("cool", concept("blue")),
+		),
+		conceptWithParent("crimson", "red"),
+	},
+}
+
+func newProvider(t *testing.T) *terminology.Memory {
+	t.Helper()
+	enumerated := valueSet("http://example.com/ValueSet/primary", []*vspb.ValueSet_Compose_ConceptSet{
+		{
+			System: fhir.URI(colorSystem),
+			Concept: []*vspb.ValueSet_Compose_ConceptSet_ConceptReference{
+				{Code: fhir.Code("red")},
+				{Code: fhir.Code("blue")},
+			},
+		},
+		{
+			System: fhir.URI(shapeSystem),
+			Concept: []*vspb.ValueSet_Compose_ConceptSet_ConceptReference{
+				{Code: fhir.Code("circle")},
+			},
+		},
+	})
+	enumerated.Version = fhir.String("1.0")
+	warm := valueSet("http://example.com/ValueSet/warm", []*vspb.ValueSet_Compose_ConceptSet{
+		{System: fhir.URI(colorSystem), Filter: []*vspb.ValueSet_Compose_ConceptSet_Filter{filter(cpb.FilterOperatorCode_IS_A, "warm")}},
+	})
+	nonWarm := valueSet("http://example.com/ValueSet/non-warm",
+		[]*vspb.ValueSet_Compose_ConceptSet{{System: fhir.URI(colorSystem)}},
+		&vspb.ValueSet_Compose_ConceptSet{ValueSet: []*dtpb.Canonical{{Value: "http://example.com/ValueSet/warm"}}},
+	)
+	warmPrimary := valueSet("http://example.com/ValueSet/warm-primary", []*vspb.ValueSet_Compose_ConceptSet{
+		{ValueSet: []*dtpb.Canonical{
+			{Value: "http://example.com/ValueSet/warm"},
+			{Value: "http://example.com/ValueSet/primary"},
+		}},
+	})
+	descendants := valueSet("http://example.com/ValueSet/warm-descendants", []*vspb.ValueSet_Compose_ConceptSet{
+		{System: fhir.URI(colorSystem), Filter: []*vspb.ValueSet_Compose_ConceptSet_Filter{filter(cpb.FilterOperatorCode_DESCENDENT_OF, "warm")}},
+	})
+	unsupported := valueSet("http://example.com/ValueSet/regex", []*vspb.ValueSet_Compose_ConceptSet{
+		{System: fhir.URI(colorSystem), Filter: []*vspb.ValueSet_Compose_ConceptSet_Filter{filter(cpb.FilterOperatorCode_REGEX, "r.*")}},
+	})
+	unknownSystem := valueSet("http://example.com/ValueSet/all-shapes", []*vspb.ValueSet_Compose_ConceptSet{
+		{System: fhir.URI(shapeSystem)},
+	})
+	expanded := &vspb.ValueSet{
+		Url: fhir.URI("http://example.com/ValueSet/expanded"),
+		Expansion: &vspb.ValueSet_Expansion{
+			Contains: []*vspb.ValueSet_Expansion_Contains{
+				{
+					System: fhir.URI(shapeSystem),
+					Code:   fhir.Code("square"),
+					Contains: []*vspb.ValueSet_Expansion_Contains{
+						{System: fhir.URI(shapeSystem), Code: fhir.Code("triangle")},
+					},
+				},
+			},
+		},
+	}
+
+	provider, err := terminology.NewMemory(colors, enumerated, warm, nonWarm, warmPrimary, descendants, unsupported, unknownSystem, expanded)
+	if err != nil {
+		t.Fatalf("NewMemory() returned unexpected error: %v", err)
+	}
+	return provider
+}
+
+func TestMemory_MemberOf(t *testing.T) {
+	provider := newProvider(t)
+
+	testCases := []struct {
+		name     string
+		valueSet string
+		coding   *dtpb.Coding
+		want     bool
+		wantErr  error
+	}{
+		{"enumerated concept", "http://example.com/ValueSet/primary", fhir.Coding(colorSystem, "red"), true, nil},
+		{"enumerated concept by version", "http://example.com/ValueSet/primary|1.0", fhir.Coding(colorSystem, "red"), true, nil},
+		{"enumerated concept from other system", "http://example.com/ValueSet/primary", fhir.Coding(shapeSystem, "red"), false, nil},
+		{"concept not enumerated", "http://example.com/ValueSet/primary", fhir.Coding(colorSystem, "orange"), false, nil},
+		{"code without system", "http://example.com/ValueSet/primary", &dtpb.Coding{Code: fhir.Code("circle")}, true, nil},
+		{"is-a filter includes concept", "http://example.com/ValueSet/warm", fhir.Coding(colorSystem, "warm"), true, nil},
+		{"is-a filter includes descendant", "http://example.com/ValueSet/warm", fhir.Coding(colorSystem, "crimson"), true, nil},
+		{"is-a filter excludes other concept", "http://example.com/ValueSet/warm", fhir.Coding(colorSystem, "blue"), false, nil},
+		{"descendent-of filter excludes concept", "http://example.com/ValueSet/warm-descendants", fhir.Coding(colorSystem, "warm"), false, nil},
+		{"descendent-of filter includes descendant", "http://example.com/ValueSet/warm-descendants", fhir.Coding(colorSystem, "orange"), true, nil},
+		{"whole code system", "http://example.com/ValueSet/non-warm", fhir.Coding(colorSystem, "blue"), true, nil},
+		{"whole code system with unknown code", "http://example.com/ValueSet/non-warm", fhir.Coding(colorSystem, "green"), false, nil},
+		{"excluded value set", "http://example.com/ValueSet/non-warm", fhir.Coding(colorSystem, "red"), false, nil},
+		{"intersection of value sets", "http://example.com/ValueSet/warm-primary", fhir.Coding(colorSystem, "red"), true, nil},
+		{"outside intersection of value sets", "http://example.com/ValueSet/warm-primary", fhir.Coding(colorSystem, "blue"), false, nil},
+		{"expansion", "http://example.com/ValueSet/expanded", fhir.Coding(shapeSystem, "square"), true, nil},
+		{"nested expansion", "http://example.com/ValueSet/expanded", fhir.Coding(shapeSystem, "triangle"), true, nil},
+		{"not in expansion", "http://example.com/ValueSet/expanded", fhir.Coding(shapeSystem, "circle"), false, nil},
+		{"unknown value set", "http://example.com/ValueSet/unknown", fhir.Coding(colorSystem, "red"), false, terminology.ErrUnknownValueSet},
+		{"unknown version", "http://example.com/ValueSet/primary|2.0", fhir.Coding(colorSystem, "red"), false, terminology.ErrUnknownValueSet},
+		{"unknown code system", "http://example.com/ValueSet/all-shapes", fhir.Coding(shapeSystem, "circle"), false, terminology.ErrUnknownCodeSystem},
+		{"unsupported filter", "http://example.com/ValueSet/regex", fhir.Coding(colorSystem, "red"), false, terminology.ErrUnsupportedFilter},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := provider.MemberOf(tc.valueSet, tc.coding)
+
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("MemberOf() got error %v, want %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("MemberOf() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMemory_Subsumes(t *testing.T) {
+	provider := newProvider(t)
+
+	testCases := []struct {
+		name    string
+		a, b    *dtpb.Coding
+		want    bool
+		wantErr error
+	}{
+		{"same concept", fhir.Coding(colorSystem, "red"), fhir.Coding(colorSystem, "red"), true, nil},
+		{"parent", fhir.Coding(colorSystem, "warm"), fhir.Coding(colorSystem, "red"), true, nil},
+		{"ancestor", fhir.Coding(colorSystem, "color"), fhir.Coding(colorSystem, "orange"), true, nil},
+		{"ancestor through parent property", fhir.Coding(colorSystem, "warm"), fhir.Coding(colorSystem, "crimson"), true, nil},
+		{"descendant", fhir.Coding(colorSystem, "red"), fhir.Coding(colorSystem, "warm"), false, nil},
+		{"sibling", fhir.Coding(colorSystem, "red"), fhir.Coding(colorSystem, "orange"), false, nil},
+		{"code without system", &dtpb.Coding{Code: fhir.Code("cool")}, fhir.Coding(colorSystem, "blue"), true, nil},
+		{"unrelated systems", fhir.Coding(colorSystem, "red"), fhir.Coding(shapeSystem, "red"), false, terminology.ErrUnrelatedSystems},
+		{"unknown code system", fhir.Coding(shapeSystem, "square"), fhir.Coding(shapeSystem, "circle"), false, terminology.ErrUnknownCodeSystem},
+		{"same concept in unknown code system", fhir.Coding(shapeSystem, "square"), fhir.Coding(shapeSystem, "square"), true, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := provider.Subsumes(tc.a, tc.b)
+
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Subsumes() got error %v, want %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("Subsumes() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestNewMemory_UnsupportedResource_ReturnsError(t *testing.T) {
+	if _, err := terminology.NewMemory(&ppb.Patient{}); err == nil {
+		t.Errorf("NewMemory() returned no error for a Patient")
+	}
+}
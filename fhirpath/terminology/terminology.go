@@ -0,0 +1,84 @@
+/*
+Package terminology provides the Provider abstraction used by the FHIRPath
+memberOf(), subsumes(), and subsumedBy() functions, along with an in-memory
+implementation that is loaded from ValueSet and CodeSystem resources.
+
+A Provider is supplied to evaluation with the evalopts.TerminologyProvider
+option. If no Provider is supplied, these functions yield an empty collection.
+*/
+package terminology
+
+import (
+	"errors"
+
+	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+)
+
+var (
+	ErrUnknownValueSet   = errors.New("unknown value set")
+	ErrUnknownCodeSystem = errors.New("unknown code system")
+	ErrUnrelatedSystems  = errors.New("codings are from unrelated code systems")
+	ErrUnsupportedFilter = errors.New("unsupported value set filter")
+)
+
+// Provider answers the terminology questions asked by FHIRPath expressions.
+//
+// Codings passed to a Provider may lack a system, when they originate from a
+// FHIR code element. Such codings are matched on code alone.
+type Provider interface {
+	// MemberOf reports whether the coding is a member of the value set
+	// identified by the given canonical URL, which may carry a version in the
+	// form "url|version". Returns an ErrUnknownValueSet error if the value set
+	// is not known to the provider.
+	MemberOf(valueSet string, coding *dtpb.Coding) (bool, error)
+
+	// Subsumes reports whether the concept of coding a subsumes the concept of
+	// coding b; that is, whether a is the same concept as b or one of its
+	// ancestors. Returns an ErrUnrelatedSystems or ErrUnknownCodeSystem error
+	// if the relationship between the codings cannot be determined.
+	Subsumes(a, b *dtpb.Coding) (bool, error)
+}
+
+// Chain returns a Provider that asks each of the providers in order, and
+// returns the first answer that is determined. Providers that return an
+// ErrUnknownValueSet, ErrUnknownCodeSystem, or ErrUnrelatedSystems error are
+// skipped; if no provider determines the answer, the error of the last one is
+// returned. Any other error stops the chain.
+func Chain(providers ...Provider) Provider {
+	return chain(providers)
+}
+
+// chain is a Provider that asks each of its providers in order.
+type chain []Provider
+
+// MemberOf returns the first membership that is determined by the chain.
+func (c chain) MemberOf(valueSet string, coding *dtpb.Coding) (bool, error) {
+	return c.ask(func(p Provider) (bool, error) { return p.MemberOf(valueSet, coding) })
+}
+
+// Subsumes returns the first subsumption that is determined by the chain.
+func (c chain) Subsumes(a, b *dtpb.Coding) (bool, error) {
+	return c.ask(func(p Provider) (bool, error) { return p.Subsumes(a, b) })
+}
+
+// ask returns the first answer of question that is determined by a provider
+// of the chain.
+func (c chain) ask(question func(Provider) (bool, error)) (bool, error) {
+	err := ErrUnknownValueSet
+	for _, provider := range c {
+		var answer bool
+		answer, err = question(provider)
+		if !isIndeterminate(err) {
+			return answer, err
+		}
+	}
+	return false, err
+}
+
+// isIndeterminate returns true if err means that a provider can't determine
+// the answer, such that another provider may.
+func isIndeterminate(err error) bool {
+	return errors.Is(err, ErrUnknownValueSet) ||
+		errors.Is(err, ErrUnknownCodeSystem) ||
+		errors.Is(err, ErrUnrelatedSystems)
+}
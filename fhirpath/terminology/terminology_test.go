@@ -0,0 +1,28 @@
+package terminology_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/fhir-fli/fhirpath-go/fhir"
+	"github.com/fhir-fli/fhirpath-go/fhirpath/terminology"
+)
+
+func TestChain(t *testing.T) {
+	empty, err := terminology.NewMemory()
+	if err != nil {
+		t.Fatalf("NewMemory() returned unexpected error: %v", err)
+	}
+	chain := terminology.Chain(empty, newProvider(t))
+	red := fhir.Coding(colorSystem, "red")
+
+	if got, err := chain.MemberOf("http://example.com/ValueSet/warm", red); err != nil || !got {
+		t.Errorf("Chain().MemberOf() = %v, %v, want true, nil", got, err)
+	}
+	if got, err := chain.Subsumes(fhir.Coding(colorSystem, "warm"), red); err != nil || !got {
+		t.Errorf("Chain().Subsumes() = %v, %v, want true, nil", got, err)
+	}
+	if _, err := chain.MemberOf("http://example.com/ValueSet/unknown", red); !errors.Is(err, terminology.ErrUnknownValueSet) {
+		t.Errorf("Chain().MemberOf() returned error %v, want %v", err, terminology.ErrUnknownValueSet)
+	}
+}
@@ -1,7 +1,9 @@
 package coding
 
 import (
+	"github.com/fhir-fli/fhirpath-go/internal/protofields"
 	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	"google.golang.org/protobuf/proto"
 )
 
 // FindBySystem searches a slice of codings for the first coding that
@@ -18,3 +20,31 @@ func FindBySystem(codings []*dtpb.Coding, system string) *dtpb.Coding {
 	}
 	return nil
 }
+
+// FromElement returns the codings represented by a Coding, CodeableConcept,
+// or code element. A code is returned as a Coding without a system, since the
+// system is only implied by the binding of the element that holds it. Returns
+// false if the element is not one of these types.
+func FromElement(element proto.Message) ([]*dtpb.Coding, bool) {
+	switch element := element.(type) {
+	case *dtpb.Coding:
+		return []*dtpb.Coding{element}, true
+	case *dtpb.CodeableConcept:
+		return element.GetCoding(), true
+	}
+	if code, ok := protofields.StringValueFromCodeField(element); ok {
+		return []*dtpb.Coding{{Code: &dtpb.Code{Value: code}}}, true
+	}
+	return nil, false
+}
+
+// Matches returns true if the coding has the given code, and either has the
+// given system or does not specify a system. An empty system matches any
+// coding with the given code.
+func Matches(coding *dtpb.Coding, system, code string) bool {
+	if coding.GetCode().GetValue() != code {
+		return false
+	}
+	codingSystem := coding.GetSystem().GetValue()
+	return system == "" || codingSystem == "" || codingSystem == system
+}
@@ -0,0 +1,92 @@
+package coding_test
+
+import (
+	"testing"
+
+	"github.com/fhir-fli/fhirpath-go/fhir"
+	"github.com/fhir-fli/fhirpath-go/internal/element/coding"
+	cpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+func TestFromElement(t *testing.T) {
+	loinc := fhir.Coding("http://loinc.org", "1234-5")
+	snomed := fhir.Coding("http://snomed.info/sct", "12345")
+
+	testCases := []struct {
+		name    string
+		element proto.Message
+		want    []*dtpb.Coding
+		wantOK  bool
+	}{
+		{
+			name:    "Coding",
+			element: loinc,
+			want:    []*dtpb.Coding{loinc},
+			wantOK:  true,
+		},
+		{
+			name:    "CodeableConcept",
+			element: fhir.CodeableConcept("text", loinc, snomed),
+			want:    []*dtpb.Coding{loinc, snomed},
+			wantOK:  true,
+		},
+		{
+			name:    "Code",
+			element: fhir.Code("active"),
+			want:    []*dtpb.Coding{{Code: fhir.Code("active")}},
+			wantOK:  true,
+		},
+		{
+			name:    "enum code",
+			element: &ppb.Patient_GenderCode{Value: cpb.AdministrativeGenderCode_FEMALE},
+			want:    []*dtpb.Coding{{Code: fhir.Code("female")}},
+			wantOK:  true,
+		},
+		{
+			name:    "other element",
+			element: fhir.String("active"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := coding.FromElement(tc.element)
+
+			if ok != tc.wantOK {
+				t.Errorf("FromElement() got ok %v, want %v", ok, tc.wantOK)
+			}
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("FromElement() returned unexpected diff (-want, +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMatches(t *testing.T) {
+	testCases := []struct {
+		name   string
+		coding *dtpb.Coding
+		system string
+		code   string
+		want   bool
+	}{
+		{"same system and code", fhir.Coding("http://loinc.org", "1234-5"), "http://loinc.org", "1234-5", true},
+		{"different system", fhir.Coding("http://loinc.org", "1234-5"), "http://snomed.info/sct", "1234-5", false},
+		{"different code", fhir.Coding("http://loinc.org", "1234-5"), "http://loinc.org", "1234-6", false},
+		{"coding without system", &dtpb.Coding{Code: fhir.Code("1234-5")}, "http://loinc.org", "1234-5", true},
+		{"any system", fhir.Coding("http://loinc.org", "1234-5"), "", "1234-5", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := coding.Matches(tc.coding, tc.system, tc.code); got != tc.want {
+				t.Errorf("Matches(%v, %v) = %v, want %v", tc.system, tc.code, got, tc.want)
+			}
+		})
+	}
+}
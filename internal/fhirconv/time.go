@@ -72,25 +72,49 @@ func TimeToDuration(dt *dtpb.Time) time.Duration {
 
 // parseLocation attempts to parse the timezone location from the zone string.
 //
-// Timezones may be specified in one of 3 formats:
+// Timezones may be specified in one of 4 formats:
 //   - Z
 //   - +zz:zz or -zz:zz
 //   - UTC (or some name)
+//   - an IANA timezone name, such as America/New_York
 //
-// Additionally, this function supports empty strings being translated into
-// UTC.
+// The returned location is named after the zone string, so that the original
+// string can be recovered with TimeToTimezone. Additionally, this function
+// supports empty strings being translated into UTC.
 func parseLocation(zone string) (*time.Location, error) {
 	if zone == "" {
 		return time.UTC, nil
 	} else if tm, err := time.Parse("MST", zone); err == nil {
-		return tm.Location(), nil
+		_, offset := tm.Zone()
+		return time.FixedZone(zone, offset), nil
 	} else if tm, err := time.Parse("Z07:00", zone); err == nil {
-		return tm.Location(), nil
+		_, offset := tm.Zone()
+		return time.FixedZone(zone, offset), nil
+	} else if loc, err := time.LoadLocation(zone); err == nil && zone != "Local" {
+		return loc, nil
 	} else {
 		return nil, fmt.Errorf("unable to parse time-zone from '%v'", zone)
 	}
 }
 
+// TimeToTimezone returns the FHIR timezone string for the location of t.
+//
+// This is the original zone string for times converted from FHIR elements,
+// such as "Z" or "America/New_York", and the UTC offset of t in the form
+// `+zz:zz` or `-zz:zz` otherwise.
+func TimeToTimezone(t time.Time) string {
+	if loc := t.Location(); loc != time.UTC && loc != time.Local && loc.String() != "" {
+		return loc.String()
+	}
+	_, offset := t.Zone()
+	sign := "+"
+	if offset < 0 {
+		sign = "-"
+		offset = -offset
+	}
+	return fmt.Sprintf("%s%02d:%02d", sign, offset/3600, offset%3600/60)
+}
+
 // DurationToDuration converts a FHIR Duration element into a Go native
 // time.Duration object.
 //
@@ -106,13 +130,14 @@ func DurationToDuration(d *dtpb.Duration) (time.Duration, error) {
 	code := d.GetCode().GetValue()
 
 	unit, err := units.TimeFromSymbol(code)
-	if err != nil {
+	if err != nil || unit == units.Months || unit == units.Years {
+		// Calendar months and years don't have a fixed length.
 		return durationToDurationError("invalid unit symbol '%v'", code)
 	}
 
 	symbol := unit.Symbol()
 
-	// Special handling is necessary as days are not supported by
+	// Special handling is necessary as days and weeks are not supported by
 	// time.ParseDuration, as well as the minutes unit being m, not min
 	switch unit {
 	case units.Minutes:
@@ -120,6 +145,9 @@ func DurationToDuration(d *dtpb.Duration) (time.Duration, error) {
 	case units.Days:
 		decimal *= 24
 		symbol = units.Hours.Symbol()
+	case units.Weeks:
+		decimal *= 7 * 24
+		symbol = units.Hours.Symbol()
 	}
 
 	duration, err := time.ParseDuration(fmt.Sprintf("%v%s", decimal, symbol))
@@ -288,3 +288,55 @@ func TestDurationToDuration_RoundTrip(t *testing.T) {
 		})
 	}
 }
+
+func TestTimeToTimezone_RoundTrip(t *testing.T) {
+	testCases := []struct {
+		name string
+		zone string
+	}{
+		{"UTC", "UTC"},
+		{"Z", "Z"},
+		{"ZeroOffset", "+00:00"},
+		{"PositiveOffset", "+05:30"},
+		{"NegativeOffset", "-13:04"},
+		{"LocationName", "America/New_York"},
+	}
+	const value = 1000
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dt := fhir.DateTime(time.UnixMicro(value))
+			dt.Timezone = tc.zone
+
+			tm, err := fhirconv.DateTimeToTime(dt)
+			if err != nil {
+				t.Fatalf("DateTimeToTime(%v): got err '%v', want nil", tc.name, err)
+			}
+
+			if got, want := fhirconv.TimeToTimezone(tm), tc.zone; got != want {
+				t.Errorf("TimeToTimezone(%v): got '%v', want '%v'", tc.name, got, want)
+			}
+		})
+	}
+}
+
+func TestTimeToTimezone_NonFHIRLocation_ReturnsOffset(t *testing.T) {
+	testCases := []struct {
+		name     string
+		location *time.Location
+		want     string
+	}{
+		{"UTC", time.UTC, "+00:00"},
+		{"UnnamedOffset", time.FixedZone("", -9000), "-02:30"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tm := time.UnixMicro(0).In(tc.location)
+
+			if got := fhirconv.TimeToTimezone(tm); got != tc.want {
+				t.Errorf("TimeToTimezone(%v): got '%v', want '%v'", tc.name, got, tc.want)
+			}
+		})
+	}
+}
@@ -1,5 +1,6 @@
 /*
 Package units provides basic unit constants that are used for various FHIR
-Quantity types.
+Quantity types, and an offline parser for UCUM unit expressions that converts
+values between commensurable units.
 */
 package units
@@ -26,6 +26,15 @@ const (
 
 	// Days is a Time unit that measures time in days.
 	Days
+
+	// Weeks is a Time unit that measures time in weeks.
+	Weeks
+
+	// Months is a Time unit that measures time in calendar months.
+	Months
+
+	// Years is a Time unit that measures time in calendar years.
+	Years
 )
 
 const (
@@ -36,6 +45,9 @@ const (
 	minutesSymbol     = "min"
 	hoursSymbol       = "h"
 	daysSymbol        = "d"
+	weeksSymbol       = "wk"
+	monthsSymbol      = "mo"
+	yearsSymbol       = "a"
 )
 
 // Symbol returns the symbol used to represent the underlying unit.
@@ -55,6 +67,12 @@ func (t Time) Symbol() string {
 		return hoursSymbol
 	case Days:
 		return daysSymbol
+	case Weeks:
+		return weeksSymbol
+	case Months:
+		return monthsSymbol
+	case Years:
+		return yearsSymbol
 	}
 	// This is a closed enumeration in an internal package. If this panic ever
 	// gets reached, it means that a developer is using this package wrong.
@@ -83,6 +101,36 @@ func TimeFromSymbol(symbol string) (Time, error) {
 		return Hours, nil
 	case daysSymbol:
 		return Days, nil
+	case weeksSymbol:
+		return Weeks, nil
+	case monthsSymbol:
+		return Months, nil
+	case yearsSymbol:
+		return Years, nil
 	}
 	return Time(0), fmt.Errorf("unknown Time symbol '%v'", symbol)
 }
+
+// TimeFromKeyword creates the Time object from a FHIRPath calendar duration
+// keyword, such as 'year' or 'days'.
+func TimeFromKeyword(keyword string) (Time, error) {
+	switch keyword {
+	case "millisecond", "milliseconds":
+		return Milliseconds, nil
+	case "second", "seconds":
+		return Seconds, nil
+	case "minute", "minutes":
+		return Minutes, nil
+	case "hour", "hours":
+		return Hours, nil
+	case "day", "days":
+		return Days, nil
+	case "week", "weeks":
+		return Weeks, nil
+	case "month", "months":
+		return Months, nil
+	case "year", "years":
+		return Years, nil
+	}
+	return Time(0), fmt.Errorf("unknown Time keyword '%v'", keyword)
+}
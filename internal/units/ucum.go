@@ -0,0 +1,570 @@
+package units
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	// ErrInvalidUCUM is returned when a unit code can't be parsed as a UCUM
+	// unit expression.
+	ErrInvalidUCUM = errors.New("invalid UCUM unit")
+
+	// ErrIncommensurable is returned when converting between UCUM units that
+	// don't measure the same kind of quantity.
+	ErrIncommensurable = errors.New("incommensurable UCUM units")
+)
+
+const (
+	// divisionPrecision is the number of decimal places kept when dividing
+	// conversion factors.
+	divisionPrecision = 40
+
+	// conversionDigits is the number of significant digits that converted
+	// values are rounded to, which hides the rounding errors of irrational
+	// factors such as those of degrees and radians.
+	conversionDigits = 20
+
+	// maxExponent is the greatest magnitude of the exponent of a simple unit.
+	// Units are raised to their exponent by repeated multiplication, so larger
+	// exponents, which measure no physical quantity, are rejected.
+	maxExponent = 99
+)
+
+// UCUM is a unit of the Unified Code for Units of Measure, expressed as a
+// multiple of the UCUM base units.
+//
+// See: https://ucum.org/ucum
+type UCUM struct {
+	code      string
+	terms     []ucumTerm
+	factor    decimal.Decimal
+	dimension dimension
+
+	// offset is the offset of the zero of a special unit from the zero of its
+	// base unit, such as 273.15 for Cel. Units with an offset can't be combined
+	// with other units.
+	offset decimal.Decimal
+}
+
+// ParseUCUM parses a UCUM unit expression, such as "mg/dL" or "kg.m/s2", and
+// canonicalizes it into its base units. Annotations such as "{cells}" are
+// treated as the unity.
+func ParseUCUM(code string) (UCUM, error) {
+	p := &ucumParser{input: code}
+	terms, err := p.parseMainTerm()
+	if err != nil {
+		return UCUM{}, fmt.Errorf("%w '%v': %v", ErrInvalidUCUM, code, err)
+	}
+	unit, err := canonicalize(terms)
+	if err != nil {
+		return UCUM{}, fmt.Errorf("%w '%v': %v", ErrInvalidUCUM, code, err)
+	}
+	unit.code = code
+	return unit, nil
+}
+
+// Code returns the UCUM code of u.
+func (u UCUM) Code() string {
+	return u.code
+}
+
+// IsUnity returns true if u is the unity, such as a product of units that
+// cancel each other out.
+func (u UCUM) IsUnity() bool {
+	for _, term := range u.terms {
+		if term.symbol != "1" || term.annotation != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// IsDimensionless returns true if u has no base units, such as "cm/m" or
+// "mg/g", so that its values are plain numbers scaled by its factor. Units
+// with annotations, and special units such as Cel, aren't dimensionless.
+func (u UCUM) IsDimensionless() bool {
+	if len(u.dimension) != 0 || !u.offset.IsZero() {
+		return false
+	}
+	for _, term := range u.terms {
+		if term.annotation != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// Commensurable returns true if u and other measure the same kind of quantity,
+// such that values can be converted between them.
+func (u UCUM) Commensurable(other UCUM) bool {
+	return u.dimension.equal(other.dimension)
+}
+
+// Convert converts value in the unit u into the unit to. Returns an error if
+// the units aren't commensurable.
+func (u UCUM) Convert(value decimal.Decimal, to UCUM) (decimal.Decimal, error) {
+	if !u.Commensurable(to) {
+		return decimal.Decimal{}, fmt.Errorf("%w: '%v' and '%v'", ErrIncommensurable, u.code, to.code)
+	}
+	base := value.Add(u.offset).Mul(u.factor)
+	converted := roundSignificant(base.DivRound(to.factor, divisionPrecision), conversionDigits)
+	return converted.Sub(to.offset), nil
+}
+
+// Mul returns the product of u and other, such as "kg.m" for "kg" and "m".
+// Exponents of the same unit symbols are combined, so that they cancel out in
+// "mg/kg" and "kg".
+func (u UCUM) Mul(other UCUM) (UCUM, error) {
+	return u.compose(other, 1)
+}
+
+// Div returns the quotient of u and other, such as "kg/m2" for "kg" and "m2".
+// Exponents of the same unit symbols are combined, so that the quotient of
+// "mg" and "mg" is the unity.
+func (u UCUM) Div(other UCUM) (UCUM, error) {
+	return u.compose(other, -1)
+}
+
+// compose returns the product of u and other raised to sign.
+func (u UCUM) compose(other UCUM, sign int) (UCUM, error) {
+	terms := append([]ucumTerm{}, u.terms...)
+	for _, term := range other.terms {
+		term.exponent *= sign
+		if i := indexOfTerm(terms, term); i >= 0 {
+			terms[i].exponent += term.exponent
+			if abs(terms[i].exponent) > maxExponent {
+				return UCUM{}, fmt.Errorf("%w: exponent of '%v' exceeds %v", ErrInvalidUCUM, term.symbol, maxExponent)
+			}
+			continue
+		}
+		terms = append(terms, term)
+	}
+
+	simplified := terms[:0]
+	for _, term := range terms {
+		if term.exponent != 0 && term.symbol != "1" {
+			simplified = append(simplified, term)
+		}
+	}
+	code := formatTerms(simplified)
+	unit, err := canonicalize(simplified)
+	if err != nil {
+		return UCUM{}, fmt.Errorf("%w '%v': %v", ErrInvalidUCUM, code, err)
+	}
+	unit.code = code
+	return unit, nil
+}
+
+// product returns the product of u and other, without composing their terms.
+func (u UCUM) product(other UCUM) (UCUM, error) {
+	if !u.offset.IsZero() || !other.offset.IsZero() {
+		return UCUM{}, errors.New("special units can't be combined with other units")
+	}
+	return UCUM{
+		factor:    u.factor.Mul(other.factor),
+		dimension: u.dimension.add(other.dimension, 1),
+	}, nil
+}
+
+// pow returns u raised to the integer exponent.
+func (u UCUM) pow(exponent int) (UCUM, error) {
+	if exponent == 1 {
+		return u, nil
+	}
+	if !u.offset.IsZero() {
+		return UCUM{}, errors.New("special units can't have an exponent")
+	}
+	factor := decimal.New(1, 0)
+	for i := 0; i < abs(exponent); i++ {
+		factor = factor.Mul(u.factor)
+	}
+	if exponent < 0 {
+		factor = decimal.New(1, 0).DivRound(factor, divisionPrecision)
+	}
+	return UCUM{
+		factor:    factor,
+		dimension: dimension{}.add(u.dimension, exponent),
+	}, nil
+}
+
+// unity is the UCUM unit "1".
+func unity() UCUM {
+	return UCUM{factor: decimal.New(1, 0), dimension: dimension{}}
+}
+
+// dimension maps the base units of a UCUM unit to their exponents. Arbitrary
+// units, such as [IU], are their own base units.
+type dimension map[string]int
+
+// add returns the dimension of d multiplied by other raised to exponent.
+func (d dimension) add(other dimension, exponent int) dimension {
+	result := dimension{}
+	for base, power := range d {
+		result[base] = power
+	}
+	for base, power := range other {
+		result[base] += power * exponent
+		if result[base] == 0 {
+			delete(result, base)
+		}
+	}
+	return result
+}
+
+// equal returns true if d and other have the same base units and exponents.
+func (d dimension) equal(other dimension) bool {
+	if len(d) != len(other) {
+		return false
+	}
+	for base, power := range d {
+		if other[base] != power {
+			return false
+		}
+	}
+	return true
+}
+
+// ucumTerm is a simple unit of a UCUM expression, raised to an exponent.
+type ucumTerm struct {
+	// symbol is the optionally prefixed atom or the integer factor of the
+	// term, which is empty for a standalone annotation.
+	symbol     string
+	annotation string
+	exponent   int
+}
+
+// canonicalize returns the product of terms in base units.
+func canonicalize(terms []ucumTerm) (UCUM, error) {
+	unit := unity()
+	for i, term := range terms {
+		resolved, err := term.resolve()
+		if err != nil {
+			return UCUM{}, err
+		}
+		if i == 0 {
+			unit = resolved
+		} else if unit, err = unit.product(resolved); err != nil {
+			return UCUM{}, err
+		}
+	}
+	unit.terms = terms
+	return unit, nil
+}
+
+// resolve returns the unit of t in base units.
+func (t ucumTerm) resolve() (UCUM, error) {
+	var unit UCUM
+	switch {
+	case t.symbol == "":
+		unit = unity()
+	case isDigits(t.symbol):
+		factor, err := decimal.NewFromString(t.symbol)
+		if err != nil {
+			return UCUM{}, err
+		}
+		unit = UCUM{factor: factor, dimension: dimension{}}
+	default:
+		var err error
+		if unit, err = resolveAtom(t.symbol); err != nil {
+			return UCUM{}, err
+		}
+	}
+	return unit.pow(t.exponent)
+}
+
+// indexOfTerm returns the index of the term in terms with the same symbol and
+// annotation as term, or -1 if there isn't one.
+func indexOfTerm(terms []ucumTerm, term ucumTerm) int {
+	for i, t := range terms {
+		if t.symbol == term.symbol && t.annotation == term.annotation {
+			return i
+		}
+	}
+	return -1
+}
+
+// formatTerms returns the UCUM expression for the product of terms, with the
+// terms with negative exponents following a division.
+func formatTerms(terms []ucumTerm) string {
+	var numerator, denominator []string
+	for _, term := range terms {
+		if term.exponent > 0 {
+			numerator = append(numerator, term.format()...)
+		} else {
+			denominator = append(denominator, term.format()...)
+		}
+	}
+	code := strings.Join(numerator, ".")
+	for _, component := range denominator {
+		code += "/" + component
+	}
+	if code == "" {
+		return "1"
+	}
+	return code
+}
+
+// format returns the components of t with the magnitude of its exponent.
+// Integer factors and standalone annotations can't have an exponent, so
+// they're repeated instead.
+func (t ucumTerm) format() []string {
+	exponent := abs(t.exponent)
+	if t.symbol == "" || isDigits(t.symbol) {
+		components := make([]string, exponent)
+		for i := range components {
+			components[i] = t.symbol + t.annotation
+		}
+		return components
+	}
+	if exponent == 1 {
+		return []string{t.symbol + t.annotation}
+	}
+	return []string{t.symbol + strconv.Itoa(exponent) + t.annotation}
+}
+
+// ucumParser parses UCUM expressions into their terms, following the grammar
+// of the UCUM specification.
+//
+// See: https://ucum.org/ucum#section-Syntax-Rules
+type ucumParser struct {
+	input string
+	pos   int
+}
+
+// parseMainTerm parses a whole UCUM expression, which is a term optionally
+// preceded by a division.
+func (p *ucumParser) parseMainTerm() ([]ucumTerm, error) {
+	if p.input == "" {
+		return nil, errors.New("empty unit")
+	}
+	sign := 1
+	if p.consume('/') {
+		sign = -1
+	}
+	terms, err := p.parseTerm(sign)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.input) {
+		return nil, fmt.Errorf("unexpected '%c' at position %v", p.input[p.pos], p.pos)
+	}
+	return terms, nil
+}
+
+// parseTerm parses components joined by the multiplication and division
+// operators, which are left-associative, such that a division only applies
+// to the component that follows it. The exponents of the first component are
+// multiplied by sign.
+func (p *ucumParser) parseTerm(sign int) ([]ucumTerm, error) {
+	var terms []ucumTerm
+	for {
+		component, err := p.parseComponent()
+		if err != nil {
+			return nil, err
+		}
+		for _, term := range component {
+			term.exponent *= sign
+			terms = append(terms, term)
+		}
+		switch {
+		case p.consume('.'):
+			sign = 1
+		case p.consume('/'):
+			sign = -1
+		default:
+			return terms, nil
+		}
+	}
+}
+
+// parseComponent parses a parenthesized term, an annotation, an integer
+// factor, or a simple unit with an optional exponent and annotation.
+func (p *ucumParser) parseComponent() ([]ucumTerm, error) {
+	if p.consume('(') {
+		terms, err := p.parseTerm(1)
+		if err != nil {
+			return nil, err
+		}
+		if !p.consume(')') {
+			return nil, errors.New("missing ')'")
+		}
+		return terms, nil
+	}
+	term := ucumTerm{exponent: 1}
+	if !p.peek('{') {
+		symbol, err := p.parseSymbol()
+		if err != nil {
+			return nil, err
+		}
+		if term.symbol, term.exponent, err = splitExponent(symbol); err != nil {
+			return nil, err
+		}
+	}
+	if p.peek('{') {
+		annotation, err := p.parseAnnotation()
+		if err != nil {
+			return nil, err
+		}
+		term.annotation = annotation
+	}
+	return []ucumTerm{term}, nil
+}
+
+// parseAnnotation returns the next annotation, including its curly braces.
+func (p *ucumParser) parseAnnotation() (string, error) {
+	end := strings.IndexByte(p.input[p.pos:], '}')
+	if end < 0 {
+		return "", errors.New("missing '}'")
+	}
+	annotation := p.input[p.pos : p.pos+end+1]
+	p.pos += end + 1
+	return annotation, nil
+}
+
+// parseSymbol returns the characters of a simple unit and its exponent, up to
+// the next operator, parenthesis or annotation. Square brackets may hold any
+// of these characters.
+func (p *ucumParser) parseSymbol() (string, error) {
+	start := p.pos
+	brackets := 0
+	for ; p.pos < len(p.input); p.pos++ {
+		c := p.input[p.pos]
+		if brackets == 0 && strings.IndexByte("./(){}", c) >= 0 {
+			break
+		}
+		switch c {
+		case '[':
+			brackets++
+		case ']':
+			brackets--
+		}
+	}
+	if brackets != 0 {
+		return "", errors.New("unbalanced '['")
+	}
+	if p.pos == start {
+		return "", fmt.Errorf("missing unit at position %v", start)
+	}
+	return p.input[start:p.pos], nil
+}
+
+// consume advances past c if it's the next character.
+func (p *ucumParser) consume(c byte) bool {
+	if p.peek(c) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// peek returns true if c is the next character.
+func (p *ucumParser) peek(c byte) bool {
+	return p.pos < len(p.input) && p.input[p.pos] == c
+}
+
+// splitExponent splits a simple unit symbol, such as "m2" or "s-1", into its
+// atom and exponent. Integer factors, such as "1000", have no exponent.
+func splitExponent(symbol string) (string, int, error) {
+	if isDigits(symbol) {
+		return symbol, 1, nil
+	}
+	start := len(symbol)
+	for start > 0 && isDigits(symbol[start-1:start]) {
+		start--
+	}
+	if start == len(symbol) {
+		return symbol, 1, nil
+	}
+	if start > 0 && (symbol[start-1] == '+' || symbol[start-1] == '-') {
+		start--
+	}
+	if start == 0 {
+		return "", 0, fmt.Errorf("missing unit before exponent '%v'", symbol)
+	}
+	exponent, err := strconv.Atoi(symbol[start:])
+	if err != nil {
+		return "", 0, err
+	}
+	if abs(exponent) > maxExponent {
+		return "", 0, fmt.Errorf("exponent '%v' exceeds %v", symbol[start:], maxExponent)
+	}
+	return symbol[:start], exponent, nil
+}
+
+// resolveAtom returns the unit of an atom, which may be preceded by a prefix
+// if it's a metric unit.
+func resolveAtom(symbol string) (UCUM, error) {
+	if definition, ok := ucumAtoms[symbol]; ok {
+		return definition.resolve(symbol)
+	}
+	for _, prefix := range ucumPrefixes {
+		if !strings.HasPrefix(symbol, prefix.symbol) {
+			continue
+		}
+		atom := strings.TrimPrefix(symbol, prefix.symbol)
+		definition, ok := ucumAtoms[atom]
+		if !ok || !definition.metric {
+			continue
+		}
+		unit, err := definition.resolve(atom)
+		if err != nil {
+			return UCUM{}, err
+		}
+		unit.factor = unit.factor.Mul(decimal.New(1, prefix.exponent))
+		return unit, nil
+	}
+	return UCUM{}, fmt.Errorf("unknown unit '%v'", symbol)
+}
+
+// resolve returns the unit of an atom from its definition.
+func (a ucumAtom) resolve(symbol string) (UCUM, error) {
+	if a.base {
+		return UCUM{factor: decimal.New(1, 0), dimension: dimension{symbol: 1}}, nil
+	}
+	terms, err := (&ucumParser{input: a.unit}).parseMainTerm()
+	if err != nil {
+		return UCUM{}, fmt.Errorf("definition of '%v': %w", symbol, err)
+	}
+	unit, err := canonicalize(terms)
+	if err != nil {
+		return UCUM{}, fmt.Errorf("definition of '%v': %w", symbol, err)
+	}
+	unit.factor = unit.factor.Mul(decimal.RequireFromString(a.value))
+	if a.offset != "" {
+		unit.offset = decimal.RequireFromString(a.offset)
+	}
+	return unit, nil
+}
+
+// roundSignificant rounds d to the given number of significant digits.
+func roundSignificant(d decimal.Decimal, digits int32) decimal.Decimal {
+	if d.IsZero() {
+		return d
+	}
+	magnitude := int32(len(d.Abs().Coefficient().String())) + d.Exponent()
+	return d.Round(digits - magnitude)
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,164 @@
+package units
+
+// ucumPrefix is a UCUM prefix, which multiplies a metric unit by a power of
+// ten.
+type ucumPrefix struct {
+	symbol   string
+	exponent int32
+}
+
+// ucumPrefixes are the UCUM prefixes. Longer symbols come first, so that "da"
+// is matched before "d".
+var ucumPrefixes = []ucumPrefix{
+	{"da", 1},
+	{"Y", 24}, {"Z", 21}, {"E", 18}, {"P", 15}, {"T", 12}, {"G", 9},
+	{"M", 6}, {"k", 3}, {"h", 2}, {"d", -1}, {"c", -2}, {"m", -3},
+	{"u", -6}, {"n", -9}, {"p", -12}, {"f", -15}, {"a", -18}, {"z", -21},
+	{"y", -24},
+}
+
+// ucumAtom defines a UCUM unit atom as a multiple of a UCUM expression.
+type ucumAtom struct {
+	// metric is true if the atom may be preceded by a prefix.
+	metric bool
+
+	// base is true for the base units, and for arbitrary units which can only
+	// be compared with themselves.
+	base bool
+
+	// value and unit define the atom as value times unit.
+	value string
+	unit  string
+
+	// offset is the offset of the zero of a special unit on the scale of
+	// value times unit, such as 273.15 for Cel.
+	offset string
+}
+
+// ucumAtoms are the supported UCUM unit atoms, covering the base units, the SI
+// and other metric units, and the common customary and clinical units.
+//
+// See: https://ucum.org/ucum#section-Tables-of-Terminal-Symbols
+var ucumAtoms = map[string]ucumAtom{
+	// Base units.
+	"m":   {metric: true, base: true},
+	"s":   {metric: true, base: true},
+	"g":   {metric: true, base: true},
+	"rad": {metric: true, base: true},
+	"K":   {metric: true, base: true},
+	"C":   {metric: true, base: true},
+	"cd":  {metric: true, base: true},
+
+	// Dimensionless units.
+	"10*":    {value: "10", unit: "1"},
+	"10^":    {value: "10", unit: "1"},
+	"[pi]":   {value: "3.1415926535897932384626433832795028841971693993751", unit: "1"},
+	"%":      {value: "1", unit: "10*-2"},
+	"[ppth]": {value: "1", unit: "10*-3"},
+	"[ppm]":  {value: "1", unit: "10*-6"},
+	"[ppb]":  {value: "1", unit: "10*-9"},
+	"[pptr]": {value: "1", unit: "10*-12"},
+
+	// SI units.
+	"mol": {metric: true, value: "6.02214076", unit: "10*23"},
+	"sr":  {metric: true, value: "1", unit: "rad2"},
+	"Hz":  {metric: true, value: "1", unit: "s-1"},
+	"N":   {metric: true, value: "1", unit: "kg.m/s2"},
+	"Pa":  {metric: true, value: "1", unit: "N/m2"},
+	"J":   {metric: true, value: "1", unit: "N.m"},
+	"W":   {metric: true, value: "1", unit: "J/s"},
+	"A":   {metric: true, value: "1", unit: "C/s"},
+	"V":   {metric: true, value: "1", unit: "J/C"},
+	"F":   {metric: true, value: "1", unit: "C/V"},
+	"Ohm": {metric: true, value: "1", unit: "V/A"},
+	"S":   {metric: true, value: "1", unit: "Ohm-1"},
+	"Wb":  {metric: true, value: "1", unit: "V.s"},
+	"T":   {metric: true, value: "1", unit: "Wb/m2"},
+	"H":   {metric: true, value: "1", unit: "Wb/A"},
+	"lm":  {metric: true, value: "1", unit: "cd.sr"},
+	"lx":  {metric: true, value: "1", unit: "lm/m2"},
+	"Bq":  {metric: true, value: "1", unit: "s-1"},
+	"Gy":  {metric: true, value: "1", unit: "J/kg"},
+	"Sv":  {metric: true, value: "1", unit: "J/kg"},
+	"Cel": {value: "1", unit: "K", offset: "273.15"},
+
+	// Other units from ISO 1000 and ISO 2955.
+	"gon":  {value: "0.9", unit: "deg"},
+	"deg":  {value: "2", unit: "[pi].rad/360"},
+	"'":    {value: "1", unit: "deg/60"},
+	"''":   {value: "1", unit: "'/60"},
+	"l":    {metric: true, value: "1", unit: "dm3"},
+	"L":    {metric: true, value: "1", unit: "l"},
+	"ar":   {metric: true, value: "100", unit: "m2"},
+	"min":  {value: "60", unit: "s"},
+	"h":    {value: "60", unit: "min"},
+	"d":    {value: "24", unit: "h"},
+	"a_t":  {value: "365.24219", unit: "d"},
+	"a_j":  {value: "365.25", unit: "d"},
+	"a_g":  {value: "365.2425", unit: "d"},
+	"a":    {value: "1", unit: "a_j"},
+	"wk":   {value: "7", unit: "d"},
+	"mo_s": {value: "29.53059", unit: "d"},
+	"mo_j": {value: "1", unit: "a_j/12"},
+	"mo_g": {value: "1", unit: "a_g/12"},
+	"mo":   {value: "1", unit: "mo_j"},
+	"t":    {metric: true, value: "1000", unit: "kg"},
+	"bar":  {metric: true, value: "100000", unit: "Pa"},
+	"u":    {metric: true, value: "1.6605402", unit: "10*-24.g"},
+	"eV":   {metric: true, value: "1.60217733", unit: "10*-19.J"},
+	"pc":   {metric: true, value: "30856780000000000", unit: "m"},
+
+	// Other metric units.
+	"atm":    {value: "101325", unit: "Pa"},
+	"[g]":    {value: "9.80665", unit: "m/s2"},
+	"cal":    {metric: true, value: "4.184", unit: "J"},
+	"[Cal]":  {value: "1", unit: "kcal"},
+	"Ci":     {metric: true, value: "37000000000", unit: "Bq"},
+	"mho":    {metric: true, value: "1", unit: "S"},
+	"m[Hg]":  {metric: true, value: "133.322", unit: "kPa"},
+	"m[H2O]": {metric: true, value: "9.80665", unit: "kPa"},
+	"eq":     {metric: true, value: "1", unit: "mol"},
+	"osm":    {metric: true, value: "1", unit: "mol"},
+	"kat":    {metric: true, value: "1", unit: "mol/s"},
+	"U":      {metric: true, value: "1", unit: "umol/min"},
+	"g%":     {metric: true, value: "1", unit: "g/dl"},
+	"bit":    {metric: true, value: "1", unit: "1"},
+	"By":     {metric: true, value: "8", unit: "bit"},
+
+	// International customary units.
+	"[in_i]":    {value: "2.54", unit: "cm"},
+	"[ft_i]":    {value: "12", unit: "[in_i]"},
+	"[yd_i]":    {value: "3", unit: "[ft_i]"},
+	"[mi_i]":    {value: "5280", unit: "[ft_i]"},
+	"[nmi_i]":   {value: "1852", unit: "m"},
+	"[kn_i]":    {value: "1", unit: "[nmi_i]/h"},
+	"[sin_i]":   {value: "1", unit: "[in_i]2"},
+	"[sft_i]":   {value: "1", unit: "[ft_i]2"},
+	"[cin_i]":   {value: "1", unit: "[in_i]3"},
+	"[cft_i]":   {value: "1", unit: "[ft_i]3"},
+	"[gr]":      {value: "64.79891", unit: "mg"},
+	"[lb_av]":   {value: "7000", unit: "[gr]"},
+	"[oz_av]":   {value: "1", unit: "[lb_av]/16"},
+	"[ston_av]": {value: "2000", unit: "[lb_av]"},
+	"[lbf_av]":  {value: "1", unit: "[lb_av].[g]"},
+	"[psi]":     {value: "1", unit: "[lbf_av]/[in_i]2"},
+	"[degF]":    {value: "5", unit: "K/9", offset: "459.67"},
+
+	// US volumes.
+	"[gal_us]": {value: "231", unit: "[in_i]3"},
+	"[qt_us]":  {value: "1", unit: "[gal_us]/4"},
+	"[pt_us]":  {value: "1", unit: "[qt_us]/2"},
+	"[cup_us]": {value: "1", unit: "[pt_us]/2"},
+	"[foz_us]": {value: "1", unit: "[pt_us]/16"},
+	"[tbs_us]": {value: "1", unit: "[foz_us]/2"},
+	"[tsp_us]": {value: "1", unit: "[tbs_us]/3"},
+	"[drp]":    {value: "1", unit: "ml/20"},
+
+	// Arbitrary units.
+	"[IU]":    {metric: true, base: true},
+	"[iU]":    {metric: true, value: "1", unit: "[IU]"},
+	"[arb'U]": {base: true},
+	"[USP'U]": {base: true},
+	"[CFU]":   {base: true},
+	"[pH]":    {base: true},
+}
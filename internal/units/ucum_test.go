@@ -0,0 +1,180 @@
+package units_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/fhir-fli/fhirpath-go/internal/units"
+	"github.com/shopspring/decimal"
+)
+
+func TestUCUM_Convert(t *testing.T) {
+	testCases := []struct {
+		name  string
+		value string
+		from  string
+		to    string
+		want  string
+	}{
+		{"prefixed mass", "1", "kg", "g", "1000"},
+		{"concentration", "5", "mg/dL", "mg/L", "50"},
+		{"derived unit", "1", "N", "kg.m/s2", "1"},
+		{"exponent", "1", "m2", "cm2", "10000"},
+		{"negative exponent", "1", "s-1", "Hz", "1"},
+		{"leading division", "2", "/min", "/h", "120"},
+		{"parenthesized term", "1", "kg/(m.s2)", "Pa", "1"},
+		{"annotations", "3", "{cells}/uL", "/mL", "3000"},
+		{"annotated unit", "1", "g{total}", "mg", "1000"},
+		{"integer factor", "1", "10*3/uL", "/nL", "1"},
+		{"customary units", "1", "[ft_i]", "[in_i]", "12"},
+		{"customary and metric units", "1", "[lb_av]", "g", "453.59237"},
+		{"pressure", "1", "mm[Hg]", "Pa", "133.322"},
+		{"time", "1", "wk", "h", "168"},
+		{"angles", "180", "deg", "[pi].rad", "1"},
+		{"celsius to kelvin", "37", "Cel", "K", "310.15"},
+		{"fahrenheit to celsius", "212", "[degF]", "Cel", "100"},
+		{"arbitrary units", "1", "k[IU]/L", "[IU]/mL", "1"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			from := mustParseUCUM(t, tc.from)
+			to := mustParseUCUM(t, tc.to)
+
+			got, err := from.Convert(decimal.RequireFromString(tc.value), to)
+
+			if err != nil {
+				t.Fatalf("Convert(%v %v, %v) returned unexpected error: %v", tc.value, tc.from, tc.to, err)
+			}
+			if want := decimal.RequireFromString(tc.want); !got.Equal(want) {
+				t.Errorf("Convert(%v %v, %v) = %v, want %v", tc.value, tc.from, tc.to, got, want)
+			}
+		})
+	}
+}
+
+func TestUCUM_Convert_Incommensurable(t *testing.T) {
+	testCases := []struct {
+		from string
+		to   string
+	}{
+		{"kg", "m"},
+		{"mg/dL", "mmol/L"},
+		{"[IU]", "[arb'U]"},
+		{"mo", "s2"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.from+" to "+tc.to, func(t *testing.T) {
+			from := mustParseUCUM(t, tc.from)
+			to := mustParseUCUM(t, tc.to)
+
+			if from.Commensurable(to) {
+				t.Errorf("%v.Commensurable(%v) = true, want false", tc.from, tc.to)
+			}
+			if _, err := from.Convert(decimal.New(1, 0), to); !errors.Is(err, units.ErrIncommensurable) {
+				t.Errorf("Convert(1 %v, %v) returned error %v, want %v", tc.from, tc.to, err, units.ErrIncommensurable)
+			}
+		})
+	}
+}
+
+func TestUCUM_MulAndDiv(t *testing.T) {
+	testCases := []struct {
+		name      string
+		lhs       string
+		rhs       string
+		div       bool
+		wantCode  string
+		wantUnity bool
+	}{
+		{name: "product of different units", lhs: "kg", rhs: "m", wantCode: "kg.m"},
+		{name: "product of the same unit", lhs: "m", rhs: "m", wantCode: "m2"},
+		{name: "quotient of derived units", lhs: "kg", rhs: "m2", div: true, wantCode: "kg/m2"},
+		{name: "quotient of a product", lhs: "kg", rhs: "m.m", div: true, wantCode: "kg/m2"},
+		{name: "cancelled units", lhs: "mg/kg", rhs: "kg", wantCode: "mg"},
+		{name: "quotient of the same unit", lhs: "mg", rhs: "mg", div: true, wantCode: "1", wantUnity: true},
+		{name: "commensurable units are kept", lhs: "mg", rhs: "kg", div: true, wantCode: "mg/kg"},
+		{name: "inverse", lhs: "1", rhs: "h", div: true, wantCode: "/h"},
+		{name: "annotations", lhs: "{cells}/uL", rhs: "uL", wantCode: "{cells}"},
+		{name: "integer factors", lhs: "10*3/uL", rhs: "10*3", div: true, wantCode: "/uL"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			lhs := mustParseUCUM(t, tc.lhs)
+			rhs := mustParseUCUM(t, tc.rhs)
+
+			got, err := lhs.Mul(rhs)
+			if tc.div {
+				got, err = lhs.Div(rhs)
+			}
+
+			if err != nil {
+				t.Fatalf("composing %v and %v returned unexpected error: %v", tc.lhs, tc.rhs, err)
+			}
+			if got.Code() != tc.wantCode {
+				t.Errorf("composing %v and %v returned %v, want %v", tc.lhs, tc.rhs, got.Code(), tc.wantCode)
+			}
+			if got.IsUnity() != tc.wantUnity {
+				t.Errorf("composing %v and %v returned IsUnity() = %v, want %v", tc.lhs, tc.rhs, got.IsUnity(), tc.wantUnity)
+			}
+			if want := mustParseUCUM(t, tc.wantCode); !got.Commensurable(want) {
+				t.Errorf("composing %v and %v returned a unit incommensurable with %v", tc.lhs, tc.rhs, tc.wantCode)
+			}
+		})
+	}
+}
+
+func TestUCUM_Mul_SpecialUnitReturnsError(t *testing.T) {
+	celsius := mustParseUCUM(t, "Cel")
+
+	if _, err := celsius.Mul(mustParseUCUM(t, "m")); !errors.Is(err, units.ErrInvalidUCUM) {
+		t.Errorf("Mul(Cel, m) returned error %v, want %v", err, units.ErrInvalidUCUM)
+	}
+}
+
+func TestUCUM_Mul_ExponentOverflowReturnsError(t *testing.T) {
+	unit := mustParseUCUM(t, "m99")
+
+	if _, err := unit.Mul(mustParseUCUM(t, "m")); !errors.Is(err, units.ErrInvalidUCUM) {
+		t.Errorf("Mul(m99, m) returned error %v, want %v", err, units.ErrInvalidUCUM)
+	}
+}
+
+func TestParseUCUM_ReturnsError(t *testing.T) {
+	testCases := []string{
+		"",
+		"Kg",
+		"lbs",
+		"km per hour",
+		"mg/",
+		"(mg",
+		"[in_i",
+		"g{total",
+		"kCel",
+		"Cel/h",
+		"2m",
+		"m100",
+		"m99999999",
+		"km2000000000",
+		"s-100",
+	}
+
+	for _, code := range testCases {
+		t.Run(code, func(t *testing.T) {
+			if _, err := units.ParseUCUM(code); !errors.Is(err, units.ErrInvalidUCUM) {
+				t.Errorf("ParseUCUM(%v) returned error %v, want %v", code, err, units.ErrInvalidUCUM)
+			}
+		})
+	}
+}
+
+func mustParseUCUM(t *testing.T, code string) units.UCUM {
+	t.Helper()
+	unit, err := units.ParseUCUM(code)
+	if err != nil {
+		t.Fatalf("ParseUCUM(%v) returned unexpected error: %v", code, err)
+	}
+	return unit
+}